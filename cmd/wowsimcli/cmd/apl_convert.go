@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/sim/core"
+	"github.com/wowsims/classic/sim/core/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var aplCmd = &cobra.Command{
+	Use:   "apl",
+	Short: "convert APL rotations between json and text formats",
+	Long:  "convert APL rotations between json (.apl.json) and text formats. The input format is detected automatically.",
+	Run:   aplMain,
+}
+
+func init() {
+	aplCmd.Flags().StringVar(&infile, "infile", "", "location of input file (APLRotation in protojson or text format)")
+	aplCmd.Flags().StringVar(&outfile, "outfile", "", "location of output file, defaults to stdout")
+	aplCmd.MarkFlagRequired("infile")
+}
+
+func aplMain(cmd *cobra.Command, args []string) {
+	data, err := os.ReadFile(infile)
+	if err != nil {
+		log.Fatalf("failed to load input file %q: %v", infile, err)
+	}
+
+	var output string
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		apl := &proto.APLRotation{}
+		if err := protojson.Unmarshal(data, apl); err != nil {
+			log.Fatalf("failed to parse APL json: %s", err)
+		}
+		output = core.FormatAPLRotationText(apl)
+	} else {
+		apl, err := core.ParseAPLRotationText(string(data))
+		if err != nil {
+			log.Fatalf("failed to parse APL text: %s", err)
+		}
+		output = protojson.Format(apl) + "\n"
+	}
+
+	if outfile == "" {
+		fmt.Print(output)
+	} else if err := os.WriteFile(outfile, []byte(output), 0666); err != nil {
+		log.Fatalf("failed to write output file: %s", err)
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/sim/core/combatlog"
+	"google.golang.org/protobuf/encoding/protojson"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+var (
+	combatLogPlayer    string
+	combatLogEncounter string
+	combatLogRotation  bool
+)
+
+var combatLogCmd = &cobra.Command{
+	Use:   "combatlog",
+	Short: "extract a player's metrics from a combat log",
+	Long:  "parse a WoWCombatLog.txt file and extract a player's casts, damage by ability, aura uptimes and fight duration, in the same format as the sim's unit metrics, or infer a starting APL rotation from the player's casts",
+	Run:   combatLogMain,
+}
+
+func init() {
+	combatLogCmd.Flags().StringVar(&infile, "infile", "WoWCombatLog.txt", "location of the combat log file")
+	combatLogCmd.Flags().StringVar(&combatLogPlayer, "player", "", "name of the player, with or without the realm")
+	combatLogCmd.Flags().StringVar(&combatLogEncounter, "encounter", "", "name or 1-based index of the encounter to extract, defaults to the whole log")
+	combatLogCmd.Flags().BoolVar(&combatLogRotation, "rotation", false, "output an APLRotation inferred from the player's casts instead of metrics")
+	combatLogCmd.Flags().StringVar(&outfile, "outfile", "", "location of output file, defaults to stdout")
+	combatLogCmd.MarkFlagRequired("player")
+}
+
+func combatLogMain(cmd *cobra.Command, args []string) {
+	file, err := os.Open(infile)
+	if err != nil {
+		log.Fatalf("failed to open combat log %q: %v", infile, err)
+	}
+	defer file.Close()
+
+	combatLog, err := combatlog.Parse(file)
+	if err != nil {
+		log.Fatalf("failed to parse combat log: %s", err)
+	}
+
+	var encounter *combatlog.Encounter
+	if combatLogEncounter != "" {
+		if encounter, err = combatLog.FindEncounter(combatLogEncounter); err != nil {
+			log.Fatalf("%s", err)
+		}
+	}
+
+	var result googleProto.Message
+	if combatLogRotation {
+		if result, err = combatLog.InferRotation(combatLogPlayer, encounter); err != nil {
+			log.Fatalf("failed to infer rotation: %s", err)
+		}
+	} else if result, err = combatLog.PlayerMetrics(combatLogPlayer, encounter); err != nil {
+		log.Fatalf("failed to extract player metrics: %s", err)
+	}
+
+	output, err := protojson.MarshalOptions{EmitUnpopulated: !combatLogRotation}.Marshal(result)
+	if err != nil {
+		log.Fatalf("failed to marshal result: %s", err)
+	}
+
+	if outfile == "" {
+		fmt.Print(string(output))
+	} else if err := os.WriteFile(outfile, output, 0666); err != nil {
+		log.Fatalf("failed to write output file: %s", err)
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/sim/core"
+	"github.com/wowsims/classic/sim/core/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var (
+	importSpec     string
+	importTemplate string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "import a character from the addon export",
+	Long:  "import a character from the WoWSims exporter addon's JSON into a RaidSimRequest, which can be run with the sim command",
+	Run:   importMain,
+}
+
+func init() {
+	importCmd.Flags().StringVar(&infile, "infile", "export.json", "location of the addon export JSON")
+	importCmd.Flags().StringVar(&importSpec, "spec", "", "spec to sim the character as, e.g. SpecWarrior, defaults to the class's main spec")
+	importCmd.Flags().StringVar(&importTemplate, "template", "", "location of a RaidSimRequest to import the character into, keeping its buffs, consumes, rotation and encounter")
+	importCmd.Flags().StringVar(&outfile, "outfile", "", "location of output file, defaults to stdout")
+	importCmd.MarkFlagRequired("infile")
+}
+
+func importMain(cmd *cobra.Command, args []string) {
+	data, err := os.ReadFile(infile)
+	if err != nil {
+		log.Fatalf("failed to load addon export %q: %v", infile, err)
+	}
+	request := &proto.CharacterImportRequest{AddonExport: string(data)}
+
+	if importSpec != "" {
+		spec, ok := proto.Spec_value[importSpec]
+		if !ok {
+			log.Fatalf("unknown spec %q", importSpec)
+		}
+		request.Spec = proto.Spec(spec)
+	}
+
+	if importTemplate != "" {
+		data, err := os.ReadFile(importTemplate)
+		if err != nil {
+			log.Fatalf("failed to load template %q: %v", importTemplate, err)
+		}
+		request.Template = &proto.RaidSimRequest{}
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, request.Template); err != nil {
+			log.Fatalf("failed to load template: %s", err)
+		}
+	}
+
+	result := core.RunCharacterImport(request)
+	if result.Error != nil {
+		log.Fatalf("import failed: %s", result.Error.Message)
+	}
+	if len(result.UnknownItems) > 0 {
+		log.Printf("items not found in the sim database: %v", result.UnknownItems)
+	}
+	if len(result.UnknownEnchants) > 0 {
+		log.Printf("enchants not found in the sim database: %v", result.UnknownEnchants)
+	}
+	if len(result.UnknownRandomSuffixes) > 0 {
+		log.Printf("random suffixes not found in the sim database: %v", result.UnknownRandomSuffixes)
+	}
+	if len(result.UnknownProfessions) > 0 {
+		log.Printf("professions not known to the sim: %v", result.UnknownProfessions)
+	}
+	if result.MissingRotation {
+		log.Printf("the spec has no default rotation, so the request sims no damage until one is added; use --template to import into a request with one")
+	}
+
+	output, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(result.Request)
+	if err != nil {
+		log.Fatalf("failed to marshal request: %s", err)
+	}
+
+	if outfile == "" {
+		fmt.Print(string(output))
+	} else if err := os.WriteFile(outfile, output, 0666); err != nil {
+		log.Fatalf("failed to write output file: %s", err)
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/sim/core"
+	"github.com/wowsims/classic/sim/core/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var (
+	replaySeed       int64
+	replayResultfile string
+	replayLogsOnly   bool
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "re-run a single iteration of a sim by its seed",
+	Long:  "re-run a single iteration of a sim by its seed, e.g. the max_seed or min_seed of a previous result, with debug logs enabled",
+	Run:   replayMain,
+}
+
+func init() {
+	replayCmd.Flags().StringVar(&infile, "infile", "input.json", "location of input file (RaidSimRequest in protojson format)")
+	replayCmd.Flags().Int64Var(&replaySeed, "seed", 0, "seed of the iteration to replay")
+	replayCmd.Flags().StringVar(&replayResultfile, "resultfile", "", "location of the original RaidSimResult, used to verify that the replay matches the recorded min/max values")
+	replayCmd.Flags().StringVar(&outfile, "outfile", "", "location of output file, defaults to stdout")
+	replayCmd.Flags().BoolVar(&replayLogsOnly, "logs", false, "only output the combat log instead of the full result")
+	replayCmd.MarkFlagRequired("infile")
+	replayCmd.MarkFlagRequired("seed")
+}
+
+func replayMain(cmd *cobra.Command, args []string) {
+	data, err := os.ReadFile(infile)
+	if err != nil {
+		log.Fatalf("failed to load input json file %q: %v", infile, err)
+	}
+	input := &proto.RaidSimRequest{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, input); err != nil {
+		log.Fatalf("failed to load input json file: %s", err)
+	}
+
+	result := core.ReplayRaidSimIteration(input, replaySeed)
+	if result.Error != nil {
+		log.Fatalf("replay failed: %s", result.Error.Message)
+	}
+
+	if replayResultfile != "" {
+		data, err := os.ReadFile(replayResultfile)
+		if err != nil {
+			log.Fatalf("failed to load result file %q: %v", replayResultfile, err)
+		}
+		original := &proto.RaidSimResult{}
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, original); err != nil {
+			log.Fatalf("failed to load result file: %s", err)
+		}
+
+		numChecked, err := core.VerifyReplay(original, result, replaySeed)
+		if err != nil {
+			log.Fatalf("replay doesn't match the original sim: %s", err)
+		}
+		log.Printf("replay matches %d recorded value(s) for seed %d", numChecked, replaySeed)
+	}
+
+	var output []byte
+	if replayLogsOnly {
+		output = []byte(result.Logs)
+	} else if output, err = (protojson.MarshalOptions{EmitUnpopulated: true}).Marshal(result); err != nil {
+		log.Fatalf("failed to marshal replay results: %s", err)
+	}
+
+	if outfile == "" {
+		fmt.Print(string(output))
+	} else if err := os.WriteFile(outfile, output, 0666); err != nil {
+		log.Fatalf("failed to write output file: %s", err)
+	}
+}
@@ -44,6 +44,13 @@ type Agent interface {
 	ExecuteCustomRotation(sim *Simulation)
 }
 
+// Optionally implemented by Agents which buff their own party during the sim. When
+// the raid derives buffs from its members, this clears the raid buff toggles which
+// the Agent replaces for its party.
+type PartyBuffProvider interface {
+	ClearProvidedBuffs(raidBuffs *proto.RaidBuffs)
+}
+
 type ActionID struct {
 	// Only one of these should be set.
 	SpellID int32
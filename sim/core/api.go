@@ -131,6 +131,62 @@ func RunBulkSimAsync(request *proto.BulkSimRequest, progress chan *proto.Progres
 	}()
 }
 
+/**
+ * Searches party assignments for a roster of players to maximize raid DPS.
+ */
+func RunRaidComposition(request *proto.RaidCompositionRequest) *proto.RaidCompositionResult {
+	return OptimizeRaidComposition(request, simsignals.CreateSignals())
+}
+
+/**
+ * Plans paladin blessings and druid innervates for each player in a raid.
+ */
+func RunBlessingsPlan(request *proto.BlessingsPlanRequest) *proto.BlessingsPlanResult {
+	return PlanBlessings(request, simsignals.CreateSignals())
+}
+
+/**
+ * Imports a character from the WoWSims exporter addon into a RaidSimRequest.
+ */
+func RunCharacterImport(request *proto.CharacterImportRequest) *proto.CharacterImportResult {
+	return ImportAddonCharacter(request)
+}
+
+/**
+ * Plans when a tank uses its defensive cooldowns to minimize its chance of death.
+ */
+func RunDefensivePlan(request *proto.DefensivePlanRequest) *proto.DefensivePlanResult {
+	return PlanDefensives(request, simsignals.CreateSignals())
+}
+
+/**
+ * Plans when a caster uses its mana cooldowns and downranks to maximize its DPS.
+ */
+func RunManaPlan(request *proto.ManaPlanRequest) *proto.ManaPlanResult {
+	return PlanMana(request, simsignals.CreateSignals())
+}
+
+/**
+ * Values the player's consumables by DPS gained per gold spent in a raid hour.
+ */
+func RunConsumableValue(request *proto.ConsumableValueRequest) *proto.ConsumableValueResult {
+	return ValueConsumables(request, simsignals.CreateSignals())
+}
+
+/**
+ * Lists the world buffs lost by each encounter of a raid.
+ */
+func RunWorldBuffTimeline(request *proto.WorldBuffTimelineRequest) *proto.WorldBuffTimelineResult {
+	return WorldBuffTimeline(request)
+}
+
+/**
+ * Sims a raid night of encounters, carrying over each player's state between them.
+ */
+func RunRaidNight(request *proto.RaidNightRequest) *proto.RaidNightResult {
+	return SimRaidNight(request, simsignals.CreateSignals())
+}
+
 var runningInWasm = false
 
 func SetRunningInWasm() {
@@ -29,6 +29,9 @@ type APLRotation struct {
 	// Used to avoid recursive APL loops.
 	inLoop bool
 
+	// Delayed rotation update scheduled by DoNextActionAfterReaction.
+	pendingReaction *PendingAction
+
 	// Validation warnings that occur during proto parsing.
 	// We return these back to the user for display in the UI.
 	curWarnings          []string
@@ -165,6 +168,7 @@ func (rot *APLRotation) allPrepullActions() []*APLAction {
 func (rot *APLRotation) reset(sim *Simulation) {
 	rot.controllingActions = nil
 	rot.inLoop = false
+	rot.pendingReaction = nil
 	rot.interruptChannelIf = nil
 	rot.allowChannelRecastOnInterrupt = false
 	for _, action := range rot.allAPLActions() {
@@ -214,6 +218,34 @@ func (apl *APLRotation) DoNextAction(sim *Simulation) {
 	}
 }
 
+// Used instead of DoNextAction when the rotation is woken up by an in-game event, such as
+// a resource gain or an auto attack, rather than by the GCD or a cast completing. When the
+// unit has a skill model, the rotation only responds after the unit's reaction time.
+func (apl *APLRotation) DoNextActionAfterReaction(sim *Simulation) {
+	if !apl.unit.Skill.ReactToEvents || sim.CurrentTime < 0 {
+		apl.DoNextAction(sim)
+		return
+	}
+
+	if pa := apl.pendingReaction; pa != nil && !pa.consumed && !pa.cancelled {
+		// Already reacting to an earlier event, which will also see this one.
+		return
+	}
+
+	reactionTime := apl.unit.RandomReactionTime(sim)
+	if reactionTime <= 0 {
+		apl.DoNextAction(sim)
+		return
+	}
+
+	apl.pendingReaction = StartDelayedAction(sim, DelayedActionOptions{
+		DoAt: sim.CurrentTime + reactionTime,
+		OnAction: func(sim *Simulation) {
+			apl.DoNextAction(sim)
+		},
+	})
+}
+
 func (apl *APLRotation) getNextAction(sim *Simulation) *APLAction {
 	if len(apl.controllingActions) != 0 {
 		return apl.controllingActions[len(apl.controllingActions)-1].GetNextAction(sim)
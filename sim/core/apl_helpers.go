@@ -154,6 +154,14 @@ func (rot *APLRotation) GetAPLSpell(spellId *proto.ActionID) *Spell {
 		}
 	} else {
 		spell = rot.unit.GetSpell(actionID)
+
+		// The rank picks another rank of the same spell, so rotations can downrank.
+		if rank := int(spellId.Rank); spell != nil && rank != 0 && spell.Rank != 0 && rank != spell.Rank {
+			if spell = rot.unit.GetSpellRank(spell, rank); spell == nil {
+				rot.ValidationWarning("%s does not know rank %d of spell %s", rot.unit.Label, rank, actionID)
+				return nil
+			}
+		}
 	}
 
 	if spell == nil {
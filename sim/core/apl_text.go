@@ -0,0 +1,854 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Compact text format for APL rotations, loosely based on SimulationCraft action lists:
+//
+//	# Lines starting with '#' are comments.
+//	type=TypeAPL
+//	prepull+=/cast_spell,spell_id=spell:14322.r6,do_at=-10s
+//	actions+=/autocast_other_cooldowns
+//	actions+=/cast_spell,spell_id=spell:3045,if=auto_time_to_next(auto_type=Ranged) < 0.1 & spell_is_ready(spell:20904.r6)
+//
+// Actions and values are written using their field names from apl.proto, followed by their
+// arguments as name=value pairs. Messages with a single field also accept positional arguments.
+// Conditions support the operators !, &, |, ==, !=, <, <=, >, >=, +, -, * and /, and constants
+// are written as plain numbers/durations or as quoted strings.
+//
+// ActionIDs are written as spell:<id>, item:<id> or other:<OtherAction>, optionally followed by
+// .r<rank> and .t<tag>. Unit references are written as <Type>[:<index>][@<owner>], e.g. Target:1.
+
+const (
+	aplTextPrepullList  = "prepull"
+	aplTextPriorityList = "actions"
+	aplTextCondition    = "if"
+	aplTextDoAt         = "do_at"
+	aplTextNone         = "none"
+)
+
+var aplTextConstRegex = regexp.MustCompile(`^-?[0-9]*\.?[0-9]+(ms|s|m|h)?$`)
+var aplTextWordRegex = regexp.MustCompile(`^[A-Za-z0-9_.:@]+$`)
+
+// Parses a rotation written in the APL text format.
+func ParseAPLRotationText(text string) (apl *proto.APLRotation, err error) {
+	apl = &proto.APLRotation{}
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := parseAPLTextLine(apl, line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+	return apl, nil
+}
+
+// Same as ParseAPLRotationText, but panics on invalid input. Mirrors APLRotationFromJsonString.
+func APLRotationFromTextString(text string) *proto.APLRotation {
+	apl, err := ParseAPLRotationText(text)
+	if err != nil {
+		panic(err)
+	}
+	return apl
+}
+
+// Formats a rotation in the APL text format. The output can be parsed back with ParseAPLRotationText.
+func FormatAPLRotationText(apl *proto.APLRotation) string {
+	var sb strings.Builder
+	msg := apl.ProtoReflect()
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.IsList() || !msg.Has(fd) {
+			continue
+		}
+		sb.WriteString(string(fd.Name()) + "=" + formatAPLTextField(fd, msg.Get(fd)) + "\n")
+	}
+	for _, prepullItem := range apl.PrepullActions {
+		var extras []string
+		if prepullItem.DoAtValue != nil {
+			extras = append(extras, aplTextDoAt+"="+formatAPLTextValue(prepullItem.DoAtValue, 0))
+		}
+		if prepullItem.Hide {
+			extras = append(extras, "hide=true")
+		}
+		sb.WriteString(aplTextPrepullList + "+=/" + formatAPLTextAction(prepullItem.Action, extras, false) + "\n")
+	}
+	for _, listItem := range apl.PriorityList {
+		var extras []string
+		if listItem.Hide {
+			extras = append(extras, "hide=true")
+		}
+		if listItem.Notes != "" {
+			extras = append(extras, "notes="+formatAPLTextString(listItem.Notes))
+		}
+		sb.WriteString(aplTextPriorityList + "+=/" + formatAPLTextAction(listItem.Action, extras, false) + "\n")
+	}
+	return sb.String()
+}
+
+///////////////////////////////////////////////////////////////////////////
+//                                 PARSING
+///////////////////////////////////////////////////////////////////////////
+
+type aplTextError struct {
+	err error
+}
+
+type aplTextParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *aplTextParser) fail(format string, args ...interface{}) {
+	panic(aplTextError{fmt.Errorf(format, args...)})
+}
+
+func (p *aplTextParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *aplTextParser) peekAt(offset int) string {
+	if p.pos+offset >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *aplTextParser) next() string {
+	tok := p.peek()
+	if tok == "" {
+		p.fail("unexpected end of input")
+	}
+	p.pos++
+	return tok
+}
+
+func (p *aplTextParser) accept(tok string) bool {
+	if p.peek() == tok {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *aplTextParser) expect(tok string) {
+	if got := p.next(); got != tok {
+		p.fail("expected '%s' but found '%s'", tok, got)
+	}
+}
+
+func (p *aplTextParser) expectWord() string {
+	tok := p.next()
+	if !aplTextWordRegex.MatchString(tok) {
+		p.fail("expected a name or number but found '%s'", tok)
+	}
+	return tok
+}
+
+func tokenizeAPLText(text string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(text) && text[j] != '"'; j++ {
+				if text[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(text) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, text[i:j+1])
+			i = j + 1
+		case strings.HasPrefix(text[i:], "=="), strings.HasPrefix(text[i:], "!="),
+			strings.HasPrefix(text[i:], "<="), strings.HasPrefix(text[i:], ">="):
+			tokens = append(tokens, text[i:i+2])
+			i += 2
+		case strings.ContainsRune("()[]{},=<>!&|+-*/", rune(c)):
+			tokens = append(tokens, text[i:i+1])
+			i++
+		default:
+			j := i
+			for j < len(text) && aplTextWordRegex.MatchString(text[j:j+1]) {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("unexpected character '%c'", c)
+			}
+			tokens = append(tokens, text[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+func parseAPLTextLine(apl *proto.APLRotation, line string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			textErr, ok := r.(aplTextError)
+			if !ok {
+				panic(r)
+			}
+			err = textErr.err
+		}
+	}()
+
+	listName, body, isListItem := strings.Cut(line, "+=/")
+	if !isListItem {
+		listName, body, _ = strings.Cut(line, "=")
+	}
+	listName = strings.TrimSpace(listName)
+
+	tokens, err := tokenizeAPLText(body)
+	if err != nil {
+		return err
+	}
+	p := &aplTextParser{tokens: tokens}
+
+	if !isListItem {
+		msg := apl.ProtoReflect()
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(listName))
+		if fd == nil || fd.IsList() {
+			return fmt.Errorf("unknown rotation field '%s'", listName)
+		}
+		msg.Set(fd, p.parseFieldValue(msg, fd))
+	} else if listName == aplTextPrepullList {
+		prepullItem := &proto.APLPrepullAction{}
+		prepullItem.Action = p.parseAction(false, func(key string) bool {
+			switch key {
+			case aplTextDoAt:
+				prepullItem.DoAtValue = p.parseExpr()
+			case "hide":
+				prepullItem.Hide = p.parseBool()
+			default:
+				return false
+			}
+			return true
+		})
+		apl.PrepullActions = append(apl.PrepullActions, prepullItem)
+	} else if listName == aplTextPriorityList {
+		listItem := &proto.APLListItem{}
+		listItem.Action = p.parseAction(false, func(key string) bool {
+			switch key {
+			case "hide":
+				listItem.Hide = p.parseBool()
+			case "notes":
+				listItem.Notes = p.parseString()
+			default:
+				return false
+			}
+			return true
+		})
+		apl.PriorityList = append(apl.PriorityList, listItem)
+	} else {
+		return fmt.Errorf("unknown action list '%s'", listName)
+	}
+
+	if p.peek() != "" {
+		return fmt.Errorf("unexpected '%s'", p.peek())
+	}
+	return nil
+}
+
+// Parses an action, either in list form (name,arg,arg) or in call form (name(arg, arg)).
+// extraArg is invoked for argument names that don't belong to the action itself.
+func (p *aplTextParser) parseAction(isCall bool, extraArg func(key string) bool) *proto.APLAction {
+	action := &proto.APLAction{}
+	name := p.expectWord()
+
+	var inner protoreflect.Message
+	if name != aplTextNone {
+		msg := action.ProtoReflect()
+		fd := msg.Descriptor().Oneofs().ByName("action").Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			p.fail("unknown action '%s'", name)
+		}
+		inner = msg.Mutable(fd).Message()
+	}
+
+	argFn := func(key string) bool {
+		if key == aplTextCondition {
+			action.Condition = p.parseExpr()
+			return true
+		}
+		return extraArg != nil && extraArg(key)
+	}
+
+	if isCall {
+		if p.accept("(") && !p.accept(")") {
+			p.parseArgs(inner, argFn)
+			p.expect(")")
+		}
+	} else if p.accept(",") {
+		p.parseArgs(inner, argFn)
+	}
+	return action
+}
+
+// Parses a comma-separated argument list into msg.
+func (p *aplTextParser) parseArgs(msg protoreflect.Message, extraArg func(key string) bool) {
+	for {
+		if p.peekAt(1) == "=" {
+			key := p.expectWord()
+			p.expect("=")
+			if extraArg == nil || !extraArg(key) {
+				if msg == nil {
+					p.fail("unknown argument '%s'", key)
+				}
+				fd := msg.Descriptor().Fields().ByName(protoreflect.Name(key))
+				if fd == nil {
+					p.fail("unknown argument '%s' for %s", key, msg.Descriptor().Name())
+				}
+				if fd.IsList() {
+					p.parseListValue(msg, fd)
+				} else {
+					msg.Set(fd, p.parseFieldValue(msg, fd))
+				}
+			}
+		} else {
+			if msg == nil || msg.Descriptor().Fields().Len() != 1 {
+				p.fail("positional arguments are only allowed for single-field messages")
+			}
+			fd := msg.Descriptor().Fields().Get(0)
+			if fd.IsList() {
+				msg.Mutable(fd).List().Append(p.parseFieldValue(msg, fd))
+			} else {
+				msg.Set(fd, p.parseFieldValue(msg, fd))
+			}
+		}
+
+		if !p.accept(",") {
+			return
+		}
+	}
+}
+
+func (p *aplTextParser) parseListValue(msg protoreflect.Message, fd protoreflect.FieldDescriptor) {
+	list := msg.Mutable(fd).List()
+	if !p.accept("[") {
+		list.Append(p.parseFieldValue(msg, fd))
+		return
+	}
+	if p.accept("]") {
+		return
+	}
+	for {
+		list.Append(p.parseFieldValue(msg, fd))
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect("]")
+}
+
+// Parses a single (non-list) value for the given field.
+func (p *aplTextParser) parseFieldValue(msg protoreflect.Message, fd protoreflect.FieldDescriptor) protoreflect.Value {
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		switch fd.Message().FullName() {
+		case "proto.APLValue":
+			return protoreflect.ValueOfMessage(p.parseExpr().ProtoReflect())
+		case "proto.APLAction":
+			return protoreflect.ValueOfMessage(p.parseAction(true, nil).ProtoReflect())
+		case "proto.ActionID":
+			return protoreflect.ValueOfMessage(p.parseActionID(p.expectWord()).ProtoReflect())
+		case "proto.UnitReference":
+			return protoreflect.ValueOfMessage(p.parseUnitReference(p.expectWord()).ProtoReflect())
+		default:
+			var inner protoreflect.Message
+			if fd.IsList() {
+				inner = msg.Mutable(fd).List().NewElement().Message()
+			} else {
+				inner = msg.NewField(fd).Message()
+			}
+			p.expect("{")
+			if !p.accept("}") {
+				p.parseArgs(inner, nil)
+				p.expect("}")
+			}
+			return protoreflect.ValueOfMessage(inner)
+		}
+	case protoreflect.EnumKind:
+		return protoreflect.ValueOfEnum(p.parseEnum(fd.Enum(), p.expectWord()))
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(p.parseString())
+	case protoreflect.BoolKind:
+		return protoreflect.ValueOfBool(p.parseBool())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return protoreflect.ValueOfInt32(int32(p.parseInt(32)))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return protoreflect.ValueOfInt64(p.parseInt(64))
+	case protoreflect.FloatKind:
+		return protoreflect.ValueOfFloat32(float32(p.parseFloat(32)))
+	case protoreflect.DoubleKind:
+		return protoreflect.ValueOfFloat64(p.parseFloat(64))
+	}
+	p.fail("unsupported field type for '%s'", fd.Name())
+	return protoreflect.Value{}
+}
+
+func (p *aplTextParser) parseString() string {
+	tok := p.next()
+	if strings.HasPrefix(tok, "\"") {
+		str, err := strconv.Unquote(tok)
+		if err != nil {
+			p.fail("invalid string %s", tok)
+		}
+		return str
+	}
+	if !aplTextWordRegex.MatchString(tok) {
+		p.fail("expected a string but found '%s'", tok)
+	}
+	return tok
+}
+
+func (p *aplTextParser) parseBool() bool {
+	tok := p.expectWord()
+	val, err := strconv.ParseBool(tok)
+	if err != nil {
+		p.fail("invalid boolean '%s'", tok)
+	}
+	return val
+}
+
+func (p *aplTextParser) parseInt(bitSize int) int64 {
+	tok := p.parseSignedWord()
+	val, err := strconv.ParseInt(tok, 10, bitSize)
+	if err != nil {
+		p.fail("invalid integer '%s'", tok)
+	}
+	return val
+}
+
+func (p *aplTextParser) parseFloat(bitSize int) float64 {
+	tok := p.parseSignedWord()
+	val, err := strconv.ParseFloat(tok, bitSize)
+	if err != nil {
+		p.fail("invalid number '%s'", tok)
+	}
+	return val
+}
+
+func (p *aplTextParser) parseSignedWord() string {
+	if p.accept("-") {
+		return "-" + p.expectWord()
+	}
+	return p.expectWord()
+}
+
+func (p *aplTextParser) parseEnum(ed protoreflect.EnumDescriptor, tok string) protoreflect.EnumNumber {
+	if ev := ed.Values().ByName(protoreflect.Name(tok)); ev != nil {
+		return ev.Number()
+	}
+	if num, err := strconv.Atoi(tok); err == nil {
+		return protoreflect.EnumNumber(num)
+	}
+	p.fail("unknown %s value '%s'", ed.Name(), tok)
+	return 0
+}
+
+func (p *aplTextParser) parseActionID(tok string) *proto.ActionID {
+	parts := strings.Split(tok, ".")
+	actionID := &proto.ActionID{}
+
+	kind, rawID, _ := strings.Cut(parts[0], ":")
+	switch kind {
+	case "spell":
+		actionID.RawId = &proto.ActionID_SpellId{SpellId: int32(p.atoi(rawID))}
+	case "item":
+		actionID.RawId = &proto.ActionID_ItemId{ItemId: int32(p.atoi(rawID))}
+	case "other":
+		ed := proto.OtherAction(0).Descriptor()
+		actionID.RawId = &proto.ActionID_OtherId{OtherId: proto.OtherAction(p.parseEnum(ed, rawID))}
+	case aplTextNone:
+	default:
+		p.fail("invalid action ID '%s'", tok)
+	}
+
+	for _, suffix := range parts[1:] {
+		switch {
+		case strings.HasPrefix(suffix, "r"):
+			actionID.Rank = int32(p.atoi(suffix[1:]))
+		case strings.HasPrefix(suffix, "t"):
+			actionID.Tag = int32(p.atoi(suffix[1:]))
+		default:
+			p.fail("invalid action ID '%s'", tok)
+		}
+	}
+	return actionID
+}
+
+func (p *aplTextParser) parseUnitReference(tok string) *proto.UnitReference {
+	unitRef := &proto.UnitReference{}
+	tok, owner, hasOwner := strings.Cut(tok, "@")
+	typeName, index, hasIndex := strings.Cut(tok, ":")
+
+	ed := proto.UnitReference_Unknown.Descriptor()
+	unitRef.Type = proto.UnitReference_Type(p.parseEnum(ed, typeName))
+	if hasIndex {
+		unitRef.Index = int32(p.atoi(index))
+	}
+	if hasOwner {
+		unitRef.Owner = p.parseUnitReference(owner)
+	}
+	return unitRef
+}
+
+func (p *aplTextParser) atoi(tok string) int {
+	val, err := strconv.Atoi(tok)
+	if err != nil {
+		p.fail("invalid integer '%s'", tok)
+	}
+	return val
+}
+
+// Expression grammar, from lowest to highest precedence:
+//
+//	or      := and ('|' and)*
+//	and     := cmp ('&' cmp)*
+//	cmp     := sum (('=='|'!='|'<'|'<='|'>'|'>=') sum)?
+//	sum     := product (('+'|'-') product)*
+//	product := unary (('*'|'/') unary)*
+//	unary   := '!' unary | primary
+//	primary := '(' or ')' | constant | name | name '(' args ')'
+func (p *aplTextParser) parseExpr() *proto.APLValue {
+	first := p.parseAnd()
+	if p.peek() != "|" {
+		return first
+	}
+	vals := []*proto.APLValue{first}
+	for p.accept("|") {
+		vals = append(vals, p.parseAnd())
+	}
+	return &proto.APLValue{Value: &proto.APLValue_Or{Or: &proto.APLValueOr{Vals: vals}}}
+}
+
+func (p *aplTextParser) parseAnd() *proto.APLValue {
+	first := p.parseCmp()
+	if p.peek() != "&" {
+		return first
+	}
+	vals := []*proto.APLValue{first}
+	for p.accept("&") {
+		vals = append(vals, p.parseCmp())
+	}
+	return &proto.APLValue{Value: &proto.APLValue_And{And: &proto.APLValueAnd{Vals: vals}}}
+}
+
+var aplTextCompareOps = map[string]proto.APLValueCompare_ComparisonOperator{
+	"==": proto.APLValueCompare_OpEq,
+	"!=": proto.APLValueCompare_OpNe,
+	"<":  proto.APLValueCompare_OpLt,
+	"<=": proto.APLValueCompare_OpLe,
+	">":  proto.APLValueCompare_OpGt,
+	">=": proto.APLValueCompare_OpGe,
+}
+
+var aplTextMathOps = map[string]proto.APLValueMath_MathOperator{
+	"+": proto.APLValueMath_OpAdd,
+	"-": proto.APLValueMath_OpSub,
+	"*": proto.APLValueMath_OpMul,
+	"/": proto.APLValueMath_OpDiv,
+}
+
+func (p *aplTextParser) parseCmp() *proto.APLValue {
+	lhs := p.parseSum()
+	op, ok := aplTextCompareOps[p.peek()]
+	if !ok {
+		return lhs
+	}
+	p.next()
+	return &proto.APLValue{Value: &proto.APLValue_Cmp{Cmp: &proto.APLValueCompare{Op: op, Lhs: lhs, Rhs: p.parseSum()}}}
+}
+
+func (p *aplTextParser) parseSum() *proto.APLValue {
+	lhs := p.parseProduct()
+	for p.peek() == "+" || p.peek() == "-" {
+		op := aplTextMathOps[p.next()]
+		lhs = &proto.APLValue{Value: &proto.APLValue_Math{Math: &proto.APLValueMath{Op: op, Lhs: lhs, Rhs: p.parseProduct()}}}
+	}
+	return lhs
+}
+
+func (p *aplTextParser) parseProduct() *proto.APLValue {
+	lhs := p.parseUnary()
+	for p.peek() == "*" || p.peek() == "/" {
+		op := aplTextMathOps[p.next()]
+		lhs = &proto.APLValue{Value: &proto.APLValue_Math{Math: &proto.APLValueMath{Op: op, Lhs: lhs, Rhs: p.parseUnary()}}}
+	}
+	return lhs
+}
+
+func (p *aplTextParser) parseUnary() *proto.APLValue {
+	if p.accept("!") {
+		return &proto.APLValue{Value: &proto.APLValue_Not{Not: &proto.APLValueNot{Val: p.parseUnary()}}}
+	}
+	return p.parsePrimary()
+}
+
+func (p *aplTextParser) parsePrimary() *proto.APLValue {
+	tok := p.peek()
+	switch {
+	case tok == "(":
+		p.next()
+		val := p.parseExpr()
+		p.expect(")")
+		return val
+	case strings.HasPrefix(tok, "\""):
+		return &proto.APLValue{Value: &proto.APLValue_Const{Const: &proto.APLValueConst{Val: p.parseString()}}}
+	case tok == "-" || aplTextConstRegex.MatchString(tok):
+		return &proto.APLValue{Value: &proto.APLValue_Const{Const: &proto.APLValueConst{Val: p.parseSignedWord()}}}
+	}
+
+	name := p.expectWord()
+	value := &proto.APLValue{}
+	if name == aplTextNone {
+		return value
+	}
+
+	msg := value.ProtoReflect()
+	fd := msg.Descriptor().Oneofs().ByName("value").Fields().ByName(protoreflect.Name(name))
+	if fd == nil {
+		p.fail("unknown value '%s'", name)
+	}
+	inner := msg.Mutable(fd).Message()
+	if p.accept("(") && !p.accept(")") {
+		p.parseArgs(inner, nil)
+		p.expect(")")
+	}
+	return value
+}
+
+///////////////////////////////////////////////////////////////////////////
+//                                FORMATTING
+///////////////////////////////////////////////////////////////////////////
+
+// Formats an action in list form (name,arg,arg) or call form (name(arg, arg)).
+func formatAPLTextAction(action *proto.APLAction, extras []string, isCall bool) string {
+	name := aplTextNone
+	var args []string
+	if action != nil {
+		msg := action.ProtoReflect()
+		if fd := msg.WhichOneof(msg.Descriptor().Oneofs().ByName("action")); fd != nil {
+			name = string(fd.Name())
+			args = formatAPLTextArgs(msg.Get(fd).Message())
+		}
+		if action.Condition != nil {
+			args = append(args, aplTextCondition+"="+formatAPLTextValue(action.Condition, 0))
+		}
+	}
+	args = append(args, extras...)
+
+	if len(args) == 0 {
+		return name
+	} else if isCall {
+		return name + "(" + strings.Join(args, ", ") + ")"
+	} else {
+		return name + "," + strings.Join(args, ",")
+	}
+}
+
+func formatAPLTextArgs(msg protoreflect.Message) []string {
+	var args []string
+	fields := msg.Descriptor().Fields()
+	positional := fields.Len() == 1
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !msg.Has(fd) {
+			continue
+		}
+		if fd.IsList() {
+			list := msg.Get(fd).List()
+			elems := make([]string, list.Len())
+			for j := range elems {
+				elems[j] = formatAPLTextField(fd, list.Get(j))
+			}
+			if positional {
+				args = append(args, elems...)
+			} else {
+				args = append(args, string(fd.Name())+"=["+strings.Join(elems, ", ")+"]")
+			}
+		} else if positional {
+			args = append(args, formatAPLTextField(fd, msg.Get(fd)))
+		} else {
+			args = append(args, string(fd.Name())+"="+formatAPLTextField(fd, msg.Get(fd)))
+		}
+	}
+	return args
+}
+
+// Formats a single (non-list) value of the given field.
+func formatAPLTextField(fd protoreflect.FieldDescriptor, val protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		switch m := val.Message().Interface().(type) {
+		case *proto.APLValue:
+			return formatAPLTextValue(m, 0)
+		case *proto.APLAction:
+			return formatAPLTextAction(m, nil, true)
+		case *proto.ActionID:
+			return formatAPLTextActionID(m)
+		case *proto.UnitReference:
+			return formatAPLTextUnitReference(m)
+		default:
+			return "{" + strings.Join(formatAPLTextArgs(val.Message()), ", ") + "}"
+		}
+	case protoreflect.EnumKind:
+		return formatAPLTextEnum(fd.Enum(), val.Enum())
+	case protoreflect.StringKind:
+		return formatAPLTextString(val.String())
+	case protoreflect.BoolKind:
+		return strconv.FormatBool(val.Bool())
+	case protoreflect.FloatKind:
+		return strconv.FormatFloat(val.Float(), 'g', -1, 32)
+	case protoreflect.DoubleKind:
+		return strconv.FormatFloat(val.Float(), 'g', -1, 64)
+	default:
+		return fmt.Sprint(val.Interface())
+	}
+}
+
+func formatAPLTextString(str string) string {
+	if aplTextWordRegex.MatchString(str) {
+		return str
+	}
+	return strconv.Quote(str)
+}
+
+func formatAPLTextEnum(ed protoreflect.EnumDescriptor, num protoreflect.EnumNumber) string {
+	if ev := ed.Values().ByNumber(num); ev != nil {
+		return string(ev.Name())
+	}
+	return strconv.Itoa(int(num))
+}
+
+func formatAPLTextActionID(actionID *proto.ActionID) string {
+	var str string
+	switch rawID := actionID.RawId.(type) {
+	case *proto.ActionID_SpellId:
+		str = "spell:" + strconv.Itoa(int(rawID.SpellId))
+	case *proto.ActionID_ItemId:
+		str = "item:" + strconv.Itoa(int(rawID.ItemId))
+	case *proto.ActionID_OtherId:
+		str = "other:" + formatAPLTextEnum(rawID.OtherId.Descriptor(), rawID.OtherId.Number())
+	default:
+		str = aplTextNone
+	}
+	if actionID.Rank != 0 {
+		str += ".r" + strconv.Itoa(int(actionID.Rank))
+	}
+	if actionID.Tag != 0 {
+		str += ".t" + strconv.Itoa(int(actionID.Tag))
+	}
+	return str
+}
+
+func formatAPLTextUnitReference(unitRef *proto.UnitReference) string {
+	str := formatAPLTextEnum(unitRef.Type.Descriptor(), unitRef.Type.Number())
+	if unitRef.Index != 0 {
+		str += ":" + strconv.Itoa(int(unitRef.Index))
+	}
+	if unitRef.Owner != nil {
+		str += "@" + formatAPLTextUnitReference(unitRef.Owner)
+	}
+	return str
+}
+
+// Operator precedence levels, matching the grammar in parseExpr.
+const (
+	aplTextPrecOr = iota + 1
+	aplTextPrecAnd
+	aplTextPrecCmp
+	aplTextPrecSum
+	aplTextPrecProduct
+	aplTextPrecUnary
+	aplTextPrecPrimary
+)
+
+// Formats a value, wrapping it in parentheses if it binds less tightly than minPrec.
+func formatAPLTextValue(value *proto.APLValue, minPrec int) string {
+	str, prec := formatAPLTextValueWithPrec(value)
+	if prec < minPrec {
+		return "(" + str + ")"
+	}
+	return str
+}
+
+func formatAPLTextValueWithPrec(value *proto.APLValue) (string, int) {
+	switch v := value.Value.(type) {
+	case nil:
+		return aplTextNone, aplTextPrecPrimary
+	case *proto.APLValue_Const:
+		if aplTextConstRegex.MatchString(v.Const.Val) {
+			return v.Const.Val, aplTextPrecPrimary
+		}
+		return strconv.Quote(v.Const.Val), aplTextPrecPrimary
+	case *proto.APLValue_Or:
+		if len(v.Or.Vals) >= 2 && !hasNilAPLValue(v.Or.Vals) {
+			return joinAPLTextValues(v.Or.Vals, " | ", aplTextPrecAnd), aplTextPrecOr
+		}
+	case *proto.APLValue_And:
+		if len(v.And.Vals) >= 2 && !hasNilAPLValue(v.And.Vals) {
+			return joinAPLTextValues(v.And.Vals, " & ", aplTextPrecCmp), aplTextPrecAnd
+		}
+	case *proto.APLValue_Not:
+		if v.Not.Val != nil {
+			return "!" + formatAPLTextValue(v.Not.Val, aplTextPrecUnary), aplTextPrecUnary
+		}
+	case *proto.APLValue_Cmp:
+		for sym, op := range aplTextCompareOps {
+			if v.Cmp.Op == op && v.Cmp.Lhs != nil && v.Cmp.Rhs != nil {
+				return formatAPLTextValue(v.Cmp.Lhs, aplTextPrecSum) + " " + sym + " " + formatAPLTextValue(v.Cmp.Rhs, aplTextPrecSum), aplTextPrecCmp
+			}
+		}
+	case *proto.APLValue_Math:
+		for sym, op := range aplTextMathOps {
+			if v.Math.Op == op && v.Math.Lhs != nil && v.Math.Rhs != nil {
+				prec := aplTextPrecSum
+				if op == proto.APLValueMath_OpMul || op == proto.APLValueMath_OpDiv {
+					prec = aplTextPrecProduct
+				}
+				return formatAPLTextValue(v.Math.Lhs, prec) + " " + sym + " " + formatAPLTextValue(v.Math.Rhs, prec+1), prec
+			}
+		}
+	}
+
+	// Generic call form, used for all other values and for operators that can't be written infix.
+	msg := value.ProtoReflect()
+	fd := msg.WhichOneof(msg.Descriptor().Oneofs().ByName("value"))
+	args := formatAPLTextArgs(msg.Get(fd).Message())
+	if len(args) == 0 {
+		return string(fd.Name()), aplTextPrecPrimary
+	}
+	return string(fd.Name()) + "(" + strings.Join(args, ", ") + ")", aplTextPrecPrimary
+}
+
+func joinAPLTextValues(vals []*proto.APLValue, sep string, minPrec int) string {
+	return strings.Join(MapSlice(vals, func(val *proto.APLValue) string { return formatAPLTextValue(val, minPrec) }), sep)
+}
+
+func hasNilAPLValue(vals []*proto.APLValue) bool {
+	for _, val := range vals {
+		if val == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+func TestAPLTextParse(t *testing.T) {
+	apl, err := ParseAPLRotationText(`
+		# Example rotation
+		type=TypeAPL
+		prepull+=/cast_spell,spell_id=spell:14322.r6,do_at=-10s
+		actions+=/autocast_other_cooldowns
+		actions+=/cast_spell,spell_id=spell:3045,if=auto_time_to_next(auto_type=Ranged) < 0.1 & !spell_is_ready(spell:20904.r6)
+		actions+=/wait,0.5s,notes="Wait for the next swing"
+	`)
+	if err != nil {
+		t.Fatalf("Failed to parse APL text: %s", err)
+	}
+
+	expected := &proto.APLRotation{
+		Type: proto.APLRotation_TypeAPL,
+		PrepullActions: []*proto.APLPrepullAction{
+			{
+				Action: &proto.APLAction{Action: &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{
+					SpellId: &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: 14322}, Rank: 6},
+				}}},
+				DoAtValue: &proto.APLValue{Value: &proto.APLValue_Const{Const: &proto.APLValueConst{Val: "-10s"}}},
+			},
+		},
+		PriorityList: []*proto.APLListItem{
+			{
+				Action: &proto.APLAction{Action: &proto.APLAction_AutocastOtherCooldowns{AutocastOtherCooldowns: &proto.APLActionAutocastOtherCooldowns{}}},
+			},
+			{
+				Action: &proto.APLAction{
+					Condition: &proto.APLValue{Value: &proto.APLValue_And{And: &proto.APLValueAnd{Vals: []*proto.APLValue{
+						{Value: &proto.APLValue_Cmp{Cmp: &proto.APLValueCompare{
+							Op:  proto.APLValueCompare_OpLt,
+							Lhs: &proto.APLValue{Value: &proto.APLValue_AutoTimeToNext{AutoTimeToNext: &proto.APLValueAutoTimeToNext{AutoType: proto.APLValueAutoTimeToNext_Ranged}}},
+							Rhs: &proto.APLValue{Value: &proto.APLValue_Const{Const: &proto.APLValueConst{Val: "0.1"}}},
+						}}},
+						{Value: &proto.APLValue_Not{Not: &proto.APLValueNot{
+							Val: &proto.APLValue{Value: &proto.APLValue_SpellIsReady{SpellIsReady: &proto.APLValueSpellIsReady{
+								SpellId: &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: 20904}, Rank: 6},
+							}}},
+						}}},
+					}}}},
+					Action: &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{
+						SpellId: &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: 3045}},
+					}},
+				},
+			},
+			{
+				Notes: "Wait for the next swing",
+				Action: &proto.APLAction{Action: &proto.APLAction_Wait{Wait: &proto.APLActionWait{
+					Duration: &proto.APLValue{Value: &proto.APLValue_Const{Const: &proto.APLValueConst{Val: "0.5s"}}},
+				}}},
+			},
+		},
+	}
+
+	if !googleProto.Equal(apl, expected) {
+		t.Fatalf("Unexpected APL parse result:\n%s", FormatAPLRotationText(apl))
+	}
+}
+
+func TestAPLTextParseErrors(t *testing.T) {
+	for _, text := range []string{
+		"actions+=/not_an_action",
+		"actions+=/cast_spell,spell_id=bogus:1",
+		"actions+=/cast_spell,spell_id=spell:1,if=current_time <",
+		"actions+=/wait,(current_time",
+		"unknown+=/wait,1s",
+	} {
+		if _, err := ParseAPLRotationText(text); err == nil {
+			t.Errorf("Expected an error when parsing %q", text)
+		}
+	}
+}
+
+func TestAPLTextOperatorPrecedence(t *testing.T) {
+	for _, text := range []string{
+		"actions+=/wait_until,current_time > 1 | gcd_is_ready & front_of_target\n",
+		"actions+=/wait_until,(current_time > 1 | gcd_is_ready) & front_of_target\n",
+		"actions+=/wait_until,1 - (2 - 3) * 4 / (5 + current_time) >= -7s\n",
+		"actions+=/wait_until,!(current_time > 1) | !is_execute_phase(E20)\n",
+		"actions+=/wait_until,max(current_mana, 100) == \"some string\"\n",
+	} {
+		apl, err := ParseAPLRotationText(text)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %s", text, err)
+		}
+		if formatted := FormatAPLRotationText(apl); formatted != text {
+			t.Errorf("Expected %q but formatted as %q", text, formatted)
+		}
+	}
+}
+
+func TestAPLTextRoundTripPresets(t *testing.T) {
+	files, err := filepath.Glob("../../ui/*/apls/*.apl.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("No preset APLs found")
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %s", file, err)
+		}
+		apl := APLRotationFromJsonString(string(data))
+
+		text := FormatAPLRotationText(apl)
+		parsed, err := ParseAPLRotationText(text)
+		if err != nil {
+			t.Errorf("%s: failed to parse formatted APL: %s\n%s", file, err, text)
+			continue
+		}
+		if !googleProto.Equal(apl, parsed) {
+			t.Errorf("%s: APL changed after round trip through text format:\n%s", file, text)
+			continue
+		}
+		if reformatted := FormatAPLRotationText(parsed); reformatted != text {
+			t.Errorf("%s: formatting is not stable:\n%s\n%s", file, text, reformatted)
+		}
+	}
+}
@@ -93,6 +93,8 @@ func (rot *APLRotation) newAPLValue(config *proto.APLValue) APLValue {
 		return rot.newValueIsExecutePhase(config.GetIsExecutePhase())
 	case *proto.APLValue_NumberTargets:
 		return rot.newValueNumberTargets(config.GetNumberTargets())
+	case *proto.APLValue_TimeToNextMovement:
+		return rot.newValueTimeToNextMovement(config.GetTimeToNextMovement())
 
 	// Resources
 	case *proto.APLValue_CurrentHealth:
@@ -117,6 +119,8 @@ func (rot *APLRotation) newAPLValue(config *proto.APLValue) APLValue {
 		return rot.newValueTimeToEnergyTick(config.GetTimeToEnergyTick())
 	case *proto.APLValue_EnergyThreshold:
 		return rot.newValueEnergyThreshold(config.GetEnergyThreshold())
+	case *proto.APLValue_ThreatMargin:
+		return rot.newValueThreatMargin(config.GetThreatMargin())
 
 	// Stats
 	case *proto.APLValue_CurrentAttackPower:
@@ -157,6 +161,8 @@ func (rot *APLRotation) newAPLValue(config *proto.APLValue) APLValue {
 		return rot.newValueSpellChanneledTicks(config.GetSpellChanneledTicks())
 	case *proto.APLValue_SpellCurrentCost:
 		return rot.newValueSpellCurrentCost(config.GetSpellCurrentCost())
+	case *proto.APLValue_SpellManaEfficiency:
+		return rot.newValueSpellManaEfficiency(config.GetSpellManaEfficiency())
 
 	// Auras
 	case *proto.APLValue_AuraIsKnown:
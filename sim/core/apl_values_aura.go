@@ -57,7 +57,7 @@ func (value *APLValueAuraIsActive) String() string {
 type APLValueAuraIsActiveWithReactionTime struct {
 	DefaultAPLValueImpl
 	aura         AuraReference
-	reactionTime time.Duration
+	reactionTime auraReactionTime
 }
 
 func (rot *APLRotation) newValueAuraIsActiveWithReactionTime(config *proto.APLValueAuraIsActiveWithReactionTime) APLValue {
@@ -67,7 +67,7 @@ func (rot *APLRotation) newValueAuraIsActiveWithReactionTime(config *proto.APLVa
 	}
 	return &APLValueAuraIsActiveWithReactionTime{
 		aura:         aura,
-		reactionTime: rot.unit.ReactionTime,
+		reactionTime: auraReactionTime{unit: rot.unit},
 	}
 }
 func (value *APLValueAuraIsActiveWithReactionTime) Type() proto.APLValueType {
@@ -75,7 +75,7 @@ func (value *APLValueAuraIsActiveWithReactionTime) Type() proto.APLValueType {
 }
 func (value *APLValueAuraIsActiveWithReactionTime) GetBool(sim *Simulation) bool {
 	aura := value.aura.Get()
-	return aura.IsActive() && aura.TimeActive(sim) >= value.reactionTime
+	return aura.IsActive() && aura.TimeActive(sim) >= value.reactionTime.Get(sim, aura)
 }
 func (value *APLValueAuraIsActiveWithReactionTime) String() string {
 	return fmt.Sprintf("Aura Active With Reaction Time(%s)", value.aura.String())
@@ -160,7 +160,7 @@ func (value *APLValueAuraInternalCooldown) String() string {
 type APLValueAuraICDIsReadyWithReactionTime struct {
 	DefaultAPLValueImpl
 	aura         AuraReference
-	reactionTime time.Duration
+	reactionTime auraReactionTime
 }
 
 func (rot *APLRotation) newValueAuraICDIsReadyWithReactionTime(config *proto.APLValueAuraICDIsReadyWithReactionTime) APLValue {
@@ -170,7 +170,7 @@ func (rot *APLRotation) newValueAuraICDIsReadyWithReactionTime(config *proto.APL
 	}
 	return &APLValueAuraICDIsReadyWithReactionTime{
 		aura:         aura,
-		reactionTime: rot.unit.ReactionTime,
+		reactionTime: auraReactionTime{unit: rot.unit},
 	}
 }
 func (value *APLValueAuraICDIsReadyWithReactionTime) Type() proto.APLValueType {
@@ -178,7 +178,7 @@ func (value *APLValueAuraICDIsReadyWithReactionTime) Type() proto.APLValueType {
 }
 func (value *APLValueAuraICDIsReadyWithReactionTime) GetBool(sim *Simulation) bool {
 	aura := value.aura.Get()
-	return aura.Icd.IsReady(sim) || (aura.IsActive() && aura.TimeActive(sim) < value.reactionTime)
+	return aura.Icd.IsReady(sim) || (aura.IsActive() && aura.TimeActive(sim) < value.reactionTime.Get(sim, aura))
 }
 func (value *APLValueAuraICDIsReadyWithReactionTime) String() string {
 	return fmt.Sprintf("Aura ICD Is Ready with Reaction Time(%s)", value.aura.String())
@@ -8,13 +8,17 @@ import (
 
 type APLValueAutoTimeToNext struct {
 	DefaultAPLValueImpl
-	unit     *Unit
+	unit     UnitReference
 	autoType proto.APLValueAutoTimeToNext_AttackType
 }
 
 func (rot *APLRotation) newValueAutoTimeToNext(config *proto.APLValueAutoTimeToNext) APLValue {
+	unit := rot.GetSourceUnit(config.SourceUnit)
+	if unit.Get() == nil {
+		return nil
+	}
 	return &APLValueAutoTimeToNext{
-		unit:     rot.unit,
+		unit:     unit,
 		autoType: config.AutoType,
 	}
 }
@@ -22,18 +26,19 @@ func (value *APLValueAutoTimeToNext) Type() proto.APLValueType {
 	return proto.APLValueType_ValueTypeDuration
 }
 func (value *APLValueAutoTimeToNext) GetDuration(sim *Simulation) time.Duration {
+	unit := value.unit.Get()
 	switch value.autoType {
 	case proto.APLValueAutoTimeToNext_Melee:
-		return max(0, value.unit.AutoAttacks.NextAttackAt()-sim.CurrentTime)
+		return max(0, unit.AutoAttacks.NextAttackAt()-sim.CurrentTime)
 	case proto.APLValueAutoTimeToNext_MainHand:
-		return max(0, value.unit.AutoAttacks.MainhandSwingAt()-sim.CurrentTime)
+		return max(0, unit.AutoAttacks.MainhandSwingAt()-sim.CurrentTime)
 	case proto.APLValueAutoTimeToNext_OffHand:
-		return max(0, value.unit.AutoAttacks.OffhandSwingAt()-sim.CurrentTime)
+		return max(0, unit.AutoAttacks.OffhandSwingAt()-sim.CurrentTime)
 	case proto.APLValueAutoTimeToNext_Ranged:
-		return max(0, value.unit.AutoAttacks.NextRangedAttackAt()-sim.CurrentTime)
+		return max(0, unit.AutoAttacks.NextRangedAttackAt()-sim.CurrentTime)
 	}
 	// defaults to Any
-	return max(0, value.unit.AutoAttacks.NextAnyAttackAt()-sim.CurrentTime)
+	return max(0, unit.AutoAttacks.NextAnyAttackAt()-sim.CurrentTime)
 }
 func (value *APLValueAutoTimeToNext) String() string {
 	return "Auto Time To Next"
@@ -92,6 +92,26 @@ func (value *APLValueNumberTargets) String() string {
 	return "Num Targets"
 }
 
+type APLValueTimeToNextMovement struct {
+	DefaultAPLValueImpl
+	unit *Unit
+}
+
+func (rot *APLRotation) newValueTimeToNextMovement(config *proto.APLValueTimeToNextMovement) APLValue {
+	return &APLValueTimeToNextMovement{
+		unit: rot.unit,
+	}
+}
+func (value *APLValueTimeToNextMovement) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeDuration
+}
+func (value *APLValueTimeToNextMovement) GetDuration(sim *Simulation) time.Duration {
+	return value.unit.TimeToNextMovement(sim)
+}
+func (value *APLValueTimeToNextMovement) String() string {
+	return "Time To Next Movement"
+}
+
 type APLValueIsExecutePhase struct {
 	DefaultAPLValueImpl
 	threshold proto.APLValueIsExecutePhase_ExecutePhaseThreshold
@@ -298,3 +298,23 @@ func (value *APLValueEnergyThreshold) GetBool(_ *Simulation) bool {
 func (value *APLValueEnergyThreshold) String() string {
 	return "Energy Threshold"
 }
+
+type APLValueThreatMargin struct {
+	DefaultAPLValueImpl
+	unit *Unit
+}
+
+func (rot *APLRotation) newValueThreatMargin(_ *proto.APLValueThreatMargin) APLValue {
+	return &APLValueThreatMargin{
+		unit: rot.unit,
+	}
+}
+func (value *APLValueThreatMargin) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeFloat
+}
+func (value *APLValueThreatMargin) GetFloat(sim *Simulation) float64 {
+	return value.unit.ThreatMargin(sim)
+}
+func (value *APLValueThreatMargin) String() string {
+	return "Threat Margin"
+}
@@ -276,3 +276,37 @@ func (value *APLValueSpellCurrentCost) GetFloat(_ *Simulation) float64 {
 func (value *APLValueSpellCurrentCost) String() string {
 	return fmt.Sprintf("CurrentCost(%s)", value.spell.ActionID)
 }
+
+type APLValueSpellManaEfficiency struct {
+	DefaultAPLValueImpl
+	unit  *Unit
+	spell *Spell
+}
+
+func (rot *APLRotation) newValueSpellManaEfficiency(config *proto.APLValueSpellManaEfficiency) APLValue {
+	spell := rot.GetAPLSpell(config.SpellId)
+	if spell == nil {
+		return nil
+	}
+	if spell.Cost == nil || spell.Cost.CostType() != CostTypeMana {
+		rot.ValidationWarning("%s does not cost mana", spell.ActionID)
+		return nil
+	}
+	return &APLValueSpellManaEfficiency{
+		unit:  rot.unit,
+		spell: spell,
+	}
+}
+func (value *APLValueSpellManaEfficiency) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeFloat
+}
+func (value *APLValueSpellManaEfficiency) GetFloat(sim *Simulation) float64 {
+	cost := value.spell.Cost.GetCurrentCost()
+	if cost <= 0 {
+		return 0
+	}
+	return value.spell.ExpectedDamagePerCast(sim, value.unit.CurrentTarget) / cost
+}
+func (value *APLValueSpellManaEfficiency) String() string {
+	return fmt.Sprintf("ManaEfficiency(%s)", value.spell.ActionID)
+}
@@ -42,3 +42,34 @@ func TestValueConst(t *testing.T) {
 		t.Fatalf("Unexpected coerced duration value %s", coercedDurVal.GetDuration(sim))
 	}
 }
+
+func TestGetAPLSpellRank(t *testing.T) {
+	unit := &Unit{Label: "Caster"}
+	rank1 := &Spell{ActionID: ActionID{SpellID: 133}, SpellCode: 1, Rank: 1}
+	rank2 := &Spell{ActionID: ActionID{SpellID: 143}, SpellCode: 1, Rank: 2}
+	unranked := &Spell{ActionID: ActionID{SpellID: 12051}}
+	unit.Spellbook = []*Spell{rank1, rank2, unranked}
+	rot := &APLRotation{
+		unit: unit,
+	}
+
+	spellID := func(id int32, rank int32) *proto.ActionID {
+		return &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: id}, Rank: rank}
+	}
+
+	if spell := rot.GetAPLSpell(spellID(143, 0)); spell != rank2 {
+		t.Fatalf("Expected the spell of the ID without a rank")
+	}
+	if spell := rot.GetAPLSpell(spellID(143, 2)); spell != rank2 {
+		t.Fatalf("Expected the spell of the ID with its own rank")
+	}
+	if spell := rot.GetAPLSpell(spellID(143, 1)); spell != rank1 {
+		t.Fatalf("Expected the rank to pick another rank of the spell")
+	}
+	if spell := rot.GetAPLSpell(spellID(12051, 3)); spell != unranked {
+		t.Fatalf("Expected the rank to be ignored for spells without ranks")
+	}
+	if spell := rot.GetAPLSpell(spellID(143, 3)); spell != nil || len(rot.curWarnings) != 1 {
+		t.Fatalf("Expected a warning for an unknown rank")
+	}
+}
@@ -367,13 +367,13 @@ func (wa *WeaponAttack) swing(sim *Simulation) time.Duration {
 
 		if wa.extraAttacksPending > 0 {
 			wa.spell.SetMetricsSplit(1)
-			wa.swingAt = sim.CurrentTime + SpellBatchWindow
+			wa.swingAt = sim.NextSpellBatchAt()
 			wa.lastSwingAt = sim.CurrentTime
 			sim.rescheduleWeaponAttack(wa.swingAt) // Required to fix extra attack procs triggered during swing
 		}
 
 		if !sim.Options.Interactive && wa.unit.Rotation != nil {
-			wa.unit.Rotation.DoNextAction(sim)
+			wa.unit.Rotation.DoNextActionAfterReaction(sim)
 		}
 	} else {
 		// Delay till cast finishes if casting or 100 ms if not
@@ -470,11 +470,8 @@ func (unit *Unit) EnableAutoAttacks(agent Agent, options AutoAttackOptions) {
 			baseDamage := autoInProgress.Unit.MHWeaponDamage(sim, autoInProgress.MeleeAttackPower())
 			result := autoInProgress.CalcDamage(sim, target, baseDamage, autoInProgress.OutcomeMeleeWhite)
 
-			StartDelayedAction(sim, DelayedActionOptions{
-				DoAt: sim.CurrentTime + SpellBatchWindow,
-				OnAction: func(s *Simulation) {
-					autoInProgress.DealDamage(sim, result)
-				},
+			sim.DoInNextSpellBatch(func(sim *Simulation) {
+				autoInProgress.DealDamage(sim, result)
 			})
 		},
 	}
@@ -499,11 +496,8 @@ func (unit *Unit) EnableAutoAttacks(agent Agent, options AutoAttackOptions) {
 			baseDamage := autoInProgress.Unit.OHWeaponDamage(sim, autoInProgress.MeleeAttackPower())
 			result := autoInProgress.CalcDamage(sim, target, baseDamage, autoInProgress.OutcomeMeleeWhite)
 
-			StartDelayedAction(sim, DelayedActionOptions{
-				DoAt: sim.CurrentTime + SpellBatchWindow,
-				OnAction: func(s *Simulation) {
-					autoInProgress.DealDamage(sim, result)
-				},
+			sim.DoInNextSpellBatch(func(sim *Simulation) {
+				autoInProgress.DealDamage(sim, result)
 			})
 		},
 	}
@@ -803,7 +797,7 @@ func (aa *AutoAttacks) ExtraOHAttack(sim *Simulation, attacks int32, actionID Ac
 		attacksText := Ternary(attacks == 1, "attack", "attacks")
 		aa.oh.unit.Log(sim, "gained %d extra off-hand %s from %s triggered by %s", attacks, attacksText, actionID, triggerAction)
 	}
-	aa.oh.swingAt = sim.CurrentTime + SpellBatchWindow
+	aa.oh.swingAt = sim.NextSpellBatchAt()
 	aa.oh.spell.SetMetricsSplit(1)
 	sim.rescheduleWeaponAttack(aa.oh.swingAt)
 	aa.oh.extraAttacksPending += attacks
@@ -816,7 +810,7 @@ func (aa *AutoAttacks) ExtraRangedAttack(sim *Simulation, attacks int32, actionI
 		attacksText := Ternary(attacks == 1, "attack", "attacks")
 		aa.mh.unit.Log(sim, "gained %d extra ranged %s from %s triggered by %s", attacks, attacksText, actionID, triggerAction)
 	}
-	aa.ranged.swingAt = sim.CurrentTime + SpellBatchWindow
+	aa.ranged.swingAt = sim.NextSpellBatchAt()
 	aa.ranged.spell.SetMetricsSplit(1)
 	sim.rescheduleWeaponAttack(aa.ranged.swingAt)
 	aa.ranged.extraAttacks += attacks
@@ -84,6 +84,9 @@ type Aura struct {
 
 	ExclusiveEffects []*ExclusiveEffect
 
+	// Set if this aura takes one of its target's limited debuff slots.
+	debuffSlot *debuffSlot
+
 	// Lifecycle callbacks.
 	OnInit          OnInit
 	OnReset         OnReset
@@ -585,6 +588,13 @@ func (aura *Aura) Activate(sim *Simulation) {
 		}
 	}
 
+	if aura.debuffSlot != nil && !aura.Unit.debuffSlots.take(sim, aura) {
+		for _, ee := range aura.ExclusiveEffects {
+			ee.Deactivate(sim)
+		}
+		return
+	}
+
 	aura.startTime = sim.CurrentTime
 	aura.Refresh(sim)
 	aura.active = true
@@ -674,6 +684,9 @@ func (aura *Aura) Deactivate(sim *Simulation) {
 
 	aura.expires = 0
 	aura.fadeTime = sim.CurrentTime
+	if aura.debuffSlot != nil {
+		aura.Unit.debuffSlots.release(aura)
+	}
 	if aura.activeIndex != Inactive {
 		removeActiveIndex := aura.activeIndex
 		aura.Unit.activeAuras = removeBySwappingToBack(aura.Unit.activeAuras, removeActiveIndex)
@@ -0,0 +1,287 @@
+package core
+
+import (
+	"slices"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+const defaultBlessingsPlanIterations = 100
+
+// A buff the planner can assign, with its marginal value for the current player.
+type plannedBuff struct {
+	value *proto.BuffValue
+	apply func(buffs *proto.IndividualBuffs)
+	score float64
+}
+
+// Plans which blessings each paladin gives each player, and who gets the druids'
+// Innervates. The value of every buff for every player is measured with short sims of
+// the player's party, with the player's own blessings and innervates removed.
+//
+// Buff values are assumed to add up, so each player's blessings are planned on their
+// own: each paladin gives each player one blessing, and a player can't get the same
+// blessing twice.
+func PlanBlessings(request *proto.BlessingsPlanRequest, signals simsignals.Signals) (result *proto.BlessingsPlanResult) {
+	defer recoverRequestError(func(errorOutcome *proto.ErrorOutcome) {
+		result = &proto.BlessingsPlanResult{Error: errorOutcome}
+	})
+
+	if request.Raid == nil || request.Encounter == nil {
+		return &proto.BlessingsPlanResult{Error: &proto.ErrorOutcome{Message: "missing raid or encounter"}}
+	}
+
+	dpsWeight, hpsWeight, tpsWeight := request.DpsWeight, request.HpsWeight, request.TpsWeight
+	if dpsWeight == 0 && hpsWeight == 0 && tpsWeight == 0 {
+		dpsWeight, hpsWeight = 1, 1
+	}
+
+	simOptions := requestSimOptions(request.SimOptions, defaultBlessingsPlanIterations)
+
+	result = &proto.BlessingsPlanResult{}
+	innervateScores := make([]float64, 0)
+
+	numParties := len(request.Raid.Parties)
+	if request.Raid.NumActiveParties > 0 {
+		numParties = min(numParties, int(request.Raid.NumActiveParties))
+	}
+
+	for partyIdx, party := range request.Raid.Parties[:numParties] {
+		for playerIdx, player := range party.GetPlayers() {
+			if player.GetClass() == proto.Class_ClassUnknown {
+				continue
+			}
+			if signals.Abort.IsTriggered() {
+				return &proto.BlessingsPlanResult{Error: &proto.ErrorOutcome{Message: "aborted"}}
+			}
+
+			candidates := newPlannedBuffs(request)
+			playerSim := func(apply func(buffs *proto.IndividualBuffs)) *proto.UnitMetrics {
+				raid := singlePartyRaid(request.Raid, partyIdx)
+				buffs := raid.Parties[0].Players[playerIdx].Buffs
+				clearPlannedBuffs(buffs)
+				if apply != nil {
+					apply(buffs)
+				}
+
+				simResult := RunSim(&proto.RaidSimRequest{
+					Raid:       raid,
+					Encounter:  request.Encounter,
+					SimOptions: simOptions,
+				}, nil, signals)
+				if simResult.Error != nil {
+					panic(simResult.Error.Message)
+				}
+				return simResult.RaidMetrics.Parties[0].Players[playerIdx]
+			}
+
+			base := playerSim(nil)
+			for _, candidate := range candidates {
+				metrics := playerSim(candidate.apply)
+				candidate.value.Dps = metrics.Dps.Avg - base.Dps.Avg
+				candidate.value.Hps = metrics.Hps.Avg - base.Hps.Avg
+				candidate.value.Tps = metrics.Threat.Avg - base.Threat.Avg
+				candidate.score = dpsWeight*candidate.value.Dps + hpsWeight*candidate.value.Hps + tpsWeight*candidate.value.Tps
+			}
+
+			plan := &proto.PlayerBlessingsPlan{
+				Player: &proto.UnitReference{Type: proto.UnitReference_Player, Index: int32(partyIdx*5 + playerIdx)},
+				Name:   player.Name,
+				Values: MapSlice(candidates, func(candidate *plannedBuff) *proto.BuffValue { return candidate.value }),
+			}
+
+			buffs := &proto.IndividualBuffs{}
+			if player.Buffs != nil {
+				buffs = googleProto.Clone(player.Buffs).(*proto.IndividualBuffs)
+			}
+			clearPlannedBuffs(buffs)
+
+			for paladinIdx, candidate := range assignBlessings(request.Paladins, candidates) {
+				plan.Blessings = append(plan.Blessings, proto.Blessings_BlessingUnknown)
+				if candidate != nil {
+					plan.Blessings[paladinIdx] = candidate.value.Blessing
+					candidate.apply(buffs)
+				}
+			}
+			plan.Buffs = buffs
+
+			innervateScore := 0.0
+			if idx := slices.IndexFunc(candidates, func(candidate *plannedBuff) bool {
+				return candidate.value.Blessing == proto.Blessings_BlessingUnknown
+			}); idx != -1 {
+				innervateScore = candidates[idx].score
+			}
+			innervateScores = append(innervateScores, innervateScore)
+			result.Players = append(result.Players, plan)
+		}
+	}
+
+	// Each druid innervates the player who gains the most from it.
+	order := make([]int, len(result.Players))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		if innervateScores[a] > innervateScores[b] {
+			return -1
+		} else if innervateScores[a] < innervateScores[b] {
+			return 1
+		}
+		return 0
+	})
+	for _, playerIdx := range order[:min(len(order), int(request.NumInnervates))] {
+		if innervateScores[playerIdx] <= 0 {
+			break
+		}
+		result.Players[playerIdx].Innervates = 1
+		result.Players[playerIdx].Buffs.Innervates = 1
+	}
+
+	return result
+}
+
+// Returns the buffs worth measuring for the given paladins and druids.
+func newPlannedBuffs(request *proto.BlessingsPlanRequest) []*plannedBuff {
+	var candidates []*plannedBuff
+	add := func(blessing proto.Blessings, improved bool, apply func(buffs *proto.IndividualBuffs)) {
+		candidates = append(candidates, &plannedBuff{
+			value: &proto.BuffValue{Blessing: blessing, Improved: improved},
+			apply: apply,
+		})
+	}
+	anyPaladin := func(hasTalent func(paladin *proto.BlessingsPlanPaladin) bool) bool {
+		return slices.ContainsFunc(request.Paladins, hasTalent)
+	}
+
+	if len(request.Paladins) > 0 {
+		add(proto.Blessings_BlessingOfMight, false, func(buffs *proto.IndividualBuffs) {
+			buffs.BlessingOfMight = proto.TristateEffect_TristateEffectRegular
+		})
+		add(proto.Blessings_BlessingOfWisdom, false, func(buffs *proto.IndividualBuffs) {
+			buffs.BlessingOfWisdom = proto.TristateEffect_TristateEffectRegular
+		})
+	}
+	if anyPaladin(func(paladin *proto.BlessingsPlanPaladin) bool { return paladin.ImprovedBlessingOfMight }) {
+		add(proto.Blessings_BlessingOfMight, true, func(buffs *proto.IndividualBuffs) {
+			buffs.BlessingOfMight = proto.TristateEffect_TristateEffectImproved
+		})
+	}
+	if anyPaladin(func(paladin *proto.BlessingsPlanPaladin) bool { return paladin.ImprovedBlessingOfWisdom }) {
+		add(proto.Blessings_BlessingOfWisdom, true, func(buffs *proto.IndividualBuffs) {
+			buffs.BlessingOfWisdom = proto.TristateEffect_TristateEffectImproved
+		})
+	}
+	if anyPaladin(func(paladin *proto.BlessingsPlanPaladin) bool { return paladin.BlessingOfKings }) {
+		add(proto.Blessings_BlessingOfKings, false, func(buffs *proto.IndividualBuffs) {
+			buffs.BlessingOfKings = true
+		})
+	}
+	if anyPaladin(func(paladin *proto.BlessingsPlanPaladin) bool { return paladin.BlessingOfSanctuary }) {
+		add(proto.Blessings_BlessingOfSanctuary, false, func(buffs *proto.IndividualBuffs) {
+			buffs.BlessingOfSanctuary = true
+		})
+	}
+	if request.NumInnervates > 0 {
+		add(proto.Blessings_BlessingUnknown, false, func(buffs *proto.IndividualBuffs) {
+			buffs.Innervates = 1
+		})
+	}
+	return candidates
+}
+
+func clearPlannedBuffs(buffs *proto.IndividualBuffs) {
+	buffs.BlessingOfKings = false
+	buffs.BlessingOfMight = proto.TristateEffect_TristateEffectMissing
+	buffs.BlessingOfWisdom = proto.TristateEffect_TristateEffectMissing
+	buffs.BlessingOfSanctuary = false
+	buffs.Innervates = 0
+}
+
+// Returns a copy of the raid with only the given party, as the first party.
+func singlePartyRaid(raidProto *proto.Raid, partyIdx int) *proto.Raid {
+	raid := googleProto.Clone(raidProto).(*proto.Raid)
+	raid.Parties = []*proto.Party{raid.Parties[partyIdx]}
+	raid.NumActiveParties = 1
+	for _, player := range raid.Parties[0].Players {
+		if player.Buffs == nil {
+			player.Buffs = &proto.IndividualBuffs{}
+		}
+	}
+
+	var tanks []*proto.UnitReference
+	for _, tank := range raid.Tanks {
+		if tank.Type == proto.UnitReference_Player && int(tank.Index)/5 == partyIdx {
+			tanks = append(tanks, &proto.UnitReference{Type: proto.UnitReference_Player, Index: tank.Index % 5})
+		}
+	}
+	raid.Tanks = tanks
+	return raid
+}
+
+// Picks a blessing from each paladin, or nil, to maximize the total score of a player's
+// blessings without giving the same blessing twice.
+func assignBlessings(paladins []*proto.BlessingsPlanPaladin, candidates []*plannedBuff) []*plannedBuff {
+	best := make([]*plannedBuff, len(paladins))
+	bestScore := 0.0
+	current := make([]*plannedBuff, len(paladins))
+
+	// Returns the candidate for a blessing as given by the paladin, or nil if the
+	// paladin can't give it.
+	paladinCandidate := func(paladin *proto.BlessingsPlanPaladin, blessing proto.Blessings) *plannedBuff {
+		improved := false
+		switch blessing {
+		case proto.Blessings_BlessingOfMight:
+			improved = paladin.ImprovedBlessingOfMight
+		case proto.Blessings_BlessingOfWisdom:
+			improved = paladin.ImprovedBlessingOfWisdom
+		case proto.Blessings_BlessingOfKings:
+			if !paladin.BlessingOfKings {
+				return nil
+			}
+		case proto.Blessings_BlessingOfSanctuary:
+			if !paladin.BlessingOfSanctuary {
+				return nil
+			}
+		default:
+			return nil
+		}
+		idx := slices.IndexFunc(candidates, func(candidate *plannedBuff) bool {
+			return candidate.value.Blessing == blessing && candidate.value.Improved == improved
+		})
+		if idx == -1 {
+			return nil
+		}
+		return candidates[idx]
+	}
+
+	var search func(paladinIdx int, score float64)
+	search = func(paladinIdx int, score float64) {
+		if paladinIdx == len(paladins) {
+			if score > bestScore {
+				bestScore = score
+				copy(best, current)
+			}
+			return
+		}
+
+		current[paladinIdx] = nil
+		search(paladinIdx+1, score)
+
+		for _, blessing := range []proto.Blessings{proto.Blessings_BlessingOfKings, proto.Blessings_BlessingOfMight, proto.Blessings_BlessingOfWisdom, proto.Blessings_BlessingOfSanctuary} {
+			candidate := paladinCandidate(paladins[paladinIdx], blessing)
+			if candidate == nil || candidate.score <= 0 || slices.ContainsFunc(current[:paladinIdx], func(given *plannedBuff) bool {
+				return given != nil && given.value.Blessing == blessing
+			}) {
+				continue
+			}
+			current[paladinIdx] = candidate
+			search(paladinIdx+1, score+candidate.score)
+		}
+		current[paladinIdx] = nil
+	}
+	search(0, 0)
+
+	return best
+}
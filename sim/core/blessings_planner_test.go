@@ -0,0 +1,32 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+func TestAssignBlessings(t *testing.T) {
+	paladins := []*proto.BlessingsPlanPaladin{
+		{},
+		{ImprovedBlessingOfMight: true, BlessingOfKings: true},
+	}
+	candidates := newPlannedBuffs(&proto.BlessingsPlanRequest{Paladins: paladins})
+	scores := map[proto.Blessings][2]float64{
+		proto.Blessings_BlessingOfMight:  {40, 50},
+		proto.Blessings_BlessingOfWisdom: {0, 0},
+		proto.Blessings_BlessingOfKings:  {45, 0},
+	}
+	for _, candidate := range candidates {
+		candidate.score = scores[candidate.value.Blessing][TernaryInt32(candidate.value.Improved, 1, 0)]
+	}
+
+	// Kings (45) + regular Might (40) beats improved Might (50) with no Kings.
+	assigned := assignBlessings(paladins, candidates)
+	if assigned[0] == nil || assigned[0].value.Blessing != proto.Blessings_BlessingOfMight || assigned[0].value.Improved {
+		t.Fatalf("Expected the first paladin to give regular Might, got %+v", assigned[0])
+	}
+	if assigned[1] == nil || assigned[1].value.Blessing != proto.Blessings_BlessingOfKings {
+		t.Fatalf("Expected the second paladin to give Kings, got %+v", assigned[1])
+	}
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"fmt"
 	"math"
 	"time"
 
@@ -296,7 +297,7 @@ func applyBuffEffects(agent Agent, playerFaction proto.Faction, raidBuffs *proto
 	}
 
 	if raidBuffs.TrueshotAura {
-		TrueshotAura(&character.Unit)
+		TrueshotAura(&character.Unit, 100)
 	}
 
 	if raidBuffs.PowerWordFortitude > 0 {
@@ -398,34 +399,36 @@ func applyBuffEffects(agent Agent, playerFaction proto.Faction, raidBuffs *proto
 	}
 
 	// World Buffs
+	worldBuffDurations := individualBuffs.GetWorldBuffDurations()
+
 	ApplyDragonslayerBuffs(&character.Unit, individualBuffs)
 
 	if individualBuffs.SpiritOfZandalar {
-		ApplySpiritOfZandalar(&character.Unit)
+		applyWorldBuffDuration(ApplySpiritOfZandalar(&character.Unit), worldBuffDurations.GetSpiritOfZandalar())
 	}
 
 	if individualBuffs.SongflowerSerenade {
-		ApplySongflowerSerenade(&character.Unit)
+		applyWorldBuffDuration(ApplySongflowerSerenade(&character.Unit), worldBuffDurations.GetSongflowerSerenade())
 	}
 
 	ApplyWarchiefsBuffs(&character.Unit, individualBuffs, isAlliance, isHorde)
 
 	// Dire Maul Buffs
 	if individualBuffs.FengusFerocity {
-		ApplyFengusFerocity(&character.Unit)
+		applyWorldBuffDuration(ApplyFengusFerocity(&character.Unit), worldBuffDurations.GetFengusFerocity())
 	}
 
 	if individualBuffs.MoldarsMoxie {
-		ApplyMoldarsMoxie(&character.Unit)
+		applyWorldBuffDuration(ApplyMoldarsMoxie(&character.Unit), worldBuffDurations.GetMoldarsMoxie())
 	}
 
 	if individualBuffs.SlipkiksSavvy {
-		ApplySlipkiksSavvy(&character.Unit)
+		applyWorldBuffDuration(ApplySlipkiksSavvy(&character.Unit), worldBuffDurations.GetSlipkiksSavvy())
 	}
 
 	// Darkmoon Faire Buffs
 	if individualBuffs.SaygesFortune != proto.SaygesFortune_SaygesUnknown {
-		ApplySaygesFortunes(character, individualBuffs.SaygesFortune)
+		applyWorldBuffDuration(ApplySaygesFortunes(character, individualBuffs.SaygesFortune), worldBuffDurations.GetSaygesFortune())
 	}
 
 	// TODO: Classic provide in APL?
@@ -1392,6 +1395,25 @@ func GraceOfAirTotemAura(unit *Unit, multiplier float64) *Aura {
 	return aura
 }
 
+func ManaSpringTotemAura(unit *Unit, multiplier float64) *Aura {
+	rank := 4
+	spellID := []int32{0, 5675, 10495, 10496, 10497}[rank]
+	duration := time.Minute
+	updateStats := BuffSpellValues[ManaSpring].Multiply(multiplier).Floor()
+
+	return unit.GetOrRegisterAura(Aura{
+		Label:    "Mana Spring Totem",
+		ActionID: ActionID{SpellID: spellID},
+		Duration: duration,
+		OnGain: func(aura *Aura, sim *Simulation) {
+			aura.Unit.AddStatsDynamic(sim, updateStats)
+		},
+		OnExpire: func(aura *Aura, sim *Simulation) {
+			aura.Unit.AddStatsDynamic(sim, updateStats.Multiply(-1))
+		},
+	})
+}
+
 const BattleShoutRanks = 7
 
 var BattleShoutSpellId = [BattleShoutRanks + 1]int32{0, 6673, 5242, 6192, 11549, 11550, 11551, 25289}
@@ -1421,10 +1443,7 @@ func BattleShoutAura(unit *Unit, impBattleShout int32, boomingVoicePts int32, ha
 	})
 }
 
-func TrueshotAura(unit *Unit) *Aura {
-	rangedAP := 100.0
-	meleeAP := 100.0
-
+func TrueshotAura(unit *Unit, attackPower float64) *Aura {
 	aura := MakePermanent(unit.RegisterAura(Aura{
 		Label:    "Trueshot Aura",
 		ActionID: ActionID{SpellID: 20906},
@@ -1433,8 +1452,8 @@ func TrueshotAura(unit *Unit) *Aura {
 	makeExclusiveBuff(aura, BuffConfig{
 		Category: "TrueshotAura",
 		Stats: []StatConfig{
-			{stats.AttackPower, rangedAP, false},
-			{stats.RangedAttackPower, meleeAP, false},
+			{stats.AttackPower, attackPower, false},
+			{stats.RangedAttackPower, attackPower, false},
 		},
 	})
 
@@ -1522,9 +1541,17 @@ func BattleSquawkAura(character *Unit, stackcount int32) *Aura {
 // }
 
 func CreateExtraAttackAuraCommon(character *Character, buffActionID ActionID, auraLabel string, rank int32, getBonusAP func(aura *Aura, rank int32) float64) *Aura {
+	apBuffAura, procAura := registerExtraAttackAuras(&character.Unit, buffActionID, auraLabel, rank, getBonusAP)
+	MakePermanent(procAura)
+	return apBuffAura
+}
+
+// Registers the bonus AP aura and the aura which procs the extra attacks, leaving it to the
+// caller whether the proc aura is permanent or activated by its source.
+func registerExtraAttackAuras(unit *Unit, buffActionID ActionID, auraLabel string, rank int32, getBonusAP func(aura *Aura, rank int32) float64) (*Aura, *Aura) {
 	var bonusAP float64
 
-	apBuffAura := character.GetOrRegisterAura(Aura{
+	apBuffAura := unit.GetOrRegisterAura(Aura{
 		Label:     auraLabel + " Buff",
 		ActionID:  buffActionID,
 		Duration:  time.Millisecond * 1500,
@@ -1538,7 +1565,7 @@ func CreateExtraAttackAuraCommon(character *Character, buffActionID ActionID, au
 		},
 	})
 
-	MakePermanent(character.GetOrRegisterAura(Aura{
+	MakePermanent(unit.GetOrRegisterAura(Aura{
 		Label:     "Extra Attacks  (Main Hand)", // Tracks Stored Extra Attacks from all sources
 		ActionID:  ActionID{SpellID: 21919},     // Thrash ID
 		Duration:  NeverExpires,
@@ -1549,13 +1576,13 @@ func CreateExtraAttackAuraCommon(character *Character, buffActionID ActionID, au
 	}))
 
 	icd := Cooldown{
-		Timer:    character.NewTimer(),
+		Timer:    unit.NewTimer(),
 		Duration: time.Millisecond * 1500,
 	}
 
 	apBuffAura.Icd = &icd
 
-	MakePermanent(character.GetOrRegisterAura(Aura{
+	procAura := unit.GetOrRegisterAura(Aura{
 		Label: auraLabel,
 		OnSpellHitDealt: func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
 			// charges are removed by every auto or next melee, whether it lands or not
@@ -1582,9 +1609,9 @@ func CreateExtraAttackAuraCommon(character *Character, buffActionID ActionID, au
 				aura.Unit.AutoAttacks.ExtraMHAttackProc(sim, 1, buffActionID, spell)
 			}
 		},
-	}))
+	})
 
-	return apBuffAura
+	return apBuffAura, procAura
 }
 
 func GetWildStrikesAP(aura *Aura, rank int32) float64 {
@@ -1611,6 +1638,14 @@ func ApplyWindfury(character *Character) *Aura {
 
 }
 
+// The Windfury a shaman's totem gives its party, which the totem activates while it is up.
+func WindfuryTotemAura(unit *Unit, rank int32) *Aura {
+	buffActionID := ActionID{SpellID: WindfuryBuffSpellId[rank]}
+	_, procAura := registerExtraAttackAuras(unit, buffActionID, fmt.Sprintf("Windfury Totem (Rank %d)", rank), rank, GetWindfuryAP)
+	procAura.Duration = time.Minute * 2
+	return procAura
+}
+
 ///////////////////////////////////////////////////////////////////////////
 //                            World Buffs
 ///////////////////////////////////////////////////////////////////////////
@@ -1618,11 +1653,11 @@ func ApplyWindfury(character *Character) *Aura {
 func ApplyDragonslayerBuffs(unit *Unit, buffs *proto.IndividualBuffs) {
 	eeCategory := "DragonslayerBuff"
 	if buffs.RallyingCryOfTheDragonslayer {
-		ApplyRallyingCryOfTheDragonslayer(unit, eeCategory)
+		applyWorldBuffDuration(ApplyRallyingCryOfTheDragonslayer(unit, eeCategory), buffs.GetWorldBuffDurations().GetRallyingCryOfTheDragonslayer())
 	}
 }
 
-func ApplyRallyingCryOfTheDragonslayer(unit *Unit, category string) {
+func ApplyRallyingCryOfTheDragonslayer(unit *Unit, category string) *Aura {
 	aura := MakePermanent(unit.RegisterAura(Aura{
 		Label:    "Rallying Cry of the Dragonslayer",
 		ActionID: ActionID{SpellID: 22888},
@@ -1638,15 +1673,14 @@ func ApplyRallyingCryOfTheDragonslayer(unit *Unit, category string) {
 			{stats.RangedAttackPower, 140, false},
 		},
 	})
+
+	return aura
 }
 
-func ApplySpiritOfZandalar(unit *Unit) {
+func ApplySpiritOfZandalar(unit *Unit) *Aura {
 	aura := MakePermanent(unit.RegisterAura(Aura{
 		Label:    "Spirit of Zandalar",
 		ActionID: ActionID{SpellID: 24425},
-		OnInit: func(aura *Aura, sim *Simulation) {
-			unit.AddMoveSpeedModifier(&aura.ActionID, 1.10)
-		},
 	}))
 
 	makeExclusiveBuff(aura, BuffConfig{
@@ -1658,10 +1692,18 @@ func ApplySpiritOfZandalar(unit *Unit) {
 			{stats.Stamina, 1.15, true},
 			{stats.Strength, 1.15, true},
 		},
+		ExtraOnGain: func(aura *Aura, sim *Simulation) {
+			aura.Unit.AddMoveSpeedModifier(&aura.ActionID, 1.10)
+		},
+		ExtraOnExpire: func(aura *Aura, sim *Simulation) {
+			aura.Unit.RemoveMoveSpeedModifier(&aura.ActionID)
+		},
 	})
+
+	return aura
 }
 
-func ApplySongflowerSerenade(unit *Unit) {
+func ApplySongflowerSerenade(unit *Unit) *Aura {
 	aura := MakePermanent(unit.RegisterAura(Aura{
 		Label:    "Songflower Serenade",
 		ActionID: ActionID{SpellID: 15366},
@@ -1680,15 +1722,17 @@ func ApplySongflowerSerenade(unit *Unit) {
 			{stats.SpellCrit, 5, false},
 		},
 	})
+
+	return aura
 }
 
 func ApplyWarchiefsBuffs(unit *Unit, buffs *proto.IndividualBuffs, isAlliance bool, isHorde bool) {
 	if buffs.WarchiefsBlessing /* && isHorde */ {
-		ApplyWarchiefsBlessing(unit, "WarchiefsBuff")
+		applyWorldBuffDuration(ApplyWarchiefsBlessing(unit, "WarchiefsBuff"), buffs.GetWorldBuffDurations().GetWarchiefsBlessing())
 	}
 }
 
-func ApplyWarchiefsBlessing(unit *Unit, category string) {
+func ApplyWarchiefsBlessing(unit *Unit, category string) *Aura {
 	aura := MakePermanent(unit.RegisterAura(Aura{
 		Label:    "Warchief's Blessing",
 		ActionID: ActionID{SpellID: 16609},
@@ -1707,9 +1751,11 @@ func ApplyWarchiefsBlessing(unit *Unit, category string) {
 			aura.Unit.PseudoStats.MeleeSpeedMultiplier /= 1.15
 		},
 	})
+
+	return aura
 }
 
-func ApplyFengusFerocity(unit *Unit) {
+func ApplyFengusFerocity(unit *Unit) *Aura {
 	aura := MakePermanent(unit.RegisterAura(Aura{
 		Label:    "Fengus' Ferocity",
 		ActionID: ActionID{SpellID: 22817},
@@ -1722,9 +1768,11 @@ func ApplyFengusFerocity(unit *Unit) {
 			{stats.RangedAttackPower, 200, false},
 		},
 	})
+
+	return aura
 }
 
-func ApplyMoldarsMoxie(unit *Unit) {
+func ApplyMoldarsMoxie(unit *Unit) *Aura {
 	aura := MakePermanent(unit.RegisterAura(Aura{
 		Label:    "Moldar's Moxie",
 		ActionID: ActionID{SpellID: 22818},
@@ -1736,9 +1784,11 @@ func ApplyMoldarsMoxie(unit *Unit) {
 			{stats.Stamina, 1.15, true},
 		},
 	})
+
+	return aura
 }
 
-func ApplySlipkiksSavvy(unit *Unit) {
+func ApplySlipkiksSavvy(unit *Unit) *Aura {
 	aura := MakePermanent(unit.RegisterAura(Aura{
 		Label:    "Slip'kik's Savvy",
 		ActionID: ActionID{SpellID: 22820},
@@ -1750,9 +1800,11 @@ func ApplySlipkiksSavvy(unit *Unit) {
 			{stats.SpellCrit, 3 * SpellCritRatingPerCritChance, false},
 		},
 	})
+
+	return aura
 }
 
-func ApplySaygesFortunes(character *Character, fortune proto.SaygesFortune) {
+func ApplySaygesFortunes(character *Character, fortune proto.SaygesFortune) *Aura {
 	var label string
 	var spellID int32
 
@@ -1806,6 +1858,8 @@ func ApplySaygesFortunes(character *Character, fortune proto.SaygesFortune) {
 	}))
 
 	makeExclusiveBuff(aura, config)
+
+	return aura
 }
 
 ///////////////////////////////////////////////////////////////////////////
@@ -201,10 +201,15 @@ func (spell *Spell) makeCastFunc(config CastConfig) CastSuccessFunc {
 			return spell.castFailureHelper(sim, "channeling %v for %s, curTime = %s", dot.ActionID, dot.expires-sim.CurrentTime, sim.CurrentTime)
 		}
 
+		// Network latency that isn't hidden by the spell queue window delays both the
+		// cast completion and the next GCD.
+		var latency time.Duration
 		if effectiveTime := spell.CurCast.EffectiveTime(); effectiveTime != 0 {
+			latency = spell.Unit.ActionLatency()
 			if spell.Flags.Matches(SpellFlagCastTimeNoGCD) {
 				effectiveTime = max(effectiveTime, spell.Unit.GCD.TimeToReady(sim))
 			}
+			effectiveTime += latency
 			// do not add channeled time here as they have variable cast length
 			// cast time for channels is handled in dot.OnExpire
 			if !spell.Flags.Matches(SpellFlagChanneled) {
@@ -219,7 +224,7 @@ func (spell *Spell) makeCastFunc(config CastConfig) CastSuccessFunc {
 
 		// Non melee casts
 		if spell.Flags.Matches(SpellFlagResetAttackSwing) && spell.Unit.AutoAttacks.enabled {
-			restartMeleeAt := sim.CurrentTime + spell.CurCast.CastTime
+			restartMeleeAt := sim.CurrentTime + spell.CurCast.CastTime + latency
 			spell.Unit.AutoAttacks.StopMeleeUntil(sim, restartMeleeAt, false)
 		}
 
@@ -231,7 +236,7 @@ func (spell *Spell) makeCastFunc(config CastConfig) CastSuccessFunc {
 			}
 
 			spell.Unit.Hardcast = Hardcast{
-				Expires:  sim.CurrentTime + spell.CurCast.CastTime,
+				Expires:  sim.CurrentTime + spell.CurCast.CastTime + latency,
 				ActionID: spell.ActionID,
 				Pushback: 1.0,
 				OnComplete: func(sim *Simulation, target *Unit) {
@@ -92,6 +92,12 @@ type Character struct {
 	Pets []*Pet // cached in AddPet, for advance()
 
 	ActiveShapeShift *Aura // Some things can't be used in shapeshift forms
+
+	// Only set for players with a pull state, see pull_state.go.
+	pullState *pullStateTracker
+
+	// Only set for players with a trinket schedule, see trinket_scheduler.go.
+	trinketScheduler *trinketScheduler
 }
 
 func NewCharacter(party *Party, partyIndex int, player *proto.Player) Character {
@@ -99,6 +105,8 @@ func NewCharacter(party *Party, partyIndex int, player *proto.Player) Character
 		addToDatabase(player.Database)
 	}
 
+	skill, reactionTime := newPlayerSkill(player)
+
 	character := Character{
 		Unit: Unit{
 			Type:        PlayerUnit,
@@ -110,8 +118,9 @@ func NewCharacter(party *Party, partyIndex int, player *proto.Player) Character
 
 			StatDependencyManager: stats.NewStatDependencyManager(),
 
-			ReactionTime:            max(0, time.Duration(player.ReactionTimeMs)*time.Millisecond),
+			ReactionTime:            reactionTime,
 			ChannelClipDelay:        max(0, time.Duration(player.ChannelClipDelayMs)*time.Millisecond),
+			Skill:                   skill,
 			DistanceFromTarget:      player.DistanceFromTarget,
 			StartDistanceFromTarget: player.DistanceFromTarget,
 		},
@@ -355,7 +364,11 @@ func (character *Character) clearBuildPhaseAuras(phase CharacterBuildPhase) {
 func (character *Character) applyItemEffects(agent Agent) {
 	for slot, eq := range character.Equipment {
 		if applyItemEffect, ok := itemEffects[eq.ID]; ok {
-			applyItemEffect(agent)
+			if character.trinketScheduler != nil && slices.Contains(trinketSlots[:], proto.ItemSlot(slot)) {
+				character.trinketScheduler.applyItemEffect(agent, eq.ID, applyItemEffect)
+			} else {
+				applyItemEffect(agent)
+			}
 		}
 
 		if applyEnchantEffect, ok := enchantEffects[eq.Enchant.EffectID]; ok {
@@ -379,6 +392,10 @@ func (character *Character) applyItemEffects(agent Agent) {
 			}
 		}
 	}
+
+	if character.trinketScheduler != nil {
+		character.trinketScheduler.applyBenchItemEffects(agent)
+	}
 }
 
 func (character *Character) AddPet(pet PetAgent) {
@@ -449,6 +466,13 @@ func (character *Character) Finalize() {
 	character.Unit.finalize()
 
 	character.majorCooldownManager.finalize()
+
+	if character.pullState != nil {
+		character.pullState.finalize()
+	}
+	if character.trinketScheduler != nil {
+		character.trinketScheduler.finalize()
+	}
 }
 
 func (character *Character) FillPlayerStats(playerStats *proto.PlayerStats) {
@@ -480,10 +504,17 @@ func (character *Character) reset(sim *Simulation, agent Agent) {
 	character.Unit.reset(sim, agent)
 	character.majorCooldownManager.reset(sim)
 	character.ItemSwap.reset(sim)
+	if character.trinketScheduler != nil {
+		character.trinketScheduler.reset(sim)
+	}
 	character.CurrentTarget = character.defaultTarget
 
 	agent.Reset(sim)
 
+	if character.pullState != nil {
+		character.pullState.reset(sim)
+	}
+
 	for _, petAgent := range character.PetAgents {
 		petAgent.GetPet().reset(sim, petAgent)
 	}
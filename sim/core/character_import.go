@@ -0,0 +1,268 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/ui"
+	"google.golang.org/protobuf/encoding/protojson"
+	googleProto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const defaultImportIterations = 3000
+
+// Spec used for characters imported without a spec of their class.
+var defaultClassSpecs = map[proto.Class]proto.Spec{
+	proto.Class_ClassDruid:   proto.Spec_SpecBalanceDruid,
+	proto.Class_ClassHunter:  proto.Spec_SpecHunter,
+	proto.Class_ClassMage:    proto.Spec_SpecMage,
+	proto.Class_ClassPaladin: proto.Spec_SpecRetributionPaladin,
+	proto.Class_ClassPriest:  proto.Spec_SpecShadowPriest,
+	proto.Class_ClassRogue:   proto.Spec_SpecRogue,
+	proto.Class_ClassShaman:  proto.Spec_SpecEnhancementShaman,
+	proto.Class_ClassWarlock: proto.Spec_SpecWarlock,
+	proto.Class_ClassWarrior: proto.Spec_SpecWarrior,
+}
+
+// Default APL preset of each spec, from its presets.ts in the UI. Specs without one,
+// like healers, are imported without a rotation.
+var defaultSpecAPLs = map[proto.Spec]string{
+	proto.Spec_SpecBalanceDruid:       "balance_druid/apls/p1.apl.json",
+	proto.Spec_SpecFeralDruid:         "feral_druid/apls/p1.apl.json",
+	proto.Spec_SpecFeralTankDruid:     "feral_tank_druid/apls/default.apl.json",
+	proto.Spec_SpecElementalShaman:    "elemental_shaman/apls/phase_5.apl.json",
+	proto.Spec_SpecEnhancementShaman:  "enhancement_shaman/apls/phase_5.apl.json",
+	proto.Spec_SpecWardenShaman:       "warden_shaman/apls/phase_4_enh_tank.apl.json",
+	proto.Spec_SpecHunter:             "hunter/apls/p1.apl.json",
+	proto.Spec_SpecMage:               "mage/apls/p1.apl.json",
+	proto.Spec_SpecProtectionPaladin:  "protection_paladin/apls/p4prot.apl.json",
+	proto.Spec_SpecRetributionPaladin: "retribution_paladin/apls/p5ret-twist-4DR-3.5-3.6.apl.json",
+	proto.Spec_SpecRogue:              "rogue/apls/combat_sinister_strike.apl.json",
+	proto.Spec_SpecShadowPriest:       "shadow_priest/apls/p1.apl.json",
+	proto.Spec_SpecWarlock:            "warlock/apls/rotation.apl.json",
+	proto.Spec_SpecWarrior:            "warrior/apls/p1.apl.json",
+	proto.Spec_SpecTankWarrior:        "tank_warrior/apls/p1.apl.json",
+}
+
+// Names the addon uses which differ from the proto enum names.
+var addonRaceAliases = map[string]proto.Race{
+	"scourge": proto.Race_RaceUndead,
+}
+
+// The JSON written by the WoWSims exporter addon.
+type addonExport struct {
+	Name        string `json:"name"`
+	Class       string `json:"class"`
+	Race        string `json:"race"`
+	Talents     string `json:"talents"`
+	Professions []struct {
+		Name string `json:"name"`
+	} `json:"professions"`
+	Gear struct {
+		Items []json.RawMessage `json:"items"`
+	} `json:"gear"`
+}
+
+// Imports a character from the addon export into a RaidSimRequest. Items, enchants and
+// random suffixes missing from the sim database are left out and reported.
+func ImportAddonCharacter(request *proto.CharacterImportRequest) *proto.CharacterImportResult {
+	export := &addonExport{}
+	if err := json.Unmarshal([]byte(request.AddonExport), export); err != nil {
+		return &proto.CharacterImportResult{Error: &proto.ErrorOutcome{Message: fmt.Sprintf("invalid addon export: %s", err)}}
+	}
+
+	class, ok := parseEnumName(proto.Class_value, "Class", export.Class)
+	if !ok || class == int32(proto.Class_ClassUnknown) {
+		return &proto.CharacterImportResult{Error: &proto.ErrorOutcome{Message: fmt.Sprintf("unknown class %q", export.Class)}}
+	}
+	race, ok := addonRaceAliases[strings.ToLower(export.Race)]
+	if !ok {
+		raceValue, found := parseEnumName(proto.Race_value, "Race", export.Race)
+		if !found || raceValue == int32(proto.Race_RaceUnknown) {
+			return &proto.CharacterImportResult{Error: &proto.ErrorOutcome{Message: fmt.Sprintf("unknown race %q", export.Race)}}
+		}
+		race = proto.Race(raceValue)
+	}
+
+	result := &proto.CharacterImportResult{}
+	player := &proto.Player{
+		Name:          export.Name,
+		Race:          race,
+		Class:         proto.Class(class),
+		TalentsString: export.Talents,
+		Equipment:     &proto.EquipmentSpec{},
+		Consumes:      &proto.Consumes{},
+		Buffs:         &proto.IndividualBuffs{},
+	}
+	if player.Name == "" {
+		player.Name = "Player"
+	}
+
+	var professions []proto.Profession
+	for _, profession := range export.Professions {
+		value, ok := parseEnumName(proto.Profession_value, "", profession.Name)
+		if !ok {
+			result.UnknownProfessions = append(result.UnknownProfessions, profession.Name)
+			continue
+		}
+		professions = append(professions, proto.Profession(value))
+	}
+	if len(professions) > 0 {
+		player.Profession1 = professions[0]
+	}
+	if len(professions) > 1 {
+		player.Profession2 = professions[1]
+	}
+
+	// Items are indexed by slot, so empty and unknown slots stay as empty items.
+	for _, rawItem := range export.Gear.Items {
+		item := &proto.ItemSpec{}
+		player.Equipment.Items = append(player.Equipment.Items, item)
+		if string(rawItem) == "null" {
+			continue
+		}
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(rawItem, item); err != nil {
+			return &proto.CharacterImportResult{Error: &proto.ErrorOutcome{Message: fmt.Sprintf("invalid item %s: %s", rawItem, err)}}
+		}
+		if item.Id == 0 {
+			continue
+		}
+		if _, ok := ItemsByID[item.Id]; !ok {
+			result.UnknownItems = append(result.UnknownItems, item.Id)
+			item.Reset()
+			continue
+		}
+		if _, ok := EnchantsByEffectID[item.Enchant]; item.Enchant != 0 && !ok {
+			result.UnknownEnchants = append(result.UnknownEnchants, item.Enchant)
+			item.Enchant = 0
+		}
+		if _, ok := RandomSuffixesByID[item.RandomSuffix]; item.RandomSuffix != 0 && !ok {
+			result.UnknownRandomSuffixes = append(result.UnknownRandomSuffixes, item.RandomSuffix)
+			item.RandomSuffix = 0
+		}
+	}
+
+	spec := request.Spec
+	if !specMatchesClass(spec, player.Class) {
+		spec = defaultClassSpecs[player.Class]
+	}
+	if err := setEmptySpec(player, spec); err != nil {
+		return &proto.CharacterImportResult{Error: &proto.ErrorOutcome{Message: err.Error()}}
+	}
+	// Rotations aren't part of the export.
+	rotation, err := defaultSpecRotation(spec)
+	if err != nil {
+		return &proto.CharacterImportResult{Error: &proto.ErrorOutcome{Message: err.Error()}}
+	}
+	player.Rotation = rotation
+
+	rsr := &proto.RaidSimRequest{
+		Raid:       SinglePlayerRaidProto(player, &proto.PartyBuffs{}, &proto.RaidBuffs{}, &proto.Debuffs{}),
+		Encounter:  MakeSingleTargetEncounter(5),
+		SimOptions: &proto.SimOptions{Iterations: defaultImportIterations},
+	}
+	if request.Template != nil {
+		rsr = googleProto.Clone(request.Template).(*proto.RaidSimRequest)
+		if err := importIntoTemplate(rsr, player); err != nil {
+			return &proto.CharacterImportResult{Error: &proto.ErrorOutcome{Message: err.Error()}}
+		}
+	}
+	result.Request = rsr
+	result.MissingRotation = len(rsr.Raid.Parties[0].Players[0].Rotation.GetPriorityList()) == 0
+
+	return result
+}
+
+// Returns the spec's default APL preset, or an empty APL for specs without one.
+func defaultSpecRotation(spec proto.Spec) (*proto.APLRotation, error) {
+	path, ok := defaultSpecAPLs[spec]
+	if !ok {
+		return &proto.APLRotation{Type: proto.APLRotation_TypeAPL}, nil
+	}
+
+	data, err := ui.APLPresets.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the default APL of %s: %s", spec, err)
+	}
+	rotation := &proto.APLRotation{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, rotation); err != nil {
+		return nil, fmt.Errorf("invalid default APL of %s: %s", spec, err)
+	}
+	return rotation, nil
+}
+
+// Replaces the character fields of the template's first player, keeping its buffs,
+// consumes and rotation when it has one for the spec.
+func importIntoTemplate(rsr *proto.RaidSimRequest, player *proto.Player) error {
+	if rsr.Raid == nil || len(rsr.Raid.Parties) == 0 || len(rsr.Raid.Parties[0].Players) == 0 {
+		return fmt.Errorf("template has no player to import into")
+	}
+
+	templatePlayer := rsr.Raid.Parties[0].Players[0]
+	sameSpec := reflect.TypeOf(templatePlayer.Spec) == reflect.TypeOf(player.Spec)
+	if sameSpec {
+		// Keep the template's spec options.
+		player.Spec = templatePlayer.Spec
+	}
+	if !sameSpec || len(templatePlayer.Rotation.GetPriorityList()) == 0 {
+		// The template's rotation is for another spec, or missing.
+		templatePlayer.Rotation = player.Rotation
+	}
+
+	templatePlayer.Name = player.Name
+	templatePlayer.Race = player.Race
+	templatePlayer.Class = player.Class
+	templatePlayer.TalentsString = player.TalentsString
+	templatePlayer.Profession1 = player.Profession1
+	templatePlayer.Profession2 = player.Profession2
+	templatePlayer.Equipment = player.Equipment
+	templatePlayer.Spec = player.Spec
+	return nil
+}
+
+// Returns the enum value whose name, without the prefix, matches the given name
+// ignoring case and spaces.
+func parseEnumName(values map[string]int32, prefix string, name string) (int32, bool) {
+	normalized := strings.ToLower(strings.ReplaceAll(name, " ", ""))
+	for valueName, value := range values {
+		if strings.ToLower(strings.TrimPrefix(valueName, prefix)) == normalized {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+// Spec enum names end with their class name, e.g. SpecFeralTankDruid.
+func specMatchesClass(spec proto.Spec, class proto.Class) bool {
+	return strings.HasSuffix(spec.String(), strings.TrimPrefix(class.String(), "Class"))
+}
+
+// Sets the player's spec to empty options for the given spec.
+func setEmptySpec(player *proto.Player, spec proto.Spec) error {
+	playerMsg := player.ProtoReflect()
+	oneof := playerMsg.Descriptor().Oneofs().ByName("spec")
+	specName := strings.TrimPrefix(spec.String(), "Spec")
+
+	for i := 0; i < oneof.Fields().Len(); i++ {
+		field := oneof.Fields().Get(i)
+		if string(field.Message().Name()) != specName {
+			continue
+		}
+
+		specMsg := playerMsg.NewField(field).Message()
+		// Class code reads the options without nil checks.
+		fields := specMsg.Descriptor().Fields()
+		for j := 0; j < fields.Len(); j++ {
+			if optionsField := fields.Get(j); optionsField.Kind() == protoreflect.MessageKind && !optionsField.IsList() && !optionsField.IsMap() {
+				specMsg.Set(optionsField, specMsg.NewField(optionsField))
+			}
+		}
+		playerMsg.Set(field, protoreflect.ValueOfMessage(specMsg))
+		return nil
+	}
+	return fmt.Errorf("no player options for spec %s", spec)
+}
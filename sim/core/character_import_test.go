@@ -0,0 +1,121 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+const testAddonExport = `{
+	"name": "Tester",
+	"class": "Warrior",
+	"race": "Scourge",
+	"talents": "30305001302-05050005525010051",
+	"professions": [{"name": "Engineering", "level": 300}, {"name": "Basket Weaving", "level": 1}, {"name": "Mining", "level": 300}],
+	"gear": {"items": [{"id": 45620, "enchant": 424242}, null, {"id": 99999999}, {"id": 46350, "randomSuffix": 999999}]}
+}`
+
+func TestImportAddonCharacter(t *testing.T) {
+	addToDatabase(tinyItemDatabase)
+
+	result := ImportAddonCharacter(&proto.CharacterImportRequest{AddonExport: testAddonExport})
+	if result.Error != nil {
+		t.Fatalf("Import failed: %s", result.Error.Message)
+	}
+
+	player := result.Request.Raid.Parties[0].Players[0]
+	if player.Class != proto.Class_ClassWarrior || player.Race != proto.Race_RaceUndead {
+		t.Fatalf("Unexpected class %s and race %s", player.Class, player.Race)
+	}
+	if player.Profession1 != proto.Profession_Engineering || player.Profession2 != proto.Profession_Mining {
+		t.Fatalf("Unexpected professions %s and %s", player.Profession1, player.Profession2)
+	}
+	if player.GetWarrior() == nil || player.GetWarrior().Options == nil {
+		t.Fatalf("Expected default warrior spec options, got %v", player.Spec)
+	}
+
+	items := player.Equipment.Items
+	if len(items) != 4 || items[0].Id != itemStarshardEdge || items[1].Id != 0 || items[2].Id != 0 || items[3].Id != itemPillarOfFortitude {
+		t.Fatalf("Items weren't kept in their slots: %v", items)
+	}
+	if items[0].Enchant != 0 || items[3].RandomSuffix != 0 {
+		t.Fatalf("Unknown enchants and random suffixes weren't removed: %v", items)
+	}
+	if len(result.UnknownItems) != 1 || result.UnknownItems[0] != 99999999 ||
+		len(result.UnknownEnchants) != 1 || result.UnknownEnchants[0] != 424242 ||
+		len(result.UnknownRandomSuffixes) != 1 || result.UnknownRandomSuffixes[0] != 999999 {
+		t.Fatalf("Unexpected unknown items %v, enchants %v and random suffixes %v", result.UnknownItems, result.UnknownEnchants, result.UnknownRandomSuffixes)
+	}
+	if len(result.UnknownProfessions) != 1 || result.UnknownProfessions[0] != "Basket Weaving" {
+		t.Fatalf("Unexpected unknown professions %v", result.UnknownProfessions)
+	}
+	if result.MissingRotation || len(player.Rotation.GetPriorityList()) == 0 {
+		t.Fatalf("Expected the warrior's default APL preset, got %v", player.Rotation)
+	}
+
+	// Healers have no default preset.
+	result = ImportAddonCharacter(&proto.CharacterImportRequest{
+		AddonExport: `{"class": "Priest", "race": "Human"}`,
+		Spec:        proto.Spec_SpecHealingPriest,
+	})
+	if result.Error != nil {
+		t.Fatalf("Import failed: %s", result.Error.Message)
+	}
+	if !result.MissingRotation {
+		t.Fatalf("Expected the healer's empty rotation to be reported")
+	}
+}
+
+func TestDefaultSpecRotations(t *testing.T) {
+	for spec := range defaultSpecAPLs {
+		rotation, err := defaultSpecRotation(spec)
+		if err != nil {
+			t.Fatalf("%s", err)
+		}
+		if len(rotation.PriorityList) == 0 {
+			t.Errorf("Default APL of %s is empty", spec)
+		}
+	}
+}
+
+func TestImportAddonCharacterIntoTemplate(t *testing.T) {
+	template := &proto.RaidSimRequest{
+		Raid: SinglePlayerRaidProto(&proto.Player{
+			Class:    proto.Class_ClassWarrior,
+			Consumes: &proto.Consumes{Flask: proto.Flask_FlaskOfTheTitans},
+			Spec:     &proto.Player_Warrior{Warrior: &proto.Warrior{Options: &proto.Warrior_Options{StartingRage: 50}}},
+			Rotation: &proto.APLRotation{Type: proto.APLRotation_TypeAPL, PriorityList: []*proto.APLListItem{{}}},
+		}, &proto.PartyBuffs{}, &proto.RaidBuffs{}, &proto.Debuffs{}),
+		Encounter: MakeSingleTargetEncounter(0),
+	}
+
+	result := ImportAddonCharacter(&proto.CharacterImportRequest{AddonExport: testAddonExport, Template: template})
+	if result.Error != nil {
+		t.Fatalf("Import failed: %s", result.Error.Message)
+	}
+
+	player := result.Request.Raid.Parties[0].Players[0]
+	if player.Name != "Tester" || player.Consumes.Flask != proto.Flask_FlaskOfTheTitans {
+		t.Fatalf("Character wasn't merged into the template: %v", player)
+	}
+	if player.GetWarrior().Options.StartingRage != 50 || len(player.Rotation.PriorityList) != 1 {
+		t.Fatalf("Template spec options and rotation weren't kept: %v", player)
+	}
+	if result.MissingRotation {
+		t.Fatalf("The template's rotation was reported as missing")
+	}
+	if template.Raid.Parties[0].Players[0].Name != "" {
+		t.Fatalf("Template was modified")
+	}
+
+	template.Raid.Parties[0].Players[0].Rotation = &proto.APLRotation{Type: proto.APLRotation_TypeAPL}
+	result = ImportAddonCharacter(&proto.CharacterImportRequest{AddonExport: testAddonExport, Template: template})
+	if result.MissingRotation {
+		t.Fatalf("Expected the default APL preset for a template without a rotation")
+	}
+
+	result = ImportAddonCharacter(&proto.CharacterImportRequest{AddonExport: `{"class": "Deathknight", "race": "Human"}`})
+	if result.Error == nil {
+		t.Fatalf("Expected an error for an unknown class")
+	}
+}
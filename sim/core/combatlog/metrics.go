@@ -0,0 +1,349 @@
+package combatlog
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+const (
+	tagMainhand = 1
+	tagOffhand  = 2
+
+	schoolPhysical = 1
+)
+
+// Combat log power types, as used by SPELL_ENERGIZE.
+var resourceTypes = map[int64]proto.ResourceType{
+	0: proto.ResourceType_ResourceTypeMana,
+	1: proto.ResourceType_ResourceTypeRage,
+	2: proto.ResourceType_ResourceTypeFocus,
+	3: proto.ResourceType_ResourceTypeEnergy,
+	4: proto.ResourceType_ResourceTypeComboPoints,
+}
+
+// Returns whether the unit is the player with the given name. Names are matched with or
+// without the realm.
+func (unit Unit) IsPlayer(name string) bool {
+	if strings.EqualFold(unit.Name, name) {
+		return true
+	}
+	unitName, _, _ := strings.Cut(unit.Name, "-")
+	return strings.EqualFold(unitName, name) && strings.HasPrefix(unit.GUID, "Player-")
+}
+
+// Extracts a player's metrics from an encounter of the log, or from the whole log if
+// encounter is nil.
+func (log *Log) PlayerMetrics(playerName string, encounter *Encounter) (*proto.CombatLogMetrics, error) {
+	events, start, end := log.Segment(encounter)
+	duration := end - start
+	if duration <= 0 {
+		return nil, fmt.Errorf("log segment has no duration")
+	}
+
+	builder := &metricsBuilder{
+		player:  &proto.UnitMetrics{Name: playerName},
+		actions: make(map[actionKey]*proto.ActionMetrics),
+		targets: make(map[string]int32),
+		auras:   make(map[int32]*auraTracker),
+	}
+
+	found := false
+	for _, event := range events {
+		if event.Source.IsPlayer(playerName) {
+			found = true
+			builder.addSourceEvent(event)
+		}
+		if event.Dest.IsPlayer(playerName) {
+			found = true
+			builder.addDestEvent(event, start)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no events for player %q", playerName)
+	}
+
+	builder.finalize(start, end)
+
+	result := &proto.CombatLogMetrics{
+		DurationSeconds: duration.Seconds(),
+		Player:          builder.player,
+	}
+	if encounter != nil {
+		result.EncounterName = encounter.Name
+		result.Kill = encounter.Kill
+	}
+	return result, nil
+}
+
+type actionKey struct {
+	spellID int32
+	tag     int32
+}
+
+type auraTracker struct {
+	metrics *proto.AuraMetrics
+	gainAt  time.Duration
+	active  bool
+
+	// Whether the aura was applied or removed during the segment. Auras first seen
+	// being removed were applied before the segment started.
+	seen bool
+}
+
+type metricsBuilder struct {
+	player *proto.UnitMetrics
+
+	actions     map[actionKey]*proto.ActionMetrics
+	actionOrder []actionKey
+
+	// Unit index of each target, by GUID, in order of first appearance.
+	targets       map[string]int32
+	currentTarget string
+
+	auras     map[int32]*auraTracker
+	auraOrder []int32
+
+	resources map[actionKey]*proto.ResourceMetrics
+
+	damage  float64
+	healing float64
+}
+
+func (builder *metricsBuilder) actionFor(event *Event) *proto.ActionMetrics {
+	key := actionKey{spellID: event.SpellID}
+	if event.IsSwing() && event.BoolParam(swingOffhandParam(event)) {
+		key.tag = tagOffhand
+	} else if event.IsSwing() {
+		key.tag = tagMainhand
+	}
+
+	action, ok := builder.actions[key]
+	if !ok {
+		action = &proto.ActionMetrics{
+			IsMelee:     event.IsSwing() || strings.HasPrefix(event.Type, "RANGE_") || event.SpellSchool == schoolPhysical,
+			SpellSchool: event.SpellSchool,
+		}
+		if event.IsSwing() {
+			action.Id = &proto.ActionID{RawId: &proto.ActionID_OtherId{OtherId: proto.OtherAction_OtherActionAttack}, Tag: key.tag}
+			action.SpellSchool = schoolPhysical
+		} else {
+			action.Id = &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: event.SpellID}}
+		}
+		builder.actions[key] = action
+		builder.actionOrder = append(builder.actionOrder, key)
+	}
+	return action
+}
+
+// Returns the metrics of an action against the event's target.
+func (builder *metricsBuilder) targetFor(action *proto.ActionMetrics, event *Event) *proto.TargetedActionMetrics {
+	guid := event.Dest.GUID
+	if event.Dest.IsEmpty() {
+		guid = builder.currentTarget
+	}
+	unitIndex, ok := builder.targets[guid]
+	if !ok {
+		unitIndex = int32(len(builder.targets))
+		builder.targets[guid] = unitIndex
+	}
+
+	for _, target := range action.Targets {
+		if target.UnitIndex == unitIndex {
+			return target
+		}
+	}
+	target := &proto.TargetedActionMetrics{UnitIndex: unitIndex}
+	action.Targets = append(action.Targets, target)
+	return target
+}
+
+// The isOffHand parameter follows the 9 damage parameters and the 3 miss parameters.
+func swingOffhandParam(event *Event) int {
+	if strings.HasSuffix(event.Type, "_MISSED") {
+		return 1
+	}
+	return 9
+}
+
+func (builder *metricsBuilder) addSourceEvent(event *Event) {
+	isHostile := !event.Dest.IsEmpty() && !event.Dest.IsPlayer(builder.player.Name) && !strings.HasPrefix(event.Dest.GUID, "Player-")
+
+	switch {
+	case strings.HasSuffix(event.Type, "_CAST_SUCCESS"):
+		action := builder.actionFor(event)
+		builder.targetFor(action, event).Casts++
+
+	case strings.HasSuffix(event.Type, "_DAMAGE"):
+		if !isHostile {
+			// Damage to the player or other players, e.g. from Hellfire.
+			return
+		}
+		builder.currentTarget = event.Dest.GUID
+		action := builder.actionFor(event)
+		target := builder.targetFor(action, event)
+		amount := event.FloatParam(0)
+		resisted := event.FloatParam(3)
+		blocked := event.FloatParam(4)
+		crit := event.BoolParam(6)
+
+		builder.damage += amount
+		target.Damage += amount
+		target.ResistedDamageLost += resisted
+
+		if event.IsPeriodic() {
+			target.Ticks++
+			target.TickDamage += amount
+			if crit {
+				target.CritTicks++
+				target.CritTickDamage += amount
+			}
+			return
+		}
+
+		if event.IsSwing() || strings.HasPrefix(event.Type, "RANGE_") {
+			target.Casts++
+		}
+		switch {
+		case crit && blocked > 0:
+			target.BlockedCrits++
+			target.BlockedCritDamage += amount
+		case crit:
+			target.Crits++
+			target.CritDamage += amount
+		case event.BoolParam(7):
+			target.Glances++
+			target.GlanceDamage += amount
+		case event.BoolParam(8):
+			target.Crushes++
+			target.CrushDamage += amount
+		case blocked > 0:
+			target.Blocks++
+			target.BlockDamage += amount
+		default:
+			target.Hits++
+		}
+
+	case strings.HasSuffix(event.Type, "_MISSED"):
+		action := builder.actionFor(event)
+		target := builder.targetFor(action, event)
+		if event.IsSwing() || strings.HasPrefix(event.Type, "RANGE_") {
+			target.Casts++
+		}
+		switch event.Param(0) {
+		case "DODGE":
+			target.Dodges++
+		case "PARRY":
+			target.Parries++
+		case "BLOCK":
+			target.Blocks++
+		case "ABSORB":
+			target.Hits++
+		default:
+			target.Misses++
+		}
+
+	case strings.HasSuffix(event.Type, "_HEAL"):
+		action := builder.actionFor(event)
+		target := builder.targetFor(action, event)
+		healing := event.FloatParam(0) - event.FloatParam(1)
+		builder.healing += healing
+		target.Healing += healing
+		if event.IsPeriodic() {
+			target.Ticks++
+		} else if event.BoolParam(3) {
+			target.Crits++
+			target.CritHealing += healing
+		} else {
+			target.Hits++
+		}
+	}
+}
+
+func (builder *metricsBuilder) addDestEvent(event *Event, start time.Duration) {
+	switch event.Type {
+	case "SPELL_AURA_APPLIED", "SPELL_AURA_REFRESH", "SPELL_AURA_APPLIED_DOSE":
+		aura := builder.auraFor(event.SpellID)
+		if event.Type == "SPELL_AURA_APPLIED" || !aura.active {
+			aura.metrics.ProcsAvg++
+		}
+		if !aura.active {
+			aura.active = true
+			aura.gainAt = event.Time
+		}
+		aura.seen = true
+	case "SPELL_AURA_REMOVED":
+		aura := builder.auraFor(event.SpellID)
+		if !aura.active && !aura.seen {
+			// Applied before the segment started.
+			aura.active = true
+			aura.gainAt = start
+		}
+		if aura.active {
+			aura.metrics.UptimeSecondsAvg += (event.Time - aura.gainAt).Seconds()
+			aura.active = false
+		}
+		aura.seen = true
+	case "SPELL_ENERGIZE", "SPELL_PERIODIC_ENERGIZE":
+		resourceType, ok := resourceTypes[event.IntParam(2)]
+		if !ok {
+			return
+		}
+		if builder.resources == nil {
+			builder.resources = make(map[actionKey]*proto.ResourceMetrics)
+		}
+		key := actionKey{spellID: event.SpellID, tag: int32(resourceType)}
+		resource, ok := builder.resources[key]
+		if !ok {
+			resource = &proto.ResourceMetrics{
+				Id:   &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: event.SpellID}},
+				Type: resourceType,
+			}
+			builder.resources[key] = resource
+			builder.player.Resources = append(builder.player.Resources, resource)
+		}
+		resource.Events++
+		resource.Gain += event.FloatParam(0) + event.FloatParam(1)
+		resource.ActualGain += event.FloatParam(0)
+	}
+}
+
+func (builder *metricsBuilder) auraFor(spellID int32) *auraTracker {
+	aura, ok := builder.auras[spellID]
+	if !ok {
+		aura = &auraTracker{
+			metrics: &proto.AuraMetrics{Id: &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: spellID}}},
+		}
+		builder.auras[spellID] = aura
+		builder.auraOrder = append(builder.auraOrder, spellID)
+	}
+	return aura
+}
+
+func (builder *metricsBuilder) finalize(start, end time.Duration) {
+	seconds := (end - start).Seconds()
+	builder.player.Dps = &proto.DistributionMetrics{Avg: builder.damage / seconds, Min: builder.damage / seconds, Max: builder.damage / seconds}
+	builder.player.Hps = &proto.DistributionMetrics{Avg: builder.healing / seconds, Min: builder.healing / seconds, Max: builder.healing / seconds}
+
+	for _, key := range builder.actionOrder {
+		action := builder.actions[key]
+		// Procs don't have casts in the log, count each of their direct hits instead.
+		if !slices.ContainsFunc(action.Targets, func(target *proto.TargetedActionMetrics) bool { return target.Casts > 0 }) {
+			for _, target := range action.Targets {
+				target.Casts = target.Hits + target.Crits + target.Misses + target.Dodges + target.Parries + target.Blocks + target.BlockedCrits + target.Glances + target.Crushes
+			}
+		}
+		builder.player.Actions = append(builder.player.Actions, action)
+	}
+
+	for _, spellID := range builder.auraOrder {
+		aura := builder.auras[spellID]
+		if aura.active {
+			aura.metrics.UptimeSecondsAvg += (end - aura.gainAt).Seconds()
+		}
+		builder.player.Auras = append(builder.player.Auras, aura.metrics)
+	}
+}
@@ -0,0 +1,347 @@
+// Package combatlog parses WoWCombatLog.txt files written by the Classic client, so
+// recorded fights can be compared with sims of the same gear and encounter.
+package combatlog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Number of advanced logging parameters (unit GUID, owner, health, power, position, ...)
+// some events carry between their prefix and suffix parameters.
+const numAdvancedParams = 17
+
+const emptyGUID = "0000000000000000"
+
+// A unit referenced by an event.
+type Unit struct {
+	GUID  string
+	Name  string
+	Flags int64
+}
+
+func (unit Unit) IsEmpty() bool {
+	return unit.GUID == "" || unit.GUID == emptyGUID
+}
+
+// A single parsed combat log event.
+type Event struct {
+	// Time since the start of the log.
+	Time time.Duration
+	Type string
+
+	Source Unit
+	Dest   Unit
+
+	// Set for SPELL_*, SPELL_PERIODIC_* and RANGE_* events.
+	SpellID     int32
+	SpellName   string
+	SpellSchool int32
+
+	// Suffix parameters, with the advanced logging parameters removed.
+	Params []string
+
+	// Advanced logging parameters of the unit performing the event, if logged.
+	Advanced []string
+}
+
+// Whether this is a melee or ranged auto attack event.
+func (event *Event) IsSwing() bool {
+	return strings.HasPrefix(event.Type, "SWING_")
+}
+
+func (event *Event) IsPeriodic() bool {
+	return strings.HasPrefix(event.Type, "SPELL_PERIODIC_")
+}
+
+// Returns the suffix parameter at the given index, or "" if it is missing.
+func (event *Event) Param(idx int) string {
+	if idx < len(event.Params) {
+		return event.Params[idx]
+	}
+	return ""
+}
+
+func (event *Event) IntParam(idx int) int64 {
+	value, _ := strconv.ParseInt(event.Param(idx), 0, 64)
+	return value
+}
+
+func (event *Event) FloatParam(idx int) float64 {
+	value, _ := strconv.ParseFloat(event.Param(idx), 64)
+	return value
+}
+
+func (event *Event) BoolParam(idx int) bool {
+	return event.Param(idx) == "1"
+}
+
+// Indices of the power fields in the advanced logging parameters.
+const (
+	advancedPowerType    = 8
+	advancedCurrentPower = 9
+	advancedMaxPower     = 10
+	advancedPowerCost    = 11
+)
+
+// Returns the power type, power before the event's cost, maximum power and cost of
+// the unit performing the event. ok is false if the event has no advanced parameters.
+func (event *Event) Power() (powerType int64, power float64, maxPower float64, cost float64, ok bool) {
+	if len(event.Advanced) != numAdvancedParams {
+		return 0, 0, 0, 0, false
+	}
+	powerType = parseInt(event.Advanced[advancedPowerType])
+	power, _ = strconv.ParseFloat(event.Advanced[advancedCurrentPower], 64)
+	maxPower, _ = strconv.ParseFloat(event.Advanced[advancedMaxPower], 64)
+	cost, _ = strconv.ParseFloat(event.Advanced[advancedPowerCost], 64)
+	return powerType, power + cost, maxPower, cost, true
+}
+
+// An ENCOUNTER_START/ENCOUNTER_END pair.
+type Encounter struct {
+	ID    int32
+	Name  string
+	Kill  bool
+	Start time.Duration
+	End   time.Duration
+
+	// Index of the first and one past the last event of the encounter.
+	StartIdx int
+	EndIdx   int
+}
+
+func (encounter *Encounter) Duration() time.Duration {
+	return encounter.End - encounter.Start
+}
+
+type Log struct {
+	Events     []*Event
+	Encounters []*Encounter
+}
+
+// Parses a whole combat log.
+func Parse(reader io.Reader) (*Log, error) {
+	log := &Log{}
+	var start time.Time
+	var openEncounter *Encounter
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		timestamp, fields, err := splitLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		if start.IsZero() {
+			start = timestamp
+		}
+		now := timestamp.Sub(start)
+
+		switch fields[0] {
+		case "COMBAT_LOG_VERSION", "ZONE_CHANGE", "MAP_CHANGE", "COMBATANT_INFO":
+			continue
+		case "ENCOUNTER_START":
+			openEncounter = &Encounter{
+				ID:       int32(parseInt(field(fields, 1))),
+				Name:     field(fields, 2),
+				Start:    now,
+				StartIdx: len(log.Events),
+			}
+			continue
+		case "ENCOUNTER_END":
+			if openEncounter != nil {
+				openEncounter.Kill = field(fields, 5) == "1"
+				openEncounter.End = now
+				openEncounter.EndIdx = len(log.Events)
+				log.Encounters = append(log.Encounters, openEncounter)
+				openEncounter = nil
+			}
+			continue
+		}
+
+		event, err := newEvent(now, fields)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		if event != nil {
+			log.Events = append(log.Events, event)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// Returns the first encounter whose name matches, or the encounter at the given
+// 1-based index if the name is a number.
+func (log *Log) FindEncounter(nameOrIndex string) (*Encounter, error) {
+	if idx, err := strconv.Atoi(nameOrIndex); err == nil {
+		if idx < 1 || idx > len(log.Encounters) {
+			return nil, fmt.Errorf("log has %d encounters, no encounter #%d", len(log.Encounters), idx)
+		}
+		return log.Encounters[idx-1], nil
+	}
+	for _, encounter := range log.Encounters {
+		if strings.EqualFold(encounter.Name, nameOrIndex) {
+			return encounter, nil
+		}
+	}
+	return nil, fmt.Errorf("no encounter named %q in log", nameOrIndex)
+}
+
+// Returns the events of an encounter, or of the whole log if encounter is nil.
+func (log *Log) Segment(encounter *Encounter) (events []*Event, start time.Duration, end time.Duration) {
+	if encounter != nil {
+		return log.Events[encounter.StartIdx:encounter.EndIdx], encounter.Start, encounter.End
+	}
+	if len(log.Events) == 0 {
+		return nil, 0, 0
+	}
+	return log.Events, log.Events[0].Time, log.Events[len(log.Events)-1].Time
+}
+
+// Splits a line into its timestamp and comma separated fields. Lines look like:
+//
+//	10/19 20:31:12.345  SPELL_DAMAGE,Player-4395-0A1B2C3D,"Name-Realm",0x511,...
+//
+// newer clients also write the year and timezone: 10/19/2024 20:31:12.345-4.
+func splitLine(line string) (time.Time, []string, error) {
+	sep := strings.Index(line, "  ")
+	if sep == -1 {
+		return time.Time{}, nil, fmt.Errorf("missing timestamp")
+	}
+	timestamp, err := parseTimestamp(line[:sep])
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	return timestamp, splitFields(strings.TrimSpace(line[sep:])), nil
+}
+
+func parseTimestamp(str string) (time.Time, error) {
+	dateStr, timeStr, ok := strings.Cut(strings.TrimSpace(str), " ")
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", str)
+	}
+
+	date := strings.Split(dateStr, "/")
+	if len(date) == 2 {
+		// 2000 is a leap year, so Feb 29 parses.
+		date = append(date, "2000")
+	}
+	if len(date) != 3 {
+		return time.Time{}, fmt.Errorf("invalid date %q", dateStr)
+	}
+
+	// Drop the timezone offset, all lines of a log share it.
+	if idx := strings.IndexAny(timeStr, "+-"); idx != -1 {
+		timeStr = timeStr[:idx]
+	}
+
+	timestamp, err := time.Parse("1/2/2006 15:04:05.000", fmt.Sprintf("%s/%s/%s %s", date[0], date[1], date[2], timeStr))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: %w", str, err)
+	}
+	return timestamp, nil
+}
+
+// Splits comma separated fields, keeping commas inside quotes and removing the quotes.
+func splitFields(str string) []string {
+	var fields []string
+	var current strings.Builder
+	inQuotes := false
+	for _, r := range str {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == ',' && !inQuotes:
+			fields = append(fields, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(fields, current.String())
+}
+
+func field(fields []string, idx int) string {
+	if idx < len(fields) {
+		return fields[idx]
+	}
+	return ""
+}
+
+func parseInt(str string) int64 {
+	value, _ := strconv.ParseInt(str, 0, 64)
+	return value
+}
+
+// Minimum number of suffix parameters, for suffixes that can follow advanced logging
+// parameters.
+var advancedSuffixParams = map[string]int{
+	"_DAMAGE":       9,
+	"_HEAL":         4,
+	"_ENERGIZE":     3,
+	"_DRAIN":        3,
+	"_LEECH":        3,
+	"_CAST_SUCCESS": 0,
+}
+
+// Returns the event for the given fields, or nil for events without a source and
+// destination.
+func newEvent(now time.Duration, fields []string) (*Event, error) {
+	eventType := fields[0]
+	if len(fields) < 9 {
+		return nil, nil
+	}
+
+	event := &Event{
+		Time:   now,
+		Type:   eventType,
+		Source: Unit{GUID: fields[1], Name: fields[2], Flags: parseInt(fields[3])},
+		Dest:   Unit{GUID: fields[5], Name: fields[6], Flags: parseInt(fields[7])},
+	}
+	params := fields[9:]
+
+	switch {
+	case strings.HasPrefix(eventType, "SWING_"):
+	case strings.HasPrefix(eventType, "ENVIRONMENTAL_"):
+		params = params[min(1, len(params)):]
+	case strings.HasPrefix(eventType, "SPELL_"), strings.HasPrefix(eventType, "RANGE_"), strings.HasPrefix(eventType, "DAMAGE_"):
+		if len(params) < 3 {
+			return nil, fmt.Errorf("%s is missing spell parameters", eventType)
+		}
+		event.SpellID = int32(parseInt(params[0]))
+		event.SpellName = params[1]
+		event.SpellSchool = int32(parseInt(params[2]))
+		params = params[3:]
+	default:
+		return nil, nil
+	}
+
+	// Advanced logging adds parameters before the suffix; detect them by count since
+	// older logs don't say whether it was enabled.
+	for suffix, numParams := range advancedSuffixParams {
+		if strings.HasSuffix(eventType, suffix) {
+			if len(params) >= numAdvancedParams+numParams && (numParams > 0 || len(params) == numAdvancedParams) {
+				event.Advanced = params[:numAdvancedParams]
+				params = params[numAdvancedParams:]
+			}
+			break
+		}
+	}
+	event.Params = params
+
+	return event, nil
+}
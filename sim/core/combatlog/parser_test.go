@@ -0,0 +1,141 @@
+package combatlog
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+const (
+	testPlayer = `Player-1-0001,"Tester-Realm",0x511,0x0`
+	testBoss   = `Creature-0-1-533-1-16028-0001,"Patchwerk",0x10a48,0x0`
+	testAdv    = `Player-1-0001,0000000000000000,5000,5000,1000,0,3000,0,1,50,100,0,1.0,2.0,1,3.0,60`
+)
+
+var testLog = strings.Join([]string{
+	`10/19 20:30:00.000  COMBAT_LOG_VERSION,9,ADVANCED_LOG_ENABLED,1,BUILD_VERSION,1.15.4,PROJECT_ID,2`,
+	`10/19 20:29:58.000  SPELL_CAST_SUCCESS,` + testPlayer + `,` + testBoss + `,11597,"Sunder Armor",0x1,` + testAdv,
+	`10/19 20:30:00.000  ENCOUNTER_START,1117,"Patchwerk",3,40,533`,
+	`10/19 20:30:00.500  SPELL_AURA_REMOVED,` + testPlayer + `,` + testPlayer + `,25289,"Battle Shout",0x1,BUFF`,
+	`10/19 20:30:01.000  SWING_DAMAGE,` + testPlayer + `,` + testBoss + `,` + testAdv + `,500,0,1,0,0,0,nil,nil,nil,nil`,
+	`10/19 20:30:01.500  SWING_MISSED,` + testPlayer + `,` + testBoss + `,DODGE,1`,
+	`10/19 20:30:02.000  SPELL_CAST_SUCCESS,` + testPlayer + `,` + testBoss + `,23894,"Bloodthirst",0x1,` + testAdv,
+	`10/19 20:30:02.000  SPELL_DAMAGE,` + testPlayer + `,` + testBoss + `,23894,"Bloodthirst",0x1,` + testAdv + `,1000,0,1,0,0,0,1,nil,nil`,
+	`10/19 20:30:02.000  SPELL_AURA_APPLIED,` + testPlayer + `,` + testPlayer + `,12970,"Flurry",0x1,BUFF`,
+	`10/19 20:30:03.000  SPELL_ENERGIZE,` + testPlayer + `,` + testPlayer + `,12964,"Unbridled Wrath",0x1,` + testAdv + `,1,0,1,100`,
+	`10/19 20:30:04.000  SPELL_AURA_REMOVED,` + testPlayer + `,` + testPlayer + `,12970,"Flurry",0x1,BUFF`,
+	`10/19 20:30:05.000  SWING_DAMAGE,` + testPlayer + `,` + testBoss + `,` + testAdv + `,300,0,1,0,0,0,nil,1,nil,1`,
+	`10/19 20:30:10.000  ENCOUNTER_END,1117,"Patchwerk",3,40,1,10000`,
+	`10/19 20:30:11.000  SWING_DAMAGE,` + testPlayer + `,` + testBoss + `,` + testAdv + `,500,0,1,0,0,0,nil,nil,nil,nil`,
+}, "\n")
+
+func TestParseEvents(t *testing.T) {
+	combatLog, err := Parse(strings.NewReader(testLog))
+	if err != nil {
+		t.Fatalf("Failed to parse log: %s", err)
+	}
+
+	if len(combatLog.Events) != 11 {
+		t.Fatalf("Expected 11 events, got %d", len(combatLog.Events))
+	}
+	if len(combatLog.Encounters) != 1 {
+		t.Fatalf("Expected 1 encounter, got %d", len(combatLog.Encounters))
+	}
+
+	encounter, err := combatLog.FindEncounter("patchwerk")
+	if err != nil {
+		t.Fatalf("Failed to find encounter: %s", err)
+	}
+	if !encounter.Kill || encounter.Duration() != time.Second*10 {
+		t.Fatalf("Expected a 10s kill, got kill = %t, duration = %s", encounter.Kill, encounter.Duration())
+	}
+
+	bloodthirst := combatLog.Events[encounter.StartIdx+4]
+	if bloodthirst.SpellID != 23894 || bloodthirst.FloatParam(0) != 1000 || !bloodthirst.BoolParam(6) {
+		t.Fatalf("Advanced logging parameters weren't removed: %v", bloodthirst.Params)
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	short, err := parseTimestamp("2/29 23:59:59.999")
+	if err != nil {
+		t.Fatalf("Failed to parse timestamp: %s", err)
+	}
+	long, err := parseTimestamp("3/1/2000 00:00:00.999-4")
+	if err != nil {
+		t.Fatalf("Failed to parse timestamp: %s", err)
+	}
+	if long.Sub(short) != time.Second {
+		t.Fatalf("Expected timestamps 1s apart, got %s", long.Sub(short))
+	}
+}
+
+func TestSplitFields(t *testing.T) {
+	fields := splitFields(`SPELL_DAMAGE,"Name, With Comma",0x1`)
+	if len(fields) != 3 || fields[1] != "Name, With Comma" {
+		t.Fatalf("Unexpected fields %q", fields)
+	}
+}
+
+func TestPlayerMetrics(t *testing.T) {
+	combatLog, err := Parse(strings.NewReader(testLog))
+	if err != nil {
+		t.Fatalf("Failed to parse log: %s", err)
+	}
+	encounter, _ := combatLog.FindEncounter("1")
+
+	metrics, err := combatLog.PlayerMetrics("Tester", encounter)
+	if err != nil {
+		t.Fatalf("Failed to extract metrics: %s", err)
+	}
+	if metrics.EncounterName != "Patchwerk" || metrics.DurationSeconds != 10 {
+		t.Fatalf("Unexpected encounter %q of %0.1fs", metrics.EncounterName, metrics.DurationSeconds)
+	}
+
+	player := metrics.Player
+	if player.Dps.Avg != 180 {
+		t.Fatalf("Expected 1800 damage over 10s = 180 DPS, got %0.1f", player.Dps.Avg)
+	}
+
+	findAction := func(id *proto.ActionID) *proto.TargetedActionMetrics {
+		for _, action := range player.Actions {
+			if action.Id.GetSpellId() == id.GetSpellId() && action.Id.GetOtherId() == id.GetOtherId() && action.Id.Tag == id.Tag {
+				return action.Targets[0]
+			}
+		}
+		t.Fatalf("Missing action %v", id)
+		return nil
+	}
+
+	mainHand := findAction(&proto.ActionID{RawId: &proto.ActionID_OtherId{OtherId: proto.OtherAction_OtherActionAttack}, Tag: tagMainhand})
+	if mainHand.Casts != 1 || mainHand.Hits != 1 || mainHand.Damage != 500 {
+		t.Fatalf("Unexpected main hand metrics: %v", mainHand)
+	}
+	offHand := findAction(&proto.ActionID{RawId: &proto.ActionID_OtherId{OtherId: proto.OtherAction_OtherActionAttack}, Tag: tagOffhand})
+	if offHand.Casts != 2 || offHand.Dodges != 1 || offHand.Glances != 1 || offHand.GlanceDamage != 300 {
+		t.Fatalf("Unexpected off hand metrics: %v", offHand)
+	}
+	bloodthirst := findAction(&proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: 23894}})
+	if bloodthirst.Casts != 1 || bloodthirst.Crits != 1 || bloodthirst.CritDamage != 1000 {
+		t.Fatalf("Unexpected Bloodthirst metrics: %v", bloodthirst)
+	}
+
+	uptimes := make(map[int32]float64)
+	for _, aura := range player.Auras {
+		uptimes[aura.Id.GetSpellId()] = aura.UptimeSecondsAvg
+	}
+	if math.Abs(uptimes[12970]-2) > 1e-9 || math.Abs(uptimes[25289]-0.5) > 1e-9 {
+		t.Fatalf("Unexpected aura uptimes: %v", uptimes)
+	}
+
+	if len(player.Resources) != 1 || player.Resources[0].Type != proto.ResourceType_ResourceTypeRage || player.Resources[0].Gain != 1 {
+		t.Fatalf("Unexpected resource metrics: %v", player.Resources)
+	}
+
+	if _, err := combatLog.PlayerMetrics("Nobody", encounter); err == nil {
+		t.Fatalf("Expected an error for a player not in the log")
+	}
+}
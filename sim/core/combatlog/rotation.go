@@ -0,0 +1,311 @@
+package combatlog
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+const (
+	// How long before an encounter casts are treated as prepull actions.
+	prepullWindow = time.Second * 10
+
+	// Spells cast with the aura they apply active less often than this are only
+	// cast when the aura is missing.
+	minRefreshFraction = 0.2
+
+	// Fraction of the casts whose remaining aura time or power is allowed below the
+	// inferred threshold, to ignore outliers.
+	thresholdOutliers = 0.1
+
+	// A power threshold is only added when players kept at least this fraction of
+	// their maximum power above the spell's cost.
+	minPowerPool = 0.2
+)
+
+// Combat log power types with an APL value.
+var powerValues = map[int64]func() *proto.APLValue{
+	0: func() *proto.APLValue {
+		return &proto.APLValue{Value: &proto.APLValue_CurrentMana{CurrentMana: &proto.APLValueCurrentMana{}}}
+	},
+	1: func() *proto.APLValue {
+		return &proto.APLValue{Value: &proto.APLValue_CurrentRage{CurrentRage: &proto.APLValueCurrentRage{}}}
+	},
+	3: func() *proto.APLValue {
+		return &proto.APLValue{Value: &proto.APLValue_CurrentEnergy{CurrentEnergy: &proto.APLValueCurrentEnergy{}}}
+	},
+}
+
+// State of an aura the player applied, on themselves or on a target.
+type observedAura struct {
+	active    bool
+	appliedAt time.Duration
+
+	// Longest time the aura lasted from an application or refresh, used as its duration.
+	duration time.Duration
+}
+
+// The state observed when the player cast a spell.
+type observedCast struct {
+	time time.Duration
+
+	selfAura   *observedAura
+	selfActive bool
+	selfAt     time.Duration
+
+	targetAura   *observedAura
+	targetActive bool
+	targetAt     time.Duration
+
+	powerType int64
+	power     float64
+	maxPower  float64
+	cost      float64
+	hasPower  bool
+}
+
+type observedSpell struct {
+	id        int32
+	name      string
+	casts     []*observedCast
+	periodic  bool
+	firstCast time.Duration
+}
+
+// Infers a starting rotation from a player's casts in an encounter of the log, or in
+// the whole log if encounter is nil. Spells are ordered from the least to the most
+// cast, and get conditions learned from the state at cast time: spells which apply an
+// aura are cast when it is missing or about to expire, and spells cast while pooling
+// power require that power.
+func (log *Log) InferRotation(playerName string, encounter *Encounter) (*proto.APLRotation, error) {
+	events, start, _ := log.Segment(encounter)
+	if encounter != nil {
+		// Include the casts before the pull.
+		firstIdx := encounter.StartIdx
+		for firstIdx > 0 && log.Events[firstIdx-1].Time >= encounter.Start-prepullWindow {
+			firstIdx--
+		}
+		events = log.Events[firstIdx:encounter.EndIdx]
+	}
+
+	selfAuras := make(map[string]*observedAura)
+	targetAuras := make(map[string]*observedAura)
+	spells := make(map[int32]*observedSpell)
+	var prepullCasts []*Event
+
+	// Returns the map and key of the aura an event refers to.
+	auraKey := func(event *Event) (map[string]*observedAura, string) {
+		if event.Dest.IsPlayer(playerName) {
+			return selfAuras, strconv.Itoa(int(event.SpellID))
+		}
+		return targetAuras, event.Dest.GUID + "-" + strconv.Itoa(int(event.SpellID))
+	}
+
+	for _, event := range events {
+		if !event.Source.IsPlayer(playerName) {
+			continue
+		}
+
+		switch event.Type {
+		case "SPELL_CAST_SUCCESS":
+			if event.Time < start {
+				prepullCasts = append(prepullCasts, event)
+				continue
+			}
+			spell, ok := spells[event.SpellID]
+			if !ok {
+				spell = &observedSpell{id: event.SpellID, name: event.SpellName, firstCast: event.Time}
+				spells[event.SpellID] = spell
+			}
+
+			cast := &observedCast{time: event.Time}
+			if aura := selfAuras[strconv.Itoa(int(event.SpellID))]; aura != nil {
+				cast.selfAura, cast.selfActive, cast.selfAt = aura, aura.active, aura.appliedAt
+			}
+			targetKey := event.Dest.GUID + "-" + strconv.Itoa(int(event.SpellID))
+			if aura := targetAuras[targetKey]; aura != nil {
+				cast.targetAura, cast.targetActive, cast.targetAt = aura, aura.active, aura.appliedAt
+			}
+			cast.powerType, cast.power, cast.maxPower, cast.cost, cast.hasPower = event.Power()
+			spell.casts = append(spell.casts, cast)
+
+		case "SPELL_AURA_APPLIED", "SPELL_AURA_REFRESH", "SPELL_AURA_APPLIED_DOSE":
+			auras, key := auraKey(event)
+			aura, ok := auras[key]
+			if !ok {
+				aura = &observedAura{}
+				auras[key] = aura
+			}
+			aura.active = true
+			aura.appliedAt = event.Time
+
+		case "SPELL_AURA_REMOVED":
+			auras, key := auraKey(event)
+			if aura := auras[key]; aura != nil && aura.active {
+				aura.active = false
+				aura.duration = max(aura.duration, event.Time-aura.appliedAt)
+			}
+
+		case "SPELL_PERIODIC_DAMAGE", "SPELL_PERIODIC_HEAL":
+			if spell, ok := spells[event.SpellID]; ok {
+				spell.periodic = true
+			}
+		}
+	}
+
+	if len(spells) == 0 && len(prepullCasts) == 0 {
+		return nil, fmt.Errorf("no casts for player %q", playerName)
+	}
+
+	rotation := &proto.APLRotation{Type: proto.APLRotation_TypeAPL}
+	for _, event := range prepullCasts {
+		rotation.PrepullActions = append(rotation.PrepullActions, &proto.APLPrepullAction{
+			Action:    &proto.APLAction{Action: castSpell(event.SpellID)},
+			DoAtValue: constValue(formatSeconds(-math.Round((start-event.Time).Seconds()*10) / 10)),
+		})
+	}
+
+	sortedSpells := make([]*observedSpell, 0, len(spells))
+	for _, spell := range spells {
+		sortedSpells = append(sortedSpells, spell)
+	}
+	slices.SortFunc(sortedSpells, func(a, b *observedSpell) int {
+		if len(a.casts) != len(b.casts) {
+			return cmp.Compare(len(a.casts), len(b.casts))
+		}
+		return cmp.Compare(a.firstCast, b.firstCast)
+	})
+
+	for _, spell := range sortedSpells {
+		rotation.PriorityList = append(rotation.PriorityList, &proto.APLListItem{
+			Notes: fmt.Sprintf("%s: cast %d times, first at %0.1fs", spell.name, len(spell.casts), (spell.firstCast - start).Seconds()),
+			Action: &proto.APLAction{
+				Condition: inferCondition(spell),
+				Action:    castSpell(spell.id),
+			},
+		})
+	}
+
+	return rotation, nil
+}
+
+// Returns the condition under which the player cast a spell, or nil if none was found.
+func inferCondition(spell *observedSpell) *proto.APLValue {
+	var conditions []*proto.APLValue
+	if condition := inferAuraCondition(spell); condition != nil {
+		conditions = append(conditions, condition)
+	}
+	if condition := inferPowerCondition(spell); condition != nil {
+		conditions = append(conditions, condition)
+	}
+
+	switch len(conditions) {
+	case 0:
+		return nil
+	case 1:
+		return conditions[0]
+	default:
+		return &proto.APLValue{Value: &proto.APLValue_And{And: &proto.APLValueAnd{Vals: conditions}}}
+	}
+}
+
+// Spells which apply an aura with their own ID are cast when the aura is missing, or
+// when its remaining time is below what the player usually refreshed it at.
+func inferAuraCondition(spell *observedSpell) *proto.APLValue {
+	onTarget := slices.ContainsFunc(spell.casts, func(cast *observedCast) bool { return cast.targetAura != nil })
+	onSelf := slices.ContainsFunc(spell.casts, func(cast *observedCast) bool { return cast.selfAura != nil })
+	if !onTarget && !onSelf {
+		return nil
+	}
+
+	var remaining []float64
+	var duration time.Duration
+	for _, cast := range spell.casts {
+		aura, active, appliedAt := cast.selfAura, cast.selfActive, cast.selfAt
+		if onTarget {
+			aura, active, appliedAt = cast.targetAura, cast.targetActive, cast.targetAt
+		}
+		if aura == nil || !active {
+			continue
+		}
+		duration = aura.duration
+		remaining = append(remaining, max(0, (appliedAt+aura.duration-cast.time).Seconds()))
+	}
+
+	id := &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: spell.id}}
+	var isActive, remainingTime *proto.APLValue
+	switch {
+	case onTarget && spell.periodic:
+		isActive = &proto.APLValue{Value: &proto.APLValue_DotIsActive{DotIsActive: &proto.APLValueDotIsActive{SpellId: id}}}
+		remainingTime = &proto.APLValue{Value: &proto.APLValue_DotRemainingTime{DotRemainingTime: &proto.APLValueDotRemainingTime{SpellId: id}}}
+	case onTarget:
+		target := &proto.UnitReference{Type: proto.UnitReference_CurrentTarget}
+		isActive = &proto.APLValue{Value: &proto.APLValue_AuraIsActive{AuraIsActive: &proto.APLValueAuraIsActive{SourceUnit: target, AuraId: id}}}
+		remainingTime = &proto.APLValue{Value: &proto.APLValue_AuraRemainingTime{AuraRemainingTime: &proto.APLValueAuraRemainingTime{SourceUnit: target, AuraId: id}}}
+	default:
+		isActive = &proto.APLValue{Value: &proto.APLValue_AuraIsActive{AuraIsActive: &proto.APLValueAuraIsActive{AuraId: id}}}
+		remainingTime = &proto.APLValue{Value: &proto.APLValue_AuraRemainingTime{AuraRemainingTime: &proto.APLValueAuraRemainingTime{AuraId: id}}}
+	}
+
+	if float64(len(remaining)) < minRefreshFraction*float64(len(spell.casts)) || duration == 0 {
+		return &proto.APLValue{Value: &proto.APLValue_Not{Not: &proto.APLValueNot{Val: isActive}}}
+	}
+
+	threshold := percentile(remaining, 1-thresholdOutliers)
+	return compare(proto.APLValueCompare_OpLt, remainingTime, constValue(formatSeconds(math.Ceil(threshold*2)/2)))
+}
+
+// Spells the player only cast while pooling power well above their cost require that
+// power.
+func inferPowerCondition(spell *observedSpell) *proto.APLValue {
+	var powers []float64
+	var powerType int64
+	var maxPower, cost float64
+	for _, cast := range spell.casts {
+		if cast.hasPower {
+			powers = append(powers, cast.power)
+			powerType, maxPower, cost = cast.powerType, cast.maxPower, cast.cost
+		}
+	}
+	powerValue, ok := powerValues[powerType]
+	if len(powers) == 0 || !ok || cost <= 0 {
+		return nil
+	}
+
+	threshold := math.Floor(percentile(powers, thresholdOutliers)/5) * 5
+	if threshold < cost+minPowerPool*maxPower {
+		return nil
+	}
+	return compare(proto.APLValueCompare_OpGe, powerValue(), constValue(strconv.Itoa(int(threshold))))
+}
+
+// Returns the value below which the given fraction of values lie.
+func percentile(values []float64, fraction float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	idx := int(math.Ceil(fraction*float64(len(sorted)))) - 1
+	return sorted[max(0, min(idx, len(sorted)-1))]
+}
+
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', -1, 64) + "s"
+}
+
+func castSpell(spellID int32) *proto.APLAction_CastSpell {
+	return &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{
+		SpellId: &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: spellID}},
+	}}
+}
+
+func constValue(val string) *proto.APLValue {
+	return &proto.APLValue{Value: &proto.APLValue_Const{Const: &proto.APLValueConst{Val: val}}}
+}
+
+func compare(op proto.APLValueCompare_ComparisonOperator, lhs, rhs *proto.APLValue) *proto.APLValue {
+	return &proto.APLValue{Value: &proto.APLValue_Cmp{Cmp: &proto.APLValueCompare{Op: op, Lhs: lhs, Rhs: rhs}}}
+}
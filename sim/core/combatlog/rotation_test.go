@@ -0,0 +1,88 @@
+package combatlog
+
+import (
+	"fmt"
+	"google.golang.org/protobuf/encoding/prototext"
+	"strings"
+	"testing"
+)
+
+// Advanced logging parameters of a rogue with the given energy after paying cost.
+func rogueAdv(energy int, cost int) string {
+	return fmt.Sprintf("Player-1-0001,0000000000000000,3000,3000,1000,0,2000,0,3,%d,100,%d,1.0,2.0,1,3.0,60", energy, cost)
+}
+
+func rogueLine(seconds float64, event string, dest string, params string) string {
+	return fmt.Sprintf("10/19 20:30:%06.3f  %s,%s,%s,%s", 20+seconds, event, testPlayer, dest, params)
+}
+
+func TestInferRotation(t *testing.T) {
+	cast := func(seconds float64, spell string, energy int, cost int) string {
+		return rogueLine(seconds, "SPELL_CAST_SUCCESS", testBoss, spell+","+rogueAdv(energy, cost))
+	}
+	const (
+		sliceAndDice   = `6774,"Slice and Dice",0x1`
+		rupture        = `1943,"Rupture",0x1`
+		sinisterStrike = `1752,"Sinister Strike",0x1`
+	)
+
+	lines := []string{
+		rogueLine(-1, "SPELL_CAST_SUCCESS", `0000000000000000,nil,0x80000000,0x80000000`, `1784,"Stealth",0x1,`+rogueAdv(100, 0)),
+		"10/19 20:30:20.000  ENCOUNTER_START,1117,\"Patchwerk\",3,40,533",
+		cast(1, sliceAndDice, 5, 25),
+		rogueLine(1, "SPELL_AURA_APPLIED", testPlayer, sliceAndDice+",BUFF"),
+		cast(3, rupture, 5, 25),
+		rogueLine(3, "SPELL_AURA_APPLIED", testBoss, rupture+",DEBUFF"),
+		rogueLine(5, "SPELL_PERIODIC_DAMAGE", testBoss, rupture+","+rogueAdv(50, 0)+",200,0,1,0,0,0,nil,nil,nil"),
+		cast(5, sinisterStrike, 35, 45),
+		cast(7, sinisterStrike, 40, 45),
+		rogueLine(10, "SPELL_AURA_REMOVED", testPlayer, sliceAndDice+",BUFF"),
+		cast(11, sinisterStrike, 45, 45),
+		cast(12, sliceAndDice, 5, 25),
+		rogueLine(12, "SPELL_AURA_APPLIED", testPlayer, sliceAndDice+",BUFF"),
+		cast(14, sinisterStrike, 40, 45),
+		cast(16, sinisterStrike, 50, 45),
+		rogueLine(19, "SPELL_AURA_REMOVED", testBoss, rupture+",DEBUFF"),
+		cast(19, sliceAndDice, 5, 25),
+		rogueLine(19, "SPELL_AURA_REFRESH", testPlayer, sliceAndDice+",BUFF"),
+		cast(20, rupture, 5, 25),
+		rogueLine(20, "SPELL_AURA_APPLIED", testBoss, rupture+",DEBUFF"),
+		cast(26.5, sliceAndDice, 5, 25),
+		rogueLine(26.5, "SPELL_AURA_REFRESH", testPlayer, sliceAndDice+",BUFF"),
+		"10/19 20:30:50.000  ENCOUNTER_END,1117,\"Patchwerk\",3,40,1,30000",
+	}
+
+	combatLog, err := Parse(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("Failed to parse log: %s", err)
+	}
+	rotation, err := combatLog.InferRotation("Tester", combatLog.Encounters[0])
+	if err != nil {
+		t.Fatalf("Failed to infer rotation: %s", err)
+	}
+
+	if len(rotation.PrepullActions) != 1 || rotation.PrepullActions[0].DoAtValue.GetConst().Val != "-1s" {
+		t.Fatalf("Expected Stealth as a prepull action at -1s, got %v", rotation.PrepullActions)
+	}
+
+	expected := []struct {
+		spellID   int32
+		condition string
+	}{
+		{1943, `not:{val:{dot_is_active:{spell_id:{spell_id:1943}}}}`},
+		{6774, `cmp:{op:OpLt lhs:{aura_remaining_time:{aura_id:{spell_id:6774}}} rhs:{const:{val:"2s"}}}`},
+		{1752, `cmp:{op:OpGe lhs:{current_energy:{}} rhs:{const:{val:"80"}}}`},
+	}
+	if len(rotation.PriorityList) != len(expected) {
+		t.Fatalf("Expected %d actions, got %d", len(expected), len(rotation.PriorityList))
+	}
+	for i, item := range rotation.PriorityList {
+		if spellID := item.Action.GetCastSpell().SpellId.GetSpellId(); spellID != expected[i].spellID {
+			t.Fatalf("Expected spell %d at position %d, got %d", expected[i].spellID, i, spellID)
+		}
+		condition := strings.Join(strings.Fields(prototext.MarshalOptions{}.Format(item.Action.Condition)), "")
+		if want := strings.Join(strings.Fields(expected[i].condition), ""); condition != want {
+			t.Fatalf("Unexpected condition for spell %d:\n got: %s\nwant: %s", expected[i].spellID, condition, want)
+		}
+	}
+}
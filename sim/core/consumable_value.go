@@ -0,0 +1,273 @@
+package core
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	googleProto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Small DPS differences need more iterations than DPS to settle.
+const defaultConsumableValueIterations = 3000
+
+const defaultConsumableFightsPerHour = 4
+
+// How long a buff consumable lasts, for those which don't last as long as the others
+// of their field in consumableFieldDurations.
+var consumableDurations = map[string]time.Duration{
+	"FoodGrilledSquid":         time.Minute * 10,
+	"FoodNightfinSoup":         time.Minute * 10,
+	"FoodRunnTumTuberSurprise": time.Minute * 10,
+	"FoodBlessedSunfruitJuice": time.Minute * 10,
+	"FoodBlessSunfruit":        time.Minute * 10,
+	"ScrollOfAgility":          time.Minute * 30,
+	"ScrollOfStrength":         time.Minute * 30,
+	"ScrollOfProtection":       time.Minute * 30,
+	"JujuPower":                time.Minute * 30,
+	"JujuMight":                time.Minute * 10,
+	"WinterfallFirewater":      time.Minute * 20,
+	"ArcaneElixir":             time.Minute * 30,
+	"ElixirOfShadowPower":      time.Minute * 30,
+	"ElixirOfFirepower":        time.Minute * 30,
+	"ElixirOfGreaterFirepower": time.Minute * 30,
+	"ElixirOfFrostPower":       time.Minute * 30,
+	"SpiritOfZanza":            time.Hour * 2,
+	"SheenOfZanza":             time.Hour * 2,
+	"SwiftnessOfZanza":         time.Hour * 2,
+	"miscConsumes.boglingRoot": time.Minute * 30,
+	"miscConsumes.jujuEmber":   time.Minute * 10,
+	"miscConsumes.jujuChill":   time.Minute * 10,
+}
+
+// How long buff consumables last, by their field in Consumes.
+var consumableFieldDurations = map[string]time.Duration{
+	"flask":                    time.Hour * 2,
+	"food":                     time.Minute * 15,
+	"agilityElixir":            time.Hour,
+	"manaRegenElixir":          time.Hour,
+	"strengthBuff":             time.Hour,
+	"attackPowerBuff":          time.Hour,
+	"spellPowerBuff":           time.Hour,
+	"shadowPowerBuff":          time.Hour,
+	"firePowerBuff":            time.Hour,
+	"frostPowerBuff":           time.Hour,
+	"mainHandImbue":            time.Minute * 30,
+	"offHandImbue":             time.Minute * 30,
+	"petAgilityConsumable":     time.Minute * 30,
+	"petStrengthConsumable":    time.Minute * 30,
+	"dragonBreathChili":        time.Minute * 10,
+	"zanzaBuff":                time.Hour,
+	"armorElixir":              time.Hour,
+	"healthElixir":             time.Hour,
+	"alcohol":                  time.Minute * 15,
+	"petAttackPowerConsumable": time.Minute * 10,
+}
+
+// Fields of consumables which are used during the fight, rather than bought for their
+// duration.
+var perFightConsumableFields = []string{"defaultPotion", "fillerExplosive", "sapperExplosive"}
+
+// A consumable set in the player's Consumes, by the path of its field.
+type playerConsumable struct {
+	name string
+	path []protoreflect.FieldDescriptor
+}
+
+// Values each of a player's consumables, by the DPS lost when simming without it, and
+// ranks them by DPS gained per gold spent on them in a raid hour. Buffs are bought for
+// their duration, and consumables used in combat, like potions and explosives, for the
+// casts they lose in each fight.
+func ValueConsumables(request *proto.ConsumableValueRequest, signals simsignals.Signals) (result *proto.ConsumableValueResult) {
+	defer recoverRequestError(func(errorOutcome *proto.ErrorOutcome) {
+		result = &proto.ConsumableValueResult{Error: errorOutcome}
+	})
+
+	if request.Raid == nil || request.Encounter == nil {
+		return &proto.ConsumableValueResult{Error: &proto.ErrorOutcome{Message: "missing raid or encounter"}}
+	}
+
+	playerRef := request.Player
+	if playerRef == nil {
+		playerRef = &proto.UnitReference{Type: proto.UnitReference_Player, Index: 0}
+	}
+	if playerRef.Type != proto.UnitReference_Player {
+		return &proto.ConsumableValueResult{Error: &proto.ErrorOutcome{Message: "consumables can only be valued for a player"}}
+	}
+	partyIdx, playerIdx, errorOutcome := raidPlayerIndices(request.Raid, playerRef.Index)
+	if errorOutcome != nil {
+		return &proto.ConsumableValueResult{Error: errorOutcome}
+	}
+
+	consumables := playerConsumables(request.Raid.Parties[partyIdx].Players[playerIdx].Consumes)
+	if len(consumables) == 0 {
+		return &proto.ConsumableValueResult{Error: &proto.ErrorOutcome{Message: "the player has no consumables to value"}}
+	}
+
+	simOptions := requestSimOptions(request.SimOptions, defaultConsumableValueIterations)
+
+	fightsPerHour := request.FightsPerHour
+	if fightsPerHour <= 0 {
+		fightsPerHour = defaultConsumableFightsPerHour
+	}
+
+	// Returns the player's DPS, and its casts per fight of each action.
+	runSim := func(consumable *playerConsumable) (float64, map[ActionID]float64) {
+		if signals.Abort.IsTriggered() {
+			panic("aborted")
+		}
+
+		raid := googleProto.Clone(request.Raid).(*proto.Raid)
+		if consumable != nil {
+			consumable.clear(raid.Parties[partyIdx].Players[playerIdx].Consumes)
+		}
+
+		simResult := RunSim(&proto.RaidSimRequest{
+			Raid:       raid,
+			Encounter:  request.Encounter,
+			SimOptions: simOptions,
+		}, nil, signals)
+		if simResult.Error != nil {
+			panic(simResult.Error.Message)
+		}
+
+		metrics := simResult.RaidMetrics.Parties[partyIdx].Players[playerIdx]
+		casts := make(map[ActionID]float64)
+		for _, action := range metrics.Actions {
+			for _, target := range action.Targets {
+				casts[ProtoToActionID(action.Id)] += float64(target.Casts) / float64(simOptions.Iterations)
+			}
+		}
+		return metrics.Dps.Avg, casts
+	}
+
+	baseDps, baseCasts := runSim(nil)
+	result = &proto.ConsumableValueResult{
+		BaseDps: baseDps,
+	}
+
+	for _, consumable := range consumables {
+		dps, casts := runSim(&consumable)
+		value := &proto.ConsumableValue{
+			Consumable: consumable.name,
+			DpsGain:    baseDps - dps,
+		}
+
+		value.UsesPerFight = consumable.usesPerFight(baseCasts, casts)
+		if value.UsesPerFight > 0 {
+			value.UsesPerHour = value.UsesPerFight * fightsPerHour
+		} else {
+			value.UsesPerHour = float64(time.Hour) / float64(consumable.duration())
+		}
+
+		if price, ok := request.Prices[consumable.name]; ok {
+			value.Priced = true
+			value.GoldPerHour = price * value.UsesPerHour
+			if value.GoldPerHour > 0 {
+				value.DpsPerGold = value.DpsGain / value.GoldPerHour
+			}
+		}
+		result.Consumables = append(result.Consumables, value)
+	}
+
+	// Consumables which cost gold first, by DPS per gold, then the others by DPS.
+	slices.SortStableFunc(result.Consumables, func(a, b *proto.ConsumableValue) int {
+		if (a.GoldPerHour > 0) != (b.GoldPerHour > 0) {
+			if a.GoldPerHour > 0 {
+				return -1
+			}
+			return 1
+		}
+		aValue, bValue := a.DpsGain, b.DpsGain
+		if a.GoldPerHour > 0 {
+			aValue, bValue = a.DpsPerGold, b.DpsPerGold
+		}
+		if aValue > bValue {
+			return -1
+		} else if aValue < bValue {
+			return 1
+		}
+		return 0
+	})
+
+	return result
+}
+
+// Returns each consumable set in the player's Consumes, named by its enum value, e.g.
+// "FlaskOfSupremePower", or by the path of its field, e.g. "miscConsumes.jujuFlurry".
+func playerConsumables(consumes *proto.Consumes) []playerConsumable {
+	if consumes == nil {
+		return nil
+	}
+
+	var consumables []playerConsumable
+	var addFields func(msg protoreflect.Message, parent []protoreflect.FieldDescriptor)
+	addFields = func(msg protoreflect.Message, parent []protoreflect.FieldDescriptor) {
+		fields := msg.Descriptor().Fields()
+		for i := 0; i < fields.Len(); i++ {
+			field := fields.Get(i)
+			if !msg.Has(field) || field.Options().(*descriptorpb.FieldOptions).GetDeprecated() {
+				continue
+			}
+
+			path := append(slices.Clone(parent), field)
+			if field.Kind() == protoreflect.MessageKind {
+				addFields(msg.Get(field).Message(), path)
+				continue
+			}
+
+			names := make([]string, len(path))
+			for j, pathField := range path {
+				names[j] = pathField.JSONName()
+			}
+			name := strings.Join(names, ".")
+			if field.Kind() == protoreflect.EnumKind {
+				if enumValue := field.Enum().Values().ByNumber(msg.Get(field).Enum()); enumValue != nil {
+					name = string(enumValue.Name())
+				}
+			}
+			consumables = append(consumables, playerConsumable{name: name, path: path})
+		}
+	}
+	addFields(consumes.ProtoReflect(), nil)
+
+	return consumables
+}
+
+func (consumable *playerConsumable) clear(consumes *proto.Consumes) {
+	msg := consumes.ProtoReflect()
+	for _, field := range consumable.path[:len(consumable.path)-1] {
+		msg = msg.Mutable(field).Message()
+	}
+	msg.Clear(consumable.path[len(consumable.path)-1])
+}
+
+func (consumable *playerConsumable) duration() time.Duration {
+	if duration, ok := consumableDurations[consumable.name]; ok {
+		return duration
+	}
+	if duration, ok := consumableFieldDurations[consumable.path[0].JSONName()]; ok {
+		return duration
+	}
+	return time.Hour
+}
+
+// Returns how often a potion or explosive is used per fight, by the casts of items which
+// stop without it. Procs of other consumables, e.g. oils, aren't uses of them.
+func (consumable *playerConsumable) usesPerFight(baseCasts map[ActionID]float64, casts map[ActionID]float64) float64 {
+	if !slices.Contains(perFightConsumableFields, consumable.path[0].JSONName()) {
+		return 0
+	}
+
+	uses := 0.0
+	for actionID, baseActionCasts := range baseCasts {
+		if actionID.ItemID != 0 && casts[actionID] == 0 {
+			uses += baseActionCasts
+		}
+	}
+	return uses
+}
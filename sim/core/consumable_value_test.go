@@ -0,0 +1,112 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+func TestPlayerConsumables(t *testing.T) {
+	consumables := playerConsumables(&proto.Consumes{
+		Flask:        proto.Flask_FlaskOfSupremePower,
+		MiscConsumes: &proto.MiscConsumes{JujuEmber: true},
+		BoglingRoot:  true,
+	})
+
+	// The deprecated field is left out.
+	if len(consumables) != 2 {
+		t.Fatalf("Expected 2 consumables, got %d", len(consumables))
+	}
+	if consumables[0].name != "FlaskOfSupremePower" || consumables[0].duration() != time.Hour*2 {
+		t.Fatalf("Unexpected flask %s lasting %s", consumables[0].name, consumables[0].duration())
+	}
+	if consumables[1].name != "miscConsumes.jujuEmber" || consumables[1].duration() != time.Minute*10 {
+		t.Fatalf("Unexpected misc consumable %s lasting %s", consumables[1].name, consumables[1].duration())
+	}
+
+	consumes := &proto.Consumes{MiscConsumes: &proto.MiscConsumes{JujuEmber: true, JujuChill: true}}
+	consumables[1].clear(consumes)
+	if consumes.MiscConsumes.JujuEmber || !consumes.MiscConsumes.JujuChill {
+		t.Fatalf("Expected only Juju Ember to be cleared")
+	}
+}
+
+func TestValueConsumablesExplosive(t *testing.T) {
+	result := ValueConsumables(&proto.ConsumableValueRequest{
+		Raid: SinglePlayerRaidProto(&proto.Player{
+			Name:        "Engineer",
+			Class:       proto.Class_ClassShaman,
+			Profession1: proto.Profession_Engineering,
+			Consumes: &proto.Consumes{
+				Flask:           proto.Flask_FlaskOfTheTitans,
+				FillerExplosive: proto.Explosive_ExplosiveDenseDynamite,
+			},
+			Buffs:     &proto.IndividualBuffs{},
+			Spec:      &proto.Player_ElementalShaman{},
+			Equipment: &proto.EquipmentSpec{},
+			Rotation: &proto.APLRotation{
+				Type: proto.APLRotation_TypeAPL,
+				PriorityList: []*proto.APLListItem{
+					{Action: &proto.APLAction{Action: &proto.APLAction_AutocastOtherCooldowns{AutocastOtherCooldowns: &proto.APLActionAutocastOtherCooldowns{}}}},
+				},
+			},
+		}, &proto.PartyBuffs{}, &proto.RaidBuffs{}, &proto.Debuffs{}),
+		Encounter: &proto.Encounter{
+			Targets: []*proto.Target{
+				{Level: 63, MobType: proto.MobType_MobTypeDemon},
+			},
+			Duration: 60,
+		},
+		SimOptions: &proto.SimOptions{
+			Iterations: 10,
+			RandomSeed: 101,
+			IsTest:     true,
+		},
+		Prices: map[string]float64{
+			"ExplosiveDenseDynamite": 1,
+			"FlaskOfTheTitans":       20,
+		},
+		FightsPerHour: 3,
+	}, simsignals.CreateSignals())
+	if result.Error != nil {
+		t.Fatalf("Valuing failed: %s", result.Error.Message)
+	}
+
+	if len(result.Consumables) != 2 {
+		t.Fatalf("Expected 2 consumables, got %d", len(result.Consumables))
+	}
+	dynamite, flask := result.Consumables[0], result.Consumables[1]
+	if dynamite.Consumable != "ExplosiveDenseDynamite" || dynamite.DpsGain <= 0 || dynamite.UsesPerFight <= 0 {
+		t.Fatalf("Expected the dynamite to be used for DPS, got %v", dynamite)
+	}
+	if dynamite.UsesPerHour != dynamite.UsesPerFight*3 || dynamite.GoldPerHour != dynamite.UsesPerHour || dynamite.DpsPerGold != dynamite.DpsGain/dynamite.GoldPerHour {
+		t.Fatalf("Unexpected dynamite cost %v", dynamite)
+	}
+	if flask.Consumable != "FlaskOfTheTitans" || flask.DpsGain != 0 || flask.UsesPerHour != 0.5 || flask.GoldPerHour != 10 {
+		t.Fatalf("Expected the flask to last 2 hours without DPS, got %v", flask)
+	}
+}
+
+func TestConsumableUsesPerFight(t *testing.T) {
+	consumables := playerConsumables(&proto.Consumes{
+		FillerExplosive: proto.Explosive_ExplosiveDenseDynamite,
+		MainHandImbue:   proto.WeaponImbue_ShadowOil,
+	})
+	dynamite, oil := consumables[0], consumables[1]
+
+	shadowOilProc := ActionID{SpellID: 1382}
+	baseCasts := map[ActionID]float64{
+		DenseDynamiteActionID: 2,
+		shadowOilProc:         12,
+	}
+
+	// The oil's procs stop without it, but it's bought for its duration.
+	if uses := oil.usesPerFight(baseCasts, map[ActionID]float64{DenseDynamiteActionID: 2}); uses != 0 || oil.duration() != time.Minute*30 {
+		t.Fatalf("Expected the oil to last 30 minutes without uses, got %.1f uses lasting %s", uses, oil.duration())
+	}
+	if uses := dynamite.usesPerFight(baseCasts, map[ActionID]float64{shadowOilProc: 12}); uses != 2 {
+		t.Fatalf("Expected the dynamite to be used twice, got %.1f", uses)
+	}
+}
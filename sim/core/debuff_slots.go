@@ -0,0 +1,218 @@
+package core
+
+import (
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+// Priorities of debuffs competing for a target's debuff slots. A debuff only pushes
+// off debuffs with a lower priority.
+const (
+	DebuffPriorityOther int32 = 1 // Poisons, stings and other debuffs from players.
+	DebuffPriorityDot   int32 = 2
+	DebuffPriorityMajor int32 = 3 // Raid debuffs which increase everyone's damage or reduce the boss's.
+)
+
+// Spell IDs of raid debuffs, using the IDs of the auras in debuffs.go.
+var defaultDebuffPriorities = map[int32]int32{
+	11597:  DebuffPriorityMajor, // Sunder Armor
+	11198:  DebuffPriorityMajor, // Expose Armor
+	9907:   DebuffPriorityMajor, // Faerie Fire
+	17392:  DebuffPriorityMajor, // Faerie Fire (Feral)
+	11717:  DebuffPriorityMajor, // Curse of Recklessness
+	11722:  DebuffPriorityMajor, // Curse of Elements
+	17937:  DebuffPriorityMajor, // Curse of Shadow
+	11708:  DebuffPriorityMajor, // Curse of Weakness
+	15334:  DebuffPriorityMajor, // Shadow Weaving
+	17800:  DebuffPriorityMajor, // Improved Shadow Bolt
+	28593:  DebuffPriorityMajor, // Winter's Chill
+	12873:  DebuffPriorityMajor, // Improved Scorch
+	17364:  DebuffPriorityMajor, // Stormstrike
+	20355:  DebuffPriorityMajor, // Judgement of Wisdom
+	20271:  DebuffPriorityMajor, // Judgement of Light
+	20303:  DebuffPriorityMajor, // Judgement of the Crusader
+	14325:  DebuffPriorityMajor, // Hunter's Mark
+	23577:  DebuffPriorityMajor, // Expose Weakness
+	11374:  DebuffPriorityMajor, // Gift of Arthas
+	17348:  DebuffPriorityMajor, // Hemorrhage
+	409828: DebuffPriorityMajor, // Mangle
+	11556:  DebuffPriorityMajor, // Demoralizing Shout
+	9898:   DebuffPriorityMajor, // Demoralizing Roar
+	8205:   DebuffPriorityMajor, // Thunder Clap
+	26016:  DebuffPriorityMajor, // Vindication
+	21992:  DebuffPriorityMajor, // Thunderfury
+}
+
+// A debuff which takes a debuff slot on its target.
+type debuffSlot struct {
+	aura     *Aura
+	caster   *Unit // nil for debuffs not applied by a player, e.g. from the raid's debuff settings.
+	priority int32
+
+	// Metrics for the current iteration.
+	denied  int32
+	evicted int32
+
+	// Aggregate values. These are updated after each iteration.
+	deniedSum  int32
+	evictedSum int32
+	n          int32
+}
+
+// The debuff slots of a target.
+type debuffSlots struct {
+	limit  int
+	active []*Aura
+	slots  []*debuffSlot
+}
+
+// Returns the debuff slot limit of a target, or 0 if it is unlimited.
+func debuffSlotLimit(config *proto.DebuffSlots, targetLimit int32) int {
+	if targetLimit > 0 {
+		return int(targetLimit)
+	}
+	if config == nil {
+		return 0
+	}
+	return int(max(config.Limit, 0))
+}
+
+// Limits the debuffs on the encounter's targets. Called after finalization, once all
+// auras and spells are registered.
+func (env *Environment) setupDebuffSlots() {
+	config := env.Encounter.debuffSlots
+	priorities := make(map[int32]int32, len(defaultDebuffPriorities))
+	for spellID, priority := range defaultDebuffPriorities {
+		priorities[spellID] = priority
+	}
+	if config != nil {
+		for _, override := range config.Priorities {
+			priorities[ProtoToActionID(override.Id).SpellID] = override.Priority
+		}
+	}
+
+	// Find who applies each debuff. Debuffs cast by several players, e.g. Sunder
+	// Armor from 2 warriors, share their aura and aren't attributed to anyone.
+	dotCasters := make(map[*Aura]*Unit)
+	spellCasters := make(map[int32]*Unit)
+	sharedSpells := make(map[int32]bool)
+	for _, unit := range env.Raid.AllUnits {
+		for _, spell := range unit.Spellbook {
+			for _, dot := range spell.dots {
+				if dot != nil {
+					dotCasters[dot.Aura] = unit
+				}
+			}
+			if spellID := spell.ActionID.SpellID; spellID != 0 {
+				if caster, ok := spellCasters[spellID]; ok && caster != unit {
+					sharedSpells[spellID] = true
+				}
+				spellCasters[spellID] = unit
+			}
+		}
+	}
+
+	for _, target := range env.Encounter.Targets {
+		limit := debuffSlotLimit(config, target.debuffSlotLimit)
+		if limit == 0 {
+			continue
+		}
+
+		slots := &debuffSlots{limit: limit}
+		for _, aura := range target.auras {
+			spellID := aura.ActionID.SpellID
+			if spellID == 0 {
+				continue
+			}
+
+			slot := &debuffSlot{aura: aura}
+			if caster, ok := dotCasters[aura]; ok {
+				slot.caster = caster
+				slot.priority = DebuffPriorityDot
+			} else if caster, ok := spellCasters[spellID]; ok && !sharedSpells[spellID] {
+				slot.caster = caster
+				slot.priority = DebuffPriorityOther
+			}
+			if priority, ok := priorities[spellID]; ok {
+				slot.priority = priority
+			} else if slot.caster == nil {
+				// Auras of the target itself, or helper auras without a known caster.
+				continue
+			}
+
+			aura.debuffSlot = slot
+			slots.slots = append(slots.slots, slot)
+			if slot.caster != nil {
+				slot.caster.Metrics.debuffSlots = append(slot.caster.Metrics.debuffSlots, slot)
+			} else {
+				target.Metrics.debuffSlots = append(target.Metrics.debuffSlots, slot)
+			}
+		}
+
+		target.debuffSlots = slots
+		target.RegisterResetEffect(func(_ *Simulation) {
+			slots.active = slots.active[:0]
+		})
+	}
+}
+
+// Takes a debuff slot for the aura, pushing off a lower priority debuff if the target
+// is full. Returns false if the aura was denied.
+func (slots *debuffSlots) take(sim *Simulation, aura *Aura) bool {
+	if len(slots.active) < slots.limit {
+		slots.active = append(slots.active, aura)
+		return true
+	}
+
+	// Oldest debuff with the lowest priority.
+	var lowest *Aura
+	for _, active := range slots.active {
+		if lowest == nil || active.debuffSlot.priority < lowest.debuffSlot.priority {
+			lowest = active
+		}
+	}
+
+	if lowest.debuffSlot.priority >= aura.debuffSlot.priority {
+		aura.debuffSlot.denied++
+		if sim.Log != nil {
+			aura.Unit.Log(sim, "Debuff denied, no free debuff slot: %s", aura.ActionID)
+		}
+		return false
+	}
+
+	lowest.debuffSlot.evicted++
+	if sim.Log != nil {
+		aura.Unit.Log(sim, "Debuff %s pushed off by %s", lowest.ActionID, aura.ActionID)
+	}
+	lowest.Deactivate(sim)
+	slots.active = append(slots.active, aura)
+	return true
+}
+
+func (slots *debuffSlots) release(aura *Aura) {
+	for i, active := range slots.active {
+		if active == aura {
+			slots.active = append(slots.active[:i], slots.active[i+1:]...)
+			return
+		}
+	}
+}
+
+func (slots *debuffSlots) doneIteration() {
+	for _, slot := range slots.slots {
+		slot.deniedSum += slot.denied
+		slot.evictedSum += slot.evicted
+		slot.n++
+		slot.denied = 0
+		slot.evicted = 0
+	}
+}
+
+func (slot *debuffSlot) ToProto() *proto.DebuffSlotMetrics {
+	n := float64(max(slot.n, 1))
+	return &proto.DebuffSlotMetrics{
+		Id:          slot.aura.ActionID.ToProto(),
+		TargetIndex: slot.aura.Unit.Index,
+		DeniedAvg:   float64(slot.deniedSum) / n,
+		EvictedAvg:  float64(slot.evictedSum) / n,
+	}
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+func setupDebuffSlotSim(debuffSlots *proto.DebuffSlots) *Simulation {
+	sim := NewSim(&proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{
+			RandomSeed: 100,
+		},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{
+							Name:      "Caster",
+							Class:     proto.Class_ClassShaman,
+							Consumes:  &proto.Consumes{},
+							Buffs:     &proto.IndividualBuffs{},
+							Spec:      &proto.Player_ElementalShaman{},
+							Equipment: &proto.EquipmentSpec{},
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				},
+			},
+			Debuffs: &proto.Debuffs{
+				CurseOfElements:     true,
+				CurseOfShadow:       true,
+				CurseOfRecklessness: true,
+				FaerieFire:          true,
+			},
+		},
+		Encounter: &proto.Encounter{
+			Targets: []*proto.Target{
+				{Name: "target", Level: 63, MobType: proto.MobType_MobTypeDemon},
+			},
+			Duration:    180,
+			DebuffSlots: debuffSlots,
+		},
+	}, simsignals.CreateSignals())
+	sim.Reset()
+
+	return sim
+}
+
+func TestDebuffSlotsUnlimited(t *testing.T) {
+	sim := setupDebuffSlotSim(nil)
+	target := sim.GetTargetUnit(0)
+
+	for _, label := range []string{"Curse of Elements", "Curse of Shadow", "Curse of Recklessness", "Faerie Fire"} {
+		if !target.GetAura(label).IsActive() {
+			t.Fatalf("Expected %s to be active without a debuff slot limit", label)
+		}
+	}
+}
+
+func TestDebuffSlotsDenied(t *testing.T) {
+	sim := setupDebuffSlotSim(&proto.DebuffSlots{Limit: 2})
+	target := sim.GetTargetUnit(0)
+
+	if !target.GetAura("Curse of Elements").IsActive() || !target.GetAura("Curse of Shadow").IsActive() {
+		t.Fatalf("Expected the first 2 debuffs to take the debuff slots")
+	}
+	if target.GetAura("Curse of Recklessness").IsActive() || target.GetAura("Faerie Fire").IsActive() {
+		t.Fatalf("Expected debuffs of the same priority to be denied when the target is full")
+	}
+
+	sim.Cleanup()
+	metrics := target.Metrics.ToProto().DebuffSlots
+	if len(metrics) != 4 || metrics[0].DeniedAvg != 0 || metrics[2].DeniedAvg != 1 || metrics[3].DeniedAvg != 1 {
+		t.Fatalf("Unexpected debuff slot metrics: %v", metrics)
+	}
+}
+
+func TestDebuffSlotsEviction(t *testing.T) {
+	sim := setupDebuffSlotSim(&proto.DebuffSlots{
+		Limit: 2,
+		Priorities: []*proto.DebuffSlotPriority{
+			{Id: ActionID{SpellID: 9907}.ToProto(), Priority: DebuffPriorityMajor + 1},
+		},
+	})
+	target := sim.GetTargetUnit(0)
+
+	if target.GetAura("Curse of Elements").IsActive() {
+		t.Fatalf("Expected the oldest lowest priority debuff to be pushed off")
+	}
+	if !target.GetAura("Curse of Shadow").IsActive() || !target.GetAura("Faerie Fire").IsActive() {
+		t.Fatalf("Expected Faerie Fire to take Curse of Elements' slot")
+	}
+
+	sim.Cleanup()
+	metrics := target.Metrics.ToProto().DebuffSlots
+	if metrics[0].EvictedAvg != 1 || metrics[3].DeniedAvg != 0 {
+		t.Fatalf("Unexpected debuff slot metrics: %v", metrics)
+	}
+}
+
+func TestDebuffSlotsCombineConcurrentResults(t *testing.T) {
+	sim := setupDebuffSlotSim(&proto.DebuffSlots{Limit: 2})
+	target := sim.GetTargetUnit(0)
+	sim.Cleanup()
+
+	result := &proto.RaidSimResult{
+		RaidMetrics:      sim.Raid.GetMetrics(),
+		EncounterMetrics: &proto.EncounterMetrics{Targets: []*proto.UnitMetrics{target.Metrics.ToProto()}},
+		IterationsDone:   1,
+	}
+	combined := CombineConcurrentSimResults([]*proto.RaidSimResult{result, result}, false)
+
+	metrics := combined.EncounterMetrics.Targets[0].DebuffSlots
+	if len(metrics) != 4 || metrics[2].DeniedAvg != 1 || metrics[3].DeniedAvg != 1 {
+		t.Fatalf("Unexpected combined debuff slot metrics: %v", metrics)
+	}
+}
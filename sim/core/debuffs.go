@@ -38,18 +38,22 @@ func applyDebuffEffects(target *Unit, targetIdx int, debuffs *proto.Debuffs, rai
 
 	if debuffs.ShadowWeaving {
 		aura := ShadowWeavingAura(target, 5)
-		SchedulePeriodicDebuffApplication(aura, PeriodicActionOptions{
-			Period:          time.Millisecond * 1500,
-			NumTicks:        5,
-			TickImmediately: true,
-			Priority:        ActionPriorityDOT, // High prio
-			OnAction: func(sim *Simulation) {
-				aura.Activate(sim)
-				if aura.IsActive() {
-					aura.AddStack(sim)
-				}
-			},
-		}, raid)
+		if rampUp := debuffs.RampUp; rampUp != nil {
+			ScheduleExternalDebuffStacks(aura, rampUp.StartDelay, rampUp.ShadowWeavingCasters, rampUp.ShadowWeavingInterval, GCDDefault)
+		} else {
+			SchedulePeriodicDebuffApplication(aura, PeriodicActionOptions{
+				Period:          time.Millisecond * 1500,
+				NumTicks:        5,
+				TickImmediately: true,
+				Priority:        ActionPriorityDOT, // High prio
+				OnAction: func(sim *Simulation) {
+					aura.Activate(sim)
+					if aura.IsActive() {
+						aura.AddStack(sim)
+					}
+				},
+			}, raid)
+		}
 	}
 
 	if debuffs.CurseOfElements {
@@ -78,18 +82,22 @@ func applyDebuffEffects(target *Unit, targetIdx int, debuffs *proto.Debuffs, rai
 
 	if debuffs.WintersChill && targetIdx == 0 {
 		aura := WintersChillAura(target)
-		SchedulePeriodicDebuffApplication(aura, PeriodicActionOptions{
-			Period:          time.Millisecond * 1500,
-			NumTicks:        5,
-			TickImmediately: true,
-			Priority:        ActionPriorityDOT, // High prio
-			OnAction: func(sim *Simulation) {
-				aura.Activate(sim)
-				if aura.IsActive() {
-					aura.AddStack(sim)
-				}
-			},
-		}, raid)
+		if rampUp := debuffs.RampUp; rampUp != nil {
+			ScheduleExternalDebuffStacks(aura, rampUp.StartDelay, rampUp.WintersChillCasters, rampUp.WintersChillInterval, time.Millisecond*2500)
+		} else {
+			SchedulePeriodicDebuffApplication(aura, PeriodicActionOptions{
+				Period:          time.Millisecond * 1500,
+				NumTicks:        5,
+				TickImmediately: true,
+				Priority:        ActionPriorityDOT, // High prio
+				OnAction: func(sim *Simulation) {
+					aura.Activate(sim)
+					if aura.IsActive() {
+						aura.AddStack(sim)
+					}
+				},
+			}, raid)
+		}
 	}
 
 	if debuffs.Stormstrike {
@@ -124,18 +132,22 @@ func applyDebuffEffects(target *Unit, targetIdx int, debuffs *proto.Debuffs, rai
 		if debuffs.SunderArmor {
 			// Sunder Armor
 			aura := SunderArmorAura(target)
-			SchedulePeriodicDebuffApplication(aura, PeriodicActionOptions{
-				Period:          time.Millisecond * 1500,
-				NumTicks:        5,
-				TickImmediately: true,
-				Priority:        ActionPriorityDOT, // High prio so it comes before actual warrior sunders.
-				OnAction: func(sim *Simulation) {
-					aura.Activate(sim)
-					if aura.IsActive() {
-						aura.AddStack(sim)
-					}
-				},
-			}, raid)
+			if rampUp := debuffs.RampUp; rampUp != nil {
+				ScheduleExternalDebuffStacks(aura, rampUp.StartDelay, 1, rampUp.SunderArmorInterval, GCDDefault)
+			} else {
+				SchedulePeriodicDebuffApplication(aura, PeriodicActionOptions{
+					Period:          time.Millisecond * 1500,
+					NumTicks:        5,
+					TickImmediately: true,
+					Priority:        ActionPriorityDOT, // High prio so it comes before actual warrior sunders.
+					OnAction: func(sim *Simulation) {
+						aura.Activate(sim)
+						if aura.IsActive() {
+							aura.AddStack(sim)
+						}
+					},
+				}, raid)
+			}
 		}
 	}
 
@@ -190,7 +202,7 @@ func StormstrikeAura(unit *Unit) *Aura {
 	return aura
 }
 
-func ExternalIsbCaster(_ *proto.Debuffs, target *Unit) {
+func ExternalIsbCaster(debuffs *proto.Debuffs, target *Unit) {
 	isbConfig := target.Env.Raid.Parties[0].Players[0].GetCharacter().IsbConfig
 	baseStacks := int32(ISBNumStacksBase)
 	isbAura := ImprovedShadowBoltAura(target, 5, baseStacks)
@@ -212,6 +224,9 @@ func ExternalIsbCaster(_ *proto.Debuffs, target *Unit) {
 					}
 				},
 			})
+			if debuffs.RampUp != nil {
+				pa.NextActionAt += DurationFromSeconds(max(debuffs.RampUp.StartDelay, 0))
+			}
 			sim.AddPendingAction(pa)
 		},
 		OnExpire: func(aura *Aura, sim *Simulation) {
@@ -331,6 +346,37 @@ func SchedulePeriodicDebuffApplication(aura *Aura, options PeriodicActionOptions
 	}
 }
 
+// Stacks up a debuff applied by players outside the sim. The casters start after
+// startDelay seconds and each adds a stack every interval seconds, staggered evenly.
+func ScheduleExternalDebuffStacks(aura *Aura, startDelay float64, casters int32, interval float64, defaultInterval time.Duration) {
+	period := defaultInterval
+	if interval > 0 {
+		period = DurationFromSeconds(interval)
+	}
+	period /= time.Duration(max(casters, 1))
+
+	aura.OnReset = func(aura *Aura, sim *Simulation) {
+		aura.Duration = NeverExpires
+		StartDelayedAction(sim, DelayedActionOptions{
+			DoAt:     DurationFromSeconds(max(startDelay, 0)),
+			Priority: ActionPriorityDOT,
+			OnAction: func(sim *Simulation) {
+				StartPeriodicAction(sim, PeriodicActionOptions{
+					Period:   period,
+					NumTicks: int(aura.MaxStacks),
+					Priority: ActionPriorityDOT,
+					OnAction: func(sim *Simulation) {
+						aura.Activate(sim)
+						if aura.IsActive() {
+							aura.AddStack(sim)
+						}
+					},
+				})
+			},
+		})
+	}
+}
+
 const JudgementAuraTag = "Judgement"
 
 // TODO: Classic verify logic
@@ -472,10 +518,10 @@ func spellSchoolResistanceEffect(aura *Aura, school stats.SchoolIndex, amount fl
 	return aura.NewExclusiveEffect("resistance"+strconv.Itoa(int(school)), exclusive, ExclusiveEffect{
 		Priority: amount + extraPriority,
 		OnGain: func(ee *ExclusiveEffect, sim *Simulation) {
-			aura.Unit.AddResistancesDynamic(sim, -amount)
+			aura.Unit.AddStatDynamic(sim, schoolResistanceStats[school], -amount)
 		},
 		OnExpire: func(ee *ExclusiveEffect, sim *Simulation) {
-			aura.Unit.AddResistancesDynamic(sim, amount)
+			aura.Unit.AddStatDynamic(sim, schoolResistanceStats[school], amount)
 		},
 	})
 }
@@ -751,10 +797,10 @@ func ExposeWeaknessAura(target *Unit) *Aura {
 		ActionID: ActionID{SpellID: 23577},
 		Label:    "Expose Weakness",
 		Duration: time.Second * 7,
-		OnGain: func(aura *Aura, sim *Simulation)  {
+		OnGain: func(aura *Aura, sim *Simulation) {
 			target.PseudoStats.BonusRangedAttackPowerTaken += bonus
 		},
-		OnExpire: func(aura *Aura, sim *Simulation)  {
+		OnExpire: func(aura *Aura, sim *Simulation) {
 			target.PseudoStats.BonusRangedAttackPowerTaken -= bonus
 		},
 	})
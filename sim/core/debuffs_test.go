@@ -0,0 +1,104 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+func setupDebuffSim(debuffs *proto.Debuffs) *Simulation {
+	sim := NewSim(&proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{
+			RandomSeed: 100,
+		},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{
+							Name:      "Caster",
+							Class:     proto.Class_ClassShaman,
+							Consumes:  &proto.Consumes{},
+							Buffs:     &proto.IndividualBuffs{},
+							Spec:      &proto.Player_ElementalShaman{},
+							Equipment: &proto.EquipmentSpec{},
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				},
+			},
+			Debuffs: debuffs,
+		},
+		Encounter: &proto.Encounter{
+			Targets: []*proto.Target{
+				{Name: "target", Level: 63, MobType: proto.MobType_MobTypeDemon},
+			},
+			Duration: 180,
+		},
+	}, simsignals.CreateSignals())
+	sim.Reset()
+
+	return sim
+}
+
+// Runs the pending actions scheduled up to the given time.
+func runDebuffSimUntil(sim *Simulation, until time.Duration) {
+	for sim.pendingActions[len(sim.pendingActions)-1].NextActionAt <= until {
+		if finished := sim.Step(); finished {
+			return
+		}
+	}
+}
+
+func TestDebuffStacksWithoutRampUp(t *testing.T) {
+	sim := setupDebuffSim(&proto.Debuffs{SunderArmor: true})
+	sunder := sim.GetTargetUnit(0).GetAura("Sunder Armor")
+
+	runDebuffSimUntil(sim, time.Second*6)
+	if sunder.GetStacks() != 5 {
+		t.Fatalf("Expected full Sunder Armor after 6s, got %d stacks", sunder.GetStacks())
+	}
+}
+
+func TestDebuffRampUp(t *testing.T) {
+	sim := setupDebuffSim(&proto.Debuffs{
+		SunderArmor:   true,
+		WintersChill:  true,
+		ShadowWeaving: true,
+		RampUp: &proto.DebuffRampUp{
+			StartDelay:           2,
+			SunderArmorInterval:  3,
+			WintersChillCasters:  2,
+			WintersChillInterval: 3,
+		},
+	})
+	target := sim.GetTargetUnit(0)
+	sunder := target.GetAura("Sunder Armor")
+	wintersChill := target.GetAura("Winter's Chill")
+	shadowWeaving := target.GetAura("Shadow Weaving")
+
+	runDebuffSimUntil(sim, time.Millisecond*1900)
+	if sunder.IsActive() || wintersChill.IsActive() || shadowWeaving.IsActive() {
+		t.Fatalf("Debuffs shouldn't be applied before the start delay")
+	}
+
+	// 2s delay, then a Sunder every 3s, a Winter's Chill every 1.5s from two mages and
+	// a Shadow Weaving every GCD from the default single priest.
+	runDebuffSimUntil(sim, time.Millisecond*8100)
+	if sunder.GetStacks() != 2 {
+		t.Fatalf("Expected 2 Sunder Armor stacks at 8s, got %d", sunder.GetStacks())
+	}
+	if wintersChill.GetStacks() != 4 {
+		t.Fatalf("Expected 4 Winter's Chill stacks at 8s, got %d", wintersChill.GetStacks())
+	}
+	if shadowWeaving.GetStacks() != 4 {
+		t.Fatalf("Expected 4 Shadow Weaving stacks at 8s, got %d", shadowWeaving.GetStacks())
+	}
+
+	runDebuffSimUntil(sim, time.Second*30)
+	if sunder.GetStacks() != 5 || wintersChill.GetStacks() != 5 || shadowWeaving.GetStacks() != 5 {
+		t.Fatalf("Expected all debuffs to reach full stacks")
+	}
+}
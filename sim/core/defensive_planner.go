@@ -0,0 +1,229 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// Chance of death needs more iterations than DPS to settle.
+const defaultDefensivePlanIterations = 1000
+
+const defaultDefensivePlanSpikeWindow = time.Second
+
+var defaultDefensivePlanHealthThresholds = []float64{20, 35, 50}
+
+// A way of using a cooldown, as the condition of an APL action casting it.
+type defensivePlanCandidate struct {
+	description string
+	condition   *proto.APLValue
+}
+
+// Plans when a tank uses its defensive cooldowns, health potions and healthstones to
+// minimize its chance of death. For each cooldown, usage times on a grid and health
+// thresholds, with and without a boss swing landing soon, are each simmed against the
+// encounter. The best plans of all cooldowns are then simmed together.
+//
+// Plans are APL actions, which are put at the top of the tank's priority list so they
+// can be copied into the rotation.
+func PlanDefensives(request *proto.DefensivePlanRequest, signals simsignals.Signals) (result *proto.DefensivePlanResult) {
+	defer recoverRequestError(func(errorOutcome *proto.ErrorOutcome) {
+		result = &proto.DefensivePlanResult{Error: errorOutcome}
+	})
+
+	if request.Raid == nil || request.Encounter == nil {
+		return &proto.DefensivePlanResult{Error: &proto.ErrorOutcome{Message: "missing raid or encounter"}}
+	}
+
+	tankRef := request.Tank
+	if tankRef == nil && len(request.Raid.Tanks) > 0 {
+		tankRef = request.Raid.Tanks[0]
+	}
+	if tankRef == nil || tankRef.Type != proto.UnitReference_Player {
+		return &proto.DefensivePlanResult{Error: &proto.ErrorOutcome{Message: "no tank to plan for"}}
+	}
+	partyIdx, playerIdx, errorOutcome := raidPlayerIndices(request.Raid, tankRef.Index)
+	if errorOutcome != nil {
+		return &proto.DefensivePlanResult{Error: errorOutcome}
+	}
+	if request.Raid.Parties[partyIdx].Players[playerIdx].HealingModel == nil {
+		return &proto.DefensivePlanResult{Error: &proto.ErrorOutcome{Message: "the tank needs a healing model to have a chance of death"}}
+	}
+
+	simOptions := requestSimOptions(request.SimOptions, defaultDefensivePlanIterations)
+
+	// Set up the encounter once, without the tank's rotation so none of its cooldowns
+	// are taken by APL actions, to find the cooldowns and the boss attacking the tank.
+	setupRaid := googleProto.Clone(request.Raid).(*proto.Raid)
+	setupRaid.Parties[partyIdx].Players[playerIdx].Rotation = nil
+	env, _, _ := NewEnvironment(setupRaid, request.Encounter, false)
+	tank := env.GetUnit(tankRef, nil)
+	if tank == nil {
+		return &proto.DefensivePlanResult{Error: &proto.ErrorOutcome{Message: fmt.Sprintf("no player at raid index %d", tankRef.Index)}}
+	}
+
+	var attackerRef *proto.UnitReference
+	for _, target := range env.Encounter.TargetUnits {
+		if target.CurrentTarget == tank {
+			attackerRef = &proto.UnitReference{Type: proto.UnitReference_Target, Index: target.Index}
+			break
+		}
+	}
+	if attackerRef == nil {
+		return &proto.DefensivePlanResult{Error: &proto.ErrorOutcome{Message: "the tank isn't tanking a target"}}
+	}
+
+	cooldowns := request.Cooldowns
+	if len(cooldowns) == 0 {
+		for _, mcd := range env.Raid.GetPlayerFromUnit(tank).GetCharacter().initialMajorCooldowns {
+			if mcd.Type.Matches(CooldownTypeSurvival) {
+				cooldowns = append(cooldowns, mcd.Spell.ActionID.ToProto())
+			}
+		}
+	}
+	if len(cooldowns) == 0 {
+		return &proto.DefensivePlanResult{Error: &proto.ErrorOutcome{Message: "the tank has no survival cooldowns to plan"}}
+	}
+
+	timeStep := DurationFromSeconds(request.TimeStepSeconds)
+	if timeStep <= 0 {
+		timeStep = env.BaseDuration / 10
+	}
+	healthThresholds := request.HealthThresholds
+	if len(healthThresholds) == 0 {
+		healthThresholds = defaultDefensivePlanHealthThresholds
+	}
+	spikeWindow := DurationFromSeconds(request.SpikeWindowSeconds)
+	if spikeWindow <= 0 {
+		spikeWindow = defaultDefensivePlanSpikeWindow
+	}
+	candidates := newDefensivePlanCandidates(env.BaseDuration, timeStep, healthThresholds, spikeWindow, attackerRef)
+
+	chanceOfDeath := func(actions []*proto.APLListItem) float64 {
+		raid := googleProto.Clone(request.Raid).(*proto.Raid)
+		player := raid.Parties[partyIdx].Players[playerIdx]
+		if player.Rotation == nil {
+			player.Rotation = &proto.APLRotation{Type: proto.APLRotation_TypeAPL}
+		}
+		player.Rotation.PriorityList = append(slices.Clone(actions), player.Rotation.PriorityList...)
+
+		simResult := RunSim(&proto.RaidSimRequest{
+			Raid:       raid,
+			Encounter:  request.Encounter,
+			SimOptions: simOptions,
+		}, nil, signals)
+		if simResult.Error != nil {
+			panic(simResult.Error.Message)
+		}
+		return simResult.RaidMetrics.Parties[partyIdx].Players[playerIdx].ChanceOfDeath
+	}
+
+	result = &proto.DefensivePlanResult{
+		BaseChanceOfDeath: chanceOfDeath(nil),
+	}
+	newPlan := func(description string, actions []*proto.APLListItem) *proto.DefensivePlan {
+		if signals.Abort.IsTriggered() {
+			panic("aborted")
+		}
+		plan := &proto.DefensivePlan{
+			Description:   description,
+			Actions:       actions,
+			ChanceOfDeath: chanceOfDeath(actions),
+		}
+		plan.ChanceOfDeathReduction = result.BaseChanceOfDeath - plan.ChanceOfDeath
+		return plan
+	}
+
+	var bestDescriptions []string
+	var bestActions []*proto.APLListItem
+	for _, cooldown := range cooldowns {
+		cooldownPlans := &proto.CooldownPlans{Cooldown: cooldown}
+		for _, candidate := range candidates {
+			action := &proto.APLListItem{
+				Notes: candidate.description,
+				Action: &proto.APLAction{
+					Condition: candidate.condition,
+					Action:    &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{SpellId: cooldown}},
+				},
+			}
+			cooldownPlans.Plans = append(cooldownPlans.Plans, newPlan(candidate.description, []*proto.APLListItem{action}))
+		}
+		slices.SortStableFunc(cooldownPlans.Plans, func(a, b *proto.DefensivePlan) int {
+			if a.ChanceOfDeath < b.ChanceOfDeath {
+				return -1
+			} else if a.ChanceOfDeath > b.ChanceOfDeath {
+				return 1
+			}
+			return 0
+		})
+		result.Cooldowns = append(result.Cooldowns, cooldownPlans)
+
+		if best := cooldownPlans.Plans[0]; best.ChanceOfDeathReduction > 0 {
+			bestDescriptions = append(bestDescriptions, fmt.Sprintf("%s: %s", ProtoToActionID(cooldown), best.Description))
+			bestActions = append(bestActions, best.Actions...)
+		}
+	}
+
+	if len(bestActions) == 0 {
+		result.BestPlan = &proto.DefensivePlan{
+			Description:   "Keep the current setup",
+			ChanceOfDeath: result.BaseChanceOfDeath,
+		}
+	} else {
+		result.BestPlan = newPlan(strings.Join(bestDescriptions, "; "), bestActions)
+	}
+
+	return result
+}
+
+// Returns the ways of using a cooldown worth simming: first use at each step of the
+// encounter, and below each health threshold, with and without a spike incoming.
+func newDefensivePlanCandidates(duration time.Duration, timeStep time.Duration, healthThresholds []float64, spikeWindow time.Duration, attackerRef *proto.UnitReference) []defensivePlanCandidate {
+	var candidates []defensivePlanCandidate
+
+	for at := time.Duration(0); at < duration; at += timeStep {
+		candidates = append(candidates, defensivePlanCandidate{
+			description: fmt.Sprintf("First use at %0.0fs", at.Seconds()),
+			condition: aplCompare(proto.APLValueCompare_OpGe,
+				&proto.APLValue{Value: &proto.APLValue_CurrentTime{CurrentTime: &proto.APLValueCurrentTime{}}},
+				aplConst(fmt.Sprintf("%0.3fs", at.Seconds()))),
+		})
+	}
+
+	for _, threshold := range healthThresholds {
+		belowThreshold := aplCompare(proto.APLValueCompare_OpLt,
+			&proto.APLValue{Value: &proto.APLValue_CurrentHealthPercent{CurrentHealthPercent: &proto.APLValueCurrentHealthPercent{}}},
+			aplConst(fmt.Sprintf("%g%%", threshold)))
+		spikeIncoming := aplCompare(proto.APLValueCompare_OpLe,
+			&proto.APLValue{Value: &proto.APLValue_AutoTimeToNext{AutoTimeToNext: &proto.APLValueAutoTimeToNext{
+				AutoType:   proto.APLValueAutoTimeToNext_Melee,
+				SourceUnit: attackerRef,
+			}}},
+			aplConst(fmt.Sprintf("%0.3fs", spikeWindow.Seconds())))
+
+		candidates = append(candidates, defensivePlanCandidate{
+			description: fmt.Sprintf("Health below %g%%", threshold),
+			condition:   belowThreshold,
+		}, defensivePlanCandidate{
+			description: fmt.Sprintf("Health below %g%% with a boss swing within %0.1fs", threshold, spikeWindow.Seconds()),
+			condition: &proto.APLValue{Value: &proto.APLValue_And{And: &proto.APLValueAnd{
+				Vals: []*proto.APLValue{belowThreshold, spikeIncoming},
+			}}},
+		})
+	}
+
+	return candidates
+}
+
+func aplConst(val string) *proto.APLValue {
+	return &proto.APLValue{Value: &proto.APLValue_Const{Const: &proto.APLValueConst{Val: val}}}
+}
+
+func aplCompare(op proto.APLValueCompare_ComparisonOperator, lhs *proto.APLValue, rhs *proto.APLValue) *proto.APLValue {
+	return &proto.APLValue{Value: &proto.APLValue_Cmp{Cmp: &proto.APLValueCompare{Op: op, Lhs: lhs, Rhs: rhs}}}
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+func TestPlanDefensivesHealthPotion(t *testing.T) {
+	result := PlanDefensives(&proto.DefensivePlanRequest{
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{
+							Name:         "Tank",
+							Class:        proto.Class_ClassShaman,
+							Consumes:     &proto.Consumes{DefaultPotion: proto.Potions_MajorHealingPotion},
+							Buffs:        &proto.IndividualBuffs{},
+							Spec:         &proto.Player_ElementalShaman{},
+							Equipment:    &proto.EquipmentSpec{},
+							HealingModel: &proto.HealingModel{Hps: 300, CadenceSeconds: 2},
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				},
+			},
+			Tanks: []*proto.UnitReference{
+				{Type: proto.UnitReference_Player, Index: 0},
+			},
+		},
+		Encounter: &proto.Encounter{
+			Targets: []*proto.Target{
+				{Level: 63, MobType: proto.MobType_MobTypeDemon, SwingSpeed: 2, MinBaseDamage: 500, DamageSpread: 0.3333},
+			},
+			Duration: 60,
+		},
+		SimOptions: &proto.SimOptions{
+			Iterations: 100,
+			RandomSeed: 101,
+			IsTest:     true,
+		},
+		TimeStepSeconds: 20,
+	}, simsignals.CreateSignals())
+	if result.Error != nil {
+		t.Fatalf("Planner failed: %s", result.Error.Message)
+	}
+
+	if len(result.Cooldowns) != 1 || result.Cooldowns[0].Cooldown.GetItemId() != 13446 {
+		t.Fatalf("Expected only the health potion to be planned, got %v", result.Cooldowns)
+	}
+	// 3 usage times, and 3 health thresholds with and without a spike incoming.
+	plans := result.Cooldowns[0].Plans
+	if len(plans) != 9 {
+		t.Fatalf("Expected 9 plans, got %d", len(plans))
+	}
+	for i := 1; i < len(plans); i++ {
+		if plans[i].ChanceOfDeath < plans[i-1].ChanceOfDeath {
+			t.Fatalf("Expected the best plan first")
+		}
+	}
+
+	if result.BaseChanceOfDeath <= 0 {
+		t.Fatalf("Expected the tank to die without a potion")
+	}
+	if result.BestPlan.ChanceOfDeath >= result.BaseChanceOfDeath || len(result.BestPlan.Actions) != 1 {
+		t.Fatalf("Expected the potion to help, got %0.2f vs %0.2f", result.BestPlan.ChanceOfDeath, result.BaseChanceOfDeath)
+	}
+}
+
+func TestPlanDefensivesRequiresHealingModel(t *testing.T) {
+	result := PlanDefensives(&proto.DefensivePlanRequest{
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{Name: "Tank", Class: proto.Class_ClassShaman, Spec: &proto.Player_ElementalShaman{}},
+					},
+				},
+			},
+			Tanks: []*proto.UnitReference{
+				{Type: proto.UnitReference_Player, Index: 0},
+			},
+		},
+		Encounter: &proto.Encounter{Duration: 60},
+	}, simsignals.CreateSignals())
+	if result.Error == nil {
+		t.Fatalf("Expected an error without a healing model")
+	}
+}
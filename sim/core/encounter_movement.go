@@ -0,0 +1,115 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+// An encounter mechanic which periodically forces a group of players to move.
+type MovementPhase struct {
+	Group      proto.MovementPhase_Group
+	FirstAt    time.Duration
+	Interval   time.Duration // 0 if the movement only happens once.
+	Duration   time.Duration
+	OutOfRange bool
+}
+
+func newMovementPhases(configs []*proto.MovementPhase) []MovementPhase {
+	var phases []MovementPhase
+	for _, config := range configs {
+		if config.Duration <= 0 {
+			continue
+		}
+		phases = append(phases, MovementPhase{
+			Group:      config.Group,
+			FirstAt:    max(0, DurationFromSeconds(config.FirstAt)),
+			Interval:   max(0, DurationFromSeconds(config.Interval)),
+			Duration:   DurationFromSeconds(config.Duration),
+			OutOfRange: config.OutOfRange,
+		})
+	}
+	return phases
+}
+
+// Whether the phase applies to the given unit. Units that auto attack in melee range are
+// considered melee, everyone else is ranged.
+func (phase *MovementPhase) Affects(unit *Unit) bool {
+	switch phase.Group {
+	case proto.MovementPhase_Melee:
+		return unit.isMelee()
+	case proto.MovementPhase_Ranged:
+		return !unit.isMelee()
+	default:
+		return true
+	}
+}
+
+func (unit *Unit) isMelee() bool {
+	return unit.AutoAttacks.AutoSwingMelee && unit.StartDistanceFromTarget <= MaxMeleeAttackDistance
+}
+
+// Returns the time of the first movement of this phase at or after the given time,
+// or NeverExpires if there is none.
+func (phase *MovementPhase) nextAt(at time.Duration) time.Duration {
+	if at <= phase.FirstAt {
+		return phase.FirstAt
+	}
+	if phase.Interval == 0 {
+		return NeverExpires
+	}
+	numIntervals := (at - phase.FirstAt + phase.Interval - 1) / phase.Interval
+	return phase.FirstAt + numIntervals*phase.Interval
+}
+
+func (encounter *Encounter) scheduleMovementPhases(sim *Simulation, players []*Unit) {
+	for i := range encounter.MovementPhases {
+		phase := &encounter.MovementPhases[i]
+
+		var affected []*Unit
+		for _, unit := range players {
+			if unit.enabled && phase.Affects(unit) {
+				affected = append(affected, unit)
+			}
+		}
+		if len(affected) == 0 {
+			continue
+		}
+
+		var doMovement func(sim *Simulation)
+		doMovement = func(sim *Simulation) {
+			for _, unit := range affected {
+				unit.ForceMovement(sim, phase.Duration, phase.OutOfRange)
+			}
+			if phase.Interval > 0 {
+				StartDelayedAction(sim, DelayedActionOptions{
+					DoAt:     sim.CurrentTime + phase.Interval,
+					OnAction: doMovement,
+				})
+			}
+		}
+
+		StartDelayedAction(sim, DelayedActionOptions{
+			DoAt:     phase.FirstAt,
+			OnAction: doMovement,
+		})
+	}
+}
+
+// Returns the time until the unit is next forced to move by the encounter, 0 if it is
+// moving right now, or the remaining fight duration if it won't need to move again.
+func (unit *Unit) TimeToNextMovement(sim *Simulation) time.Duration {
+	if unit.ForcedMovementEndsAt() > sim.CurrentTime {
+		return 0
+	}
+
+	nextAt := NeverExpires
+	for i := range unit.Env.Encounter.MovementPhases {
+		phase := &unit.Env.Encounter.MovementPhases[i]
+		if phase.Affects(unit) {
+			nextAt = min(nextAt, phase.nextAt(sim.CurrentTime))
+		}
+	}
+
+	return min(nextAt-sim.CurrentTime, sim.GetRemainingDuration())
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+func TestMovementPhaseNextAt(t *testing.T) {
+	phases := newMovementPhases([]*proto.MovementPhase{
+		{FirstAt: 10, Interval: 30, Duration: 3},
+		{FirstAt: 20, Duration: 5},
+		{FirstAt: 5}, // No duration, ignored.
+	})
+	if len(phases) != 2 {
+		t.Fatalf("Expected 2 movement phases, got %d", len(phases))
+	}
+
+	for _, tc := range []struct {
+		phase    int
+		at       time.Duration
+		expected time.Duration
+	}{
+		{0, 0, time.Second * 10},
+		{0, time.Second * 10, time.Second * 10},
+		{0, time.Second * 11, time.Second * 40},
+		{0, time.Second * 70, time.Second * 70},
+		{1, time.Second * 15, time.Second * 20},
+		{1, time.Second * 21, NeverExpires},
+	} {
+		if nextAt := phases[tc.phase].nextAt(tc.at); nextAt != tc.expected {
+			t.Errorf("Phase %d at %s: expected next movement at %s, got %s", tc.phase, tc.at, tc.expected, nextAt)
+		}
+	}
+}
+
+func TestMovementPhaseAffects(t *testing.T) {
+	melee := &Unit{StartDistanceFromTarget: 5, AutoAttacks: AutoAttacks{AutoSwingMelee: true}}
+	ranged := &Unit{StartDistanceFromTarget: 30}
+
+	meleePhase := MovementPhase{Group: proto.MovementPhase_Melee}
+	if !meleePhase.Affects(melee) || meleePhase.Affects(ranged) {
+		t.Errorf("Melee phase should only affect melee")
+	}
+	rangedPhase := MovementPhase{Group: proto.MovementPhase_Ranged}
+	if rangedPhase.Affects(melee) || !rangedPhase.Affects(ranged) {
+		t.Errorf("Ranged phase should only affect ranged")
+	}
+	allPhase := MovementPhase{Group: proto.MovementPhase_AllPlayers}
+	if !allPhase.Affects(melee) || !allPhase.Affects(ranged) {
+		t.Errorf("All players phase should affect everyone")
+	}
+}
@@ -107,6 +107,9 @@ func (env *Environment) construct(raidProto *proto.Raid, encounterProto *proto.E
 				}
 			}
 		}
+		if env.Encounter.UseThreatTable || env.Encounter.ThreatCap != nil {
+			target.ThreatTable = newThreatTable(&target.Unit, env.Encounter.UseThreatTable, env.Encounter.ThreatCap)
+		}
 	}
 
 	env.State = Constructed
@@ -179,6 +182,7 @@ func (env *Environment) finalize(raidProto *proto.Raid, _ *proto.Encounter, raid
 	}
 
 	env.setupAttackTables()
+	env.setupDebuffSlots()
 
 	for _, finalizeEffect := range env.postFinalizeEffects {
 		finalizeEffect()
@@ -266,6 +270,17 @@ func (env *Environment) reset(sim *Simulation) {
 	}
 
 	env.Raid.reset(sim)
+
+	for _, target := range env.Encounter.TargetUnits {
+		if target.ThreatTable != nil {
+			target.ThreatTable.reset()
+		}
+	}
+	for _, target := range env.Encounter.Targets {
+		target.scheduleThreatWipes(sim)
+	}
+
+	env.Encounter.scheduleMovementPhases(sim, env.Raid.AllPlayerUnits)
 }
 
 // The maximum possible duration for any iteration.
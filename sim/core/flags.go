@@ -154,6 +154,19 @@ func (ho HitOutcome) PartialResistString() string {
 	}
 }
 
+// Returns the amount of the spell that was resisted, in quarters.
+func (ho HitOutcome) PartialResistIndex() int {
+	if ho.Matches(OutcomePartial1_4) {
+		return 1
+	} else if ho.Matches(OutcomePartial2_4) {
+		return 2
+	} else if ho.Matches(OutcomePartial3_4) {
+		return 3
+	} else {
+		return 0
+	}
+}
+
 // Other flags
 type SpellFlag uint64
 
@@ -36,6 +36,23 @@ func (unit *Unit) newHardcastAction(sim *Simulation) {
 	sim.AddPendingAction(unit.hardcastAction)
 }
 
+// Interrupts the current hardcast, if any, without completing it.
+func (unit *Unit) CancelHardcast(sim *Simulation) {
+	if !unit.IsCasting(sim) {
+		return
+	}
+
+	if sim.Log != nil {
+		unit.Log(sim, "Cast %s interrupted", unit.Hardcast.ActionID)
+	}
+
+	unit.Hardcast = Hardcast{Expires: startingCDTime}
+	if unit.hardcastAction != nil && !unit.hardcastAction.consumed {
+		unit.hardcastAction.Cancel(sim)
+	}
+	unit.SetGCDTimer(sim, sim.CurrentTime)
+}
+
 func (unit *Unit) NextGCDAt() time.Duration {
 	return unit.gcdAction.NextActionAt
 }
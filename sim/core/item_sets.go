@@ -69,6 +69,17 @@ func NewItemSet(set ItemSet) *ItemSet {
 	return &set
 }
 
+// Whether a set with the given name has a registered bonus for the given number of pieces.
+func HasItemSetBonus(setName string, numItems int32) bool {
+	for _, set := range sets {
+		if set.Name == setName || (set.AlternativeName != "" && set.AlternativeName == setName) {
+			_, ok := set.Bonuses[numItems]
+			return ok
+		}
+	}
+	return false
+}
+
 func (character *Character) HasSetBonus(set *ItemSet, numItems int32) bool {
 	if character.Env != nil && character.Env.IsFinalized() {
 		panic("HasSetBonus is very slow and should never be called after finalization. Try caching the value during construction instead!")
@@ -0,0 +1,302 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+const defaultManaPlanIterations = 1000
+
+var defaultManaPlanThresholds = []float64{10, 25, 40}
+
+// A change to the caster's rotation, e.g. using a potion on a schedule.
+type manaPlanCandidate struct {
+	description string
+	apply       func(rotation *proto.APLRotation)
+}
+
+// Plans when a caster uses mana potions, runes, Evocation, Innervate and Mana Tide, and
+// when to downrank instead, to maximize its DPS over the encounter. For each cooldown,
+// schedules starting at times on a grid and mana thresholds are each simmed, as are
+// lower ranks of each downranked spell below each mana threshold. The best plans of
+// all cooldowns and spells are then simmed together.
+func PlanMana(request *proto.ManaPlanRequest, signals simsignals.Signals) (result *proto.ManaPlanResult) {
+	defer recoverRequestError(func(errorOutcome *proto.ErrorOutcome) {
+		result = &proto.ManaPlanResult{Error: errorOutcome}
+	})
+
+	if request.Raid == nil || request.Encounter == nil {
+		return &proto.ManaPlanResult{Error: &proto.ErrorOutcome{Message: "missing raid or encounter"}}
+	}
+
+	casterRef := request.Caster
+	if casterRef == nil {
+		casterRef = &proto.UnitReference{Type: proto.UnitReference_Player, Index: 0}
+	}
+	if casterRef.Type != proto.UnitReference_Player {
+		return &proto.ManaPlanResult{Error: &proto.ErrorOutcome{Message: "the caster must be a player"}}
+	}
+	partyIdx, playerIdx, errorOutcome := raidPlayerIndices(request.Raid, casterRef.Index)
+	if errorOutcome != nil {
+		return &proto.ManaPlanResult{Error: errorOutcome}
+	}
+	if request.Raid.Parties[partyIdx].Players[playerIdx].Rotation == nil {
+		return &proto.ManaPlanResult{Error: &proto.ErrorOutcome{Message: "the caster has no APL rotation"}}
+	}
+
+	simOptions := requestSimOptions(request.SimOptions, defaultManaPlanIterations)
+
+	// Set up the encounter once, without the caster's rotation so none of its
+	// cooldowns are taken by APL actions, to find the cooldowns and spell ranks.
+	setupRaid := googleProto.Clone(request.Raid).(*proto.Raid)
+	setupRaid.Parties[partyIdx].Players[playerIdx].Rotation = nil
+	env, _, _ := NewEnvironment(setupRaid, request.Encounter, false)
+	casterUnit := env.GetUnit(casterRef, nil)
+	if casterUnit == nil {
+		return &proto.ManaPlanResult{Error: &proto.ErrorOutcome{Message: fmt.Sprintf("no player at raid index %d", casterRef.Index)}}
+	}
+	caster := env.Raid.GetPlayerFromUnit(casterUnit).GetCharacter()
+	if !caster.HasManaBar() {
+		return &proto.ManaPlanResult{Error: &proto.ErrorOutcome{Message: "the caster doesn't use mana"}}
+	}
+
+	cooldowns := request.Cooldowns
+	if len(cooldowns) == 0 {
+		for _, mcd := range caster.initialMajorCooldowns {
+			if mcd.Type.Matches(CooldownTypeMana) {
+				cooldowns = append(cooldowns, mcd.Spell.ActionID.ToProto())
+			}
+		}
+	}
+	if len(cooldowns) == 0 && len(request.DownrankSpells) == 0 {
+		return &proto.ManaPlanResult{Error: &proto.ErrorOutcome{Message: "the caster has no mana cooldowns or spells to downrank"}}
+	}
+
+	timeStep := DurationFromSeconds(request.TimeStepSeconds)
+	if timeStep <= 0 {
+		timeStep = env.BaseDuration / 10
+	}
+	thresholds := request.ManaThresholds
+	if len(thresholds) == 0 {
+		thresholds = defaultManaPlanThresholds
+	}
+
+	baseRotation := request.Raid.Parties[partyIdx].Players[playerIdx].Rotation
+	runPlan := func(description string, apply func(rotation *proto.APLRotation)) *proto.ManaPlan {
+		if signals.Abort.IsTriggered() {
+			panic("aborted")
+		}
+
+		raid := googleProto.Clone(request.Raid).(*proto.Raid)
+		player := raid.Parties[partyIdx].Players[playerIdx]
+		if apply != nil {
+			apply(player.Rotation)
+		}
+
+		simResult := RunSim(&proto.RaidSimRequest{
+			Raid:       raid,
+			Encounter:  request.Encounter,
+			SimOptions: simOptions,
+		}, nil, signals)
+		if simResult.Error != nil {
+			panic(simResult.Error.Message)
+		}
+		metrics := simResult.RaidMetrics.Parties[partyIdx].Players[playerIdx]
+		return &proto.ManaPlan{
+			Description:   description,
+			Rotation:      player.Rotation,
+			Dps:           metrics.Dps.Avg,
+			SecondsOomAvg: metrics.SecondsOomAvg,
+		}
+	}
+
+	base := runPlan("", nil)
+	result = &proto.ManaPlanResult{
+		BaseDps:           base.Dps,
+		BaseSecondsOomAvg: base.SecondsOomAvg,
+	}
+
+	var bestDescriptions []string
+	var bestApplies []func(rotation *proto.APLRotation)
+	addGroup := func(action *proto.ActionID, downrank bool, candidates []manaPlanCandidate) {
+		group := &proto.ManaPlanGroup{Action: action, Downrank: downrank}
+		applies := make(map[*proto.ManaPlan]func(rotation *proto.APLRotation), len(candidates))
+		for _, candidate := range candidates {
+			plan := runPlan(candidate.description, candidate.apply)
+			plan.DpsGain = plan.Dps - base.Dps
+			group.Plans = append(group.Plans, plan)
+			applies[plan] = candidate.apply
+		}
+		slices.SortStableFunc(group.Plans, func(a, b *proto.ManaPlan) int {
+			if a.Dps > b.Dps {
+				return -1
+			} else if a.Dps < b.Dps {
+				return 1
+			}
+			return 0
+		})
+		result.Groups = append(result.Groups, group)
+
+		if len(group.Plans) > 0 && group.Plans[0].DpsGain > 0 {
+			bestDescriptions = append(bestDescriptions, group.Plans[0].Description)
+			bestApplies = append(bestApplies, applies[group.Plans[0]])
+		}
+	}
+
+	for _, cooldown := range cooldowns {
+		mcd := caster.GetInitialMajorCooldown(ProtoToActionID(cooldown))
+		if mcd.Spell == nil {
+			return &proto.ManaPlanResult{Error: &proto.ErrorOutcome{Message: fmt.Sprintf("the caster has no cooldown %s", ProtoToActionID(cooldown))}}
+		}
+		cooldownDuration := max(mcd.Spell.CD.Duration, mcd.Spell.SharedCD.Duration)
+		addGroup(cooldown, false, newManaCooldownCandidates(cooldown, cooldownDuration, env.BaseDuration, timeStep, thresholds))
+	}
+
+	for _, spellID := range request.DownrankSpells {
+		candidates, err := newDownrankCandidates(caster, baseRotation, ProtoToActionID(spellID), thresholds)
+		if err != nil {
+			return &proto.ManaPlanResult{Error: &proto.ErrorOutcome{Message: err.Error()}}
+		}
+		addGroup(spellID, true, candidates)
+	}
+
+	if len(bestApplies) == 0 {
+		result.BestPlan = base
+		result.BestPlan.Description = "Keep the current rotation"
+	} else {
+		result.BestPlan = runPlan(strings.Join(bestDescriptions, "; "), func(rotation *proto.APLRotation) {
+			for _, apply := range bestApplies {
+				apply(rotation)
+			}
+		})
+		result.BestPlan.DpsGain = result.BestPlan.Dps - base.Dps
+	}
+
+	return result
+}
+
+// Returns the ways of using a mana cooldown worth simming: on cooldown starting at
+// each step of the encounter, and below each mana threshold.
+func newManaCooldownCandidates(cooldown *proto.ActionID, cooldownDuration time.Duration, duration time.Duration, timeStep time.Duration, thresholds []float64) []manaPlanCandidate {
+	var candidates []manaPlanCandidate
+	castCooldown := &proto.APLAction{
+		Action: &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{SpellId: cooldown}},
+	}
+	actionID := ProtoToActionID(cooldown)
+
+	for at := time.Duration(0); at < duration; at += timeStep {
+		schedule := manaSchedule(at, cooldownDuration, duration)
+		candidates = append(candidates, manaPlanCandidate{
+			description: fmt.Sprintf("%s at %s", actionID, schedule),
+			apply: func(rotation *proto.APLRotation) {
+				prependAPLAction(rotation, &proto.APLAction{
+					Action: &proto.APLAction_Schedule{Schedule: &proto.APLActionSchedule{
+						Schedule:    schedule,
+						InnerAction: castCooldown,
+					}},
+				})
+			},
+		})
+	}
+
+	for _, threshold := range thresholds {
+		condition := aplCompare(proto.APLValueCompare_OpLt,
+			&proto.APLValue{Value: &proto.APLValue_CurrentManaPercent{CurrentManaPercent: &proto.APLValueCurrentManaPercent{}}},
+			aplConst(fmt.Sprintf("%g%%", threshold)))
+		candidates = append(candidates, manaPlanCandidate{
+			description: fmt.Sprintf("%s below %g%% mana", actionID, threshold),
+			apply: func(rotation *proto.APLRotation) {
+				prependAPLAction(rotation, &proto.APLAction{
+					Condition: condition,
+					Action:    castCooldown.Action,
+				})
+			},
+		})
+	}
+
+	return candidates
+}
+
+// Returns the times to use a cooldown at, starting at the given time and then every
+// time it's ready, in the format of APLActionSchedule.
+func manaSchedule(start time.Duration, cooldownDuration time.Duration, duration time.Duration) string {
+	var timings []string
+	for at := start; at < duration; at += cooldownDuration {
+		timings = append(timings, fmt.Sprintf("%gs", at.Seconds()))
+		if cooldownDuration <= 0 {
+			break
+		}
+	}
+	return strings.Join(timings, ", ")
+}
+
+// Returns the plans casting each lower rank of the spell below each mana threshold,
+// instead of the spell, wherever the rotation casts it.
+func newDownrankCandidates(caster *Character, rotation *proto.APLRotation, spellID ActionID, thresholds []float64) ([]manaPlanCandidate, error) {
+	spell := caster.GetSpell(spellID)
+	if spell == nil || spell.SpellCode == 0 || spell.Rank == 0 {
+		return nil, fmt.Errorf("the caster has no ranks of %s", spellID)
+	}
+	if !slices.ContainsFunc(rotation.PriorityList, func(item *proto.APLListItem) bool {
+		return castsSpell(item.Action, spellID)
+	}) {
+		return nil, fmt.Errorf("the caster's priority list doesn't cast %s", spellID)
+	}
+
+	var candidates []manaPlanCandidate
+	for _, rankSpell := range caster.Spellbook {
+		if rankSpell.SpellCode != spell.SpellCode || rankSpell.Rank == 0 || rankSpell.Rank >= spell.Rank {
+			continue
+		}
+		rankID := rankSpell.ActionID.ToProto()
+		rankID.Rank = int32(rankSpell.Rank)
+		for _, threshold := range thresholds {
+			condition := aplCompare(proto.APLValueCompare_OpLt,
+				&proto.APLValue{Value: &proto.APLValue_CurrentManaPercent{CurrentManaPercent: &proto.APLValueCurrentManaPercent{}}},
+				aplConst(fmt.Sprintf("%g%%", threshold)))
+			candidates = append(candidates, manaPlanCandidate{
+				description: fmt.Sprintf("Rank %d of %s below %g%% mana", rankSpell.Rank, spellID, threshold),
+				apply: func(rotation *proto.APLRotation) {
+					insertDownrank(rotation, spellID, rankID, condition)
+				},
+			})
+		}
+	}
+	return candidates, nil
+}
+
+// Puts a cast of the lower rank before each item of the priority list casting the
+// spell, with the same condition and the mana condition.
+func insertDownrank(rotation *proto.APLRotation, spellID ActionID, rankID *proto.ActionID, manaCondition *proto.APLValue) {
+	var priorityList []*proto.APLListItem
+	for _, item := range rotation.PriorityList {
+		if castsSpell(item.Action, spellID) {
+			downrank := googleProto.Clone(item).(*proto.APLListItem)
+			downrank.Action.Condition = manaCondition
+			if item.Action.Condition != nil {
+				downrank.Action.Condition = &proto.APLValue{Value: &proto.APLValue_And{And: &proto.APLValueAnd{
+					Vals: []*proto.APLValue{item.Action.Condition, manaCondition},
+				}}}
+			}
+			downrank.Action.GetCastSpell().SpellId = rankID
+			priorityList = append(priorityList, downrank)
+		}
+		priorityList = append(priorityList, item)
+	}
+	rotation.PriorityList = priorityList
+}
+
+func castsSpell(action *proto.APLAction, spellID ActionID) bool {
+	castSpell := action.GetCastSpell()
+	return castSpell != nil && ProtoToActionID(castSpell.SpellId).SameAction(spellID)
+}
+
+func prependAPLAction(rotation *proto.APLRotation, action *proto.APLAction) {
+	rotation.PriorityList = append([]*proto.APLListItem{{Action: action}}, rotation.PriorityList...)
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+func TestManaSchedule(t *testing.T) {
+	if schedule := manaSchedule(time.Second*30, time.Minute*2, time.Minute*5); schedule != "30s, 150s, 270s" {
+		t.Fatalf("Unexpected schedule: %s", schedule)
+	}
+	if schedule := manaSchedule(time.Second*30, 0, time.Minute*5); schedule != "30s" {
+		t.Fatalf("Expected a single use without a cooldown, got %s", schedule)
+	}
+}
+
+func TestInsertDownrank(t *testing.T) {
+	maxRank := ActionID{SpellID: 10151}
+	castSpell := func(spellID ActionID) *proto.APLAction {
+		return &proto.APLAction{Action: &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{SpellId: spellID.ToProto()}}}
+	}
+	rotation := &proto.APLRotation{
+		PriorityList: []*proto.APLListItem{
+			{Action: castSpell(ActionID{SpellID: 12051})},
+			{Action: castSpell(maxRank)},
+		},
+	}
+	manaCondition := aplConst("true")
+
+	insertDownrank(rotation, maxRank, ActionID{SpellID: 10148}.ToProto(), manaCondition)
+
+	if len(rotation.PriorityList) != 3 {
+		t.Fatalf("Expected the downrank to be inserted, got %d items", len(rotation.PriorityList))
+	}
+	downrank := rotation.PriorityList[1].Action
+	if downrank.GetCastSpell().SpellId.GetSpellId() != 10148 || downrank.Condition != manaCondition {
+		t.Fatalf("Expected the lower rank below the mana threshold before the max rank, got %v", downrank)
+	}
+	if rotation.PriorityList[2].Action.GetCastSpell().SpellId.GetSpellId() != 10151 {
+		t.Fatalf("Expected the max rank to be kept")
+	}
+}
+
+func TestManaCooldownCandidates(t *testing.T) {
+	potion := ActionID{ItemID: 13444}.ToProto()
+	candidates := newManaCooldownCandidates(potion, time.Minute*2, time.Minute*3, time.Minute, []float64{10, 25, 40})
+
+	// 3 schedules, and 3 mana thresholds.
+	if len(candidates) != 6 {
+		t.Fatalf("Expected 6 candidates, got %d", len(candidates))
+	}
+
+	rotation := &proto.APLRotation{PriorityList: []*proto.APLListItem{{}}}
+	candidates[1].apply(rotation)
+	schedule := rotation.PriorityList[0].Action.GetSchedule()
+	if len(rotation.PriorityList) != 2 || schedule == nil || schedule.Schedule != "60s" {
+		t.Fatalf("Expected the potion to be scheduled at the top of the priority list, got %v", rotation.PriorityList[0])
+	}
+}
+
+func TestPlanManaRequiresManaBar(t *testing.T) {
+	result := PlanMana(&proto.ManaPlanRequest{
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{
+							Name:      "Caster",
+							Class:     proto.Class_ClassShaman,
+							Consumes:  &proto.Consumes{},
+							Buffs:     &proto.IndividualBuffs{},
+							Spec:      &proto.Player_ElementalShaman{},
+							Equipment: &proto.EquipmentSpec{},
+							Rotation:  &proto.APLRotation{Type: proto.APLRotation_TypeAPL},
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				},
+			},
+		},
+		Encounter: &proto.Encounter{
+			Targets: []*proto.Target{
+				{Name: "target", Level: 63, MobType: proto.MobType_MobTypeDemon},
+			},
+			Duration: 180,
+		},
+	}, simsignals.CreateSignals())
+	if result.Error == nil {
+		t.Fatalf("Expected an error for a caster without mana")
+	}
+}
@@ -98,10 +98,24 @@ type UnitMetrics struct {
 	CharacterIterationMetrics
 
 	// Aggregate values. These are updated after each iteration.
-	numItersDead int32
-	oomTimeSum   float64
-	actions      map[ActionID]*ActionMetrics
-	resources    []*ResourceMetrics
+	numItersDead        int32
+	oomTimeSum          float64
+	aggroTimeSum        float64
+	numItersPulledAggro int32
+	actions             map[ActionID]*ActionMetrics
+	resources           []*ResourceMetrics
+
+	// Debuffs of this unit competing for limited debuff slots, see debuff_slots.go.
+	debuffSlots []*debuffSlot
+
+	// Only set for players tanking a target, see survivability.go.
+	survivability *tankSurvivability
+
+	// Only set for players with a pull state, see pull_state.go.
+	pullState *pullStateTracker
+
+	// Only set for players with a trinket schedule, see trinket_scheduler.go.
+	trinketScheduler *trinketScheduler
 }
 
 // Metrics for the current iteration, for 1 agent. Keep this as a separate
@@ -116,6 +130,9 @@ type CharacterIterationMetrics struct {
 	OOMTime time.Duration // time spent not casting and waiting for regen.
 
 	FirstOOMTimestamp time.Duration // Timestamp at which unit first went OOM.
+
+	AggroTime   time.Duration // Time spent holding aggro, see ThreatTable.
+	PulledAggro bool          // Whether this unit pulled aggro from the tank.
 }
 
 type ActionMetrics struct {
@@ -166,7 +183,9 @@ type SpellMetrics struct {
 	Blocks            int32
 	BlockedCrits      int32
 
-	// Partial or full resists aren't tracked, at the moment, cp. applyResistances()
+	// Landed hits and ticks which rolled for partial resists, by resisted amount.
+	PartialResists [4]int32
+
 	TotalDamage                 float64 // Damage done by all casts of this spell.
 	TotalResistedDamage         float64 // Damage done by all resisted casts of this spell.
 	TotalCritDamage             float64 // Damage done by all critical casts of this spell.
@@ -179,6 +198,7 @@ type SpellMetrics struct {
 	TotalBlockDamage            float64 // Damage done by all block casts of this spell.
 	TotalBlockedCritDamage      float64 // Damage done by all blocked critical casts casts of this spell.
 	TotalCrushDamage            float64 // Damage done by all crushed casts of this spell.
+	TotalResistedDamageLost     float64 // Damage prevented by partial resists of this spell.
 	TotalThreat                 float64 // Threat generated by all casts of this spell.
 	TotalHealing                float64 // Healing done by all casts of this spell.
 	TotalCritHealing            float64 // Healing done by all critical casts of this spell.
@@ -203,6 +223,7 @@ type TargetedActionMetrics struct {
 	Blocks            int32
 	BlockedCrits      int32
 	Crushes           int32
+	PartialResists    [4]int32
 
 	Damage                 float64
 	ResistedDamage         float64
@@ -216,6 +237,7 @@ type TargetedActionMetrics struct {
 	BlockDamage            float64
 	BlockedCritDamage      float64
 	CrushDamage            float64
+	ResistedDamageLost     float64
 	Threat                 float64
 	Healing                float64
 	CritHealing            float64
@@ -255,6 +277,11 @@ func (tam *TargetedActionMetrics) ToProto(unitIndex int32) *proto.TargetedAction
 		BlockDamage:            tam.BlockDamage,
 		BlockedCritDamage:      tam.BlockedCritDamage,
 		CrushDamage:            tam.CrushDamage,
+		PartialResists_0:       tam.PartialResists[0],
+		PartialResists_25:      tam.PartialResists[1],
+		PartialResists_50:      tam.PartialResists[2],
+		PartialResists_75:      tam.PartialResists[3],
+		ResistedDamageLost:     tam.ResistedDamageLost,
 		Threat:                 tam.Threat,
 		Healing:                tam.Healing,
 		CritHealing:            tam.CritHealing,
@@ -415,6 +442,10 @@ func (unitMetrics *UnitMetrics) addSpellMetrics(spell *Spell, actionID ActionID,
 		tam.BlockDamage += spellTargetMetrics.TotalBlockDamage
 		tam.BlockedCritDamage += spellTargetMetrics.TotalBlockedCritDamage
 		tam.CrushDamage += spellTargetMetrics.TotalCrushDamage
+		for j, count := range spellTargetMetrics.PartialResists {
+			tam.PartialResists[j] += count
+		}
+		tam.ResistedDamageLost += spellTargetMetrics.TotalResistedDamageLost
 		tam.Threat += spellTargetMetrics.TotalThreat
 		tam.Healing += spellTargetMetrics.TotalHealing
 		tam.CritHealing += spellTargetMetrics.TotalCritHealing
@@ -470,6 +501,9 @@ func (unitMetrics *UnitMetrics) reset() {
 	unitMetrics.hps.reset()
 	unitMetrics.tto.reset()
 	unitMetrics.CharacterIterationMetrics = CharacterIterationMetrics{}
+	if unitMetrics.survivability != nil {
+		unitMetrics.survivability.damageTaken = unitMetrics.survivability.damageTaken[:0]
+	}
 
 	for _, resourceMetrics := range unitMetrics.resources {
 		resourceMetrics.reset()
@@ -514,10 +548,24 @@ func (unitMetrics *UnitMetrics) doneIteration(unit *Unit, sim *Simulation) {
 	unitMetrics.hps.doneIteration(sim)
 	unitMetrics.tto.doneIteration(sim)
 
+	if unitMetrics.survivability != nil {
+		unitMetrics.survivability.doneIteration(sim)
+	}
+	if unitMetrics.pullState != nil {
+		unitMetrics.pullState.doneIteration(sim)
+	}
+	if unitMetrics.trinketScheduler != nil {
+		unitMetrics.trinketScheduler.doneIteration(sim)
+	}
+
 	unitMetrics.oomTimeSum += unitMetrics.OOMTime.Seconds()
 	if unitMetrics.Died {
 		unitMetrics.numItersDead++
 	}
+	unitMetrics.aggroTimeSum += unitMetrics.AggroTime.Seconds()
+	if unitMetrics.PulledAggro {
+		unitMetrics.numItersPulledAggro++
+	}
 }
 
 func (unitMetrics *UnitMetrics) calculateTMI(unit *Unit, sim *Simulation) float64 {
@@ -604,6 +652,9 @@ func (unitMetrics *UnitMetrics) ToProto() *proto.UnitMetrics {
 		Tto:           unitMetrics.tto.ToProto(),
 		SecondsOomAvg: unitMetrics.oomTimeSum / n,
 		ChanceOfDeath: float64(unitMetrics.numItersDead) / n,
+
+		SecondsWithAggroAvg:  unitMetrics.aggroTimeSum / n,
+		ChanceOfPullingAggro: float64(unitMetrics.numItersPulledAggro) / n,
 	}
 
 	protoMetrics.Actions = make([]*proto.ActionMetrics, 0, len(unitMetrics.actions))
@@ -611,6 +662,20 @@ func (unitMetrics *UnitMetrics) ToProto() *proto.UnitMetrics {
 		protoMetrics.Actions = append(protoMetrics.Actions, action.ToProto(actionID))
 	}
 
+	for _, slot := range unitMetrics.debuffSlots {
+		protoMetrics.DebuffSlots = append(protoMetrics.DebuffSlots, slot.ToProto())
+	}
+
+	if unitMetrics.survivability != nil {
+		protoMetrics.Survivability = unitMetrics.survivability.ToProto()
+	}
+	if unitMetrics.pullState != nil {
+		protoMetrics.EndState = unitMetrics.pullState.ToProto()
+	}
+	if unitMetrics.trinketScheduler != nil {
+		protoMetrics.TrinketUses = unitMetrics.trinketScheduler.ToProto()
+	}
+
 	protoMetrics.Resources = make([]*proto.ResourceMetrics, 0, len(unitMetrics.resources))
 	for _, resource := range unitMetrics.resources {
 		if resource.Events > 0 {
@@ -75,8 +75,12 @@ type MovementHandler struct {
 	Moving    bool
 	MoveSpeed float64
 
+	// Set while an encounter mechanic keeps the unit out of range of its target.
+	OutOfRange bool
+
 	baseSpeed          float64
 	moveAura           *Aura
+	forcedMoveAura     *Aura
 	moveSpell          *Spell
 	moveSpeedBonuses   *MoveHeap
 	moveSpeedPenalties *MoveHeap
@@ -112,6 +116,9 @@ func (unit *Unit) initMovement() {
 			unit.MovementHandler.Moving = true
 		},
 		OnExpire: func(aura *Aura, sim *Simulation) {
+			if unit.MovementHandler.forcedMoveAura.IsActive() {
+				return
+			}
 			unit.MovementHandler.Moving = false
 			unit.AutoAttacks.EnableAutoSwing(sim)
 
@@ -120,6 +127,38 @@ func (unit *Unit) initMovement() {
 		},
 	})
 
+	// Movement forced by encounter mechanics, see encounter_movement.go.
+	autosWereEnabled := false
+	unit.MovementHandler.forcedMoveAura = unit.GetOrRegisterAura(Aura{
+		Label:    "Encounter Movement",
+		ActionID: ActionID{OtherID: proto.OtherAction_OtherActionMove, Tag: 1},
+		Duration: time.Second,
+
+		OnReset: func(aura *Aura, sim *Simulation) {
+			unit.MovementHandler.OutOfRange = false
+		},
+		OnGain: func(aura *Aura, sim *Simulation) {
+			unit.CancelHardcast(sim)
+			if unit.IsChanneling(sim) {
+				unit.ChanneledDot.Cancel(sim)
+			}
+			autosWereEnabled = unit.AutoAttacks.enabled
+			unit.AutoAttacks.CancelAutoSwing(sim)
+			unit.MovementHandler.Moving = true
+		},
+		OnExpire: func(aura *Aura, sim *Simulation) {
+			unit.MovementHandler.OutOfRange = false
+			if unit.MovementHandler.moveAura.IsActive() {
+				return
+			}
+			unit.MovementHandler.Moving = false
+			if autosWereEnabled {
+				unit.AutoAttacks.EnableAutoSwing(sim)
+				unit.AutoAttacks.DelayMeleeBy(sim, time.Millisecond*50)
+			}
+		},
+	})
+
 	unit.MovementHandler.moveSpell = unit.GetOrRegisterSpell(SpellConfig{
 		ActionID: ActionID{OtherID: proto.OtherAction_OtherActionMove},
 		Flags:    SpellFlagMeleeMetrics,
@@ -135,6 +174,32 @@ func (unit *Unit) IsMoving() bool {
 	return unit.MovementHandler.Moving
 }
 
+// Whether an encounter mechanic currently keeps the unit out of range of its target.
+func (unit *Unit) IsOutOfRange() bool {
+	return unit.MovementHandler.OutOfRange
+}
+
+// Forces the unit to move for the given duration, interrupting any cast or channel and
+// pausing auto attacks. If outOfRange is set, melee abilities can't be used while moving.
+func (unit *Unit) ForceMovement(sim *Simulation, duration time.Duration, outOfRange bool) {
+	aura := unit.MovementHandler.forcedMoveAura
+	if aura.IsActive() && aura.ExpiresAt() >= sim.CurrentTime+duration {
+		unit.MovementHandler.OutOfRange = unit.MovementHandler.OutOfRange || outOfRange
+		return
+	}
+	aura.Duration = duration
+	aura.Activate(sim)
+	unit.MovementHandler.OutOfRange = unit.MovementHandler.OutOfRange || outOfRange
+}
+
+// Returns when the current forced movement ends, or 0 if the unit isn't forced to move.
+func (unit *Unit) ForcedMovementEndsAt() time.Duration {
+	if aura := unit.MovementHandler.forcedMoveAura; aura.IsActive() {
+		return aura.ExpiresAt()
+	}
+	return 0
+}
+
 func (unit *Unit) MoveTo(moveRange float64, sim *Simulation) {
 	if moveRange == unit.DistanceFromTarget {
 		return
@@ -21,6 +21,10 @@ const (
 	// DOTs need to be higher than anything else so that dots can properly expire before we take other actions.
 	ActionPriorityDOT ActionPriority = 3
 
+	// Spell batches land before the other actions at the same time, so that those
+	// see their results.
+	ActionPrioritySpellBatch ActionPriority = 4
+
 	ActionPriorityPrePull ActionPriority = 10
 )
 
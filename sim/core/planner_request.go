@@ -0,0 +1,44 @@
+package core
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// Helpers shared by the planner RPCs, which sim variations of a request's raid.
+
+// Recovers from a panic while handling a request, passing it to setError with its stack
+// trace. Must be deferred directly, so recover() sees the panic.
+func recoverRequestError(setError func(errorOutcome *proto.ErrorOutcome)) {
+	if err := recover(); err != nil {
+		setError(&proto.ErrorOutcome{Message: fmt.Sprintf("%v\nStack Trace:\n%s", err, string(debug.Stack()))})
+	}
+}
+
+// Returns the party and player indices of the player at the given raid index, or an
+// error if there is no player there.
+func raidPlayerIndices(raid *proto.Raid, raidIndex int32) (int, int, *proto.ErrorOutcome) {
+	partyIdx, playerIdx := int(raidIndex/5), int(raidIndex%5)
+	if raidIndex < 0 || partyIdx >= len(raid.Parties) || playerIdx >= len(raid.Parties[partyIdx].Players) {
+		return 0, 0, &proto.ErrorOutcome{Message: fmt.Sprintf("no player at raid index %d", raidIndex)}
+	}
+	return partyIdx, playerIdx, nil
+}
+
+// Returns a copy of the request's sim options to sim with, so the request is left
+// unmodified, with the default iterations if none are set and debug logs off.
+func requestSimOptions(simOptions *proto.SimOptions, defaultIterations int32) *proto.SimOptions {
+	if simOptions == nil {
+		simOptions = &proto.SimOptions{}
+	} else {
+		simOptions = googleProto.Clone(simOptions).(*proto.SimOptions)
+	}
+	if simOptions.Iterations == 0 {
+		simOptions.Iterations = defaultIterations
+	}
+	simOptions.Debug = false
+	return simOptions
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+func TestRaidPlayerIndices(t *testing.T) {
+	raid := &proto.Raid{
+		Parties: []*proto.Party{
+			{Players: []*proto.Player{{}, {}}},
+			{Players: []*proto.Player{{}}},
+		},
+	}
+	for _, test := range []struct {
+		raidIndex int32
+		partyIdx  int
+		playerIdx int
+		ok        bool
+	}{
+		{0, 0, 0, true},
+		{1, 0, 1, true},
+		{5, 1, 0, true},
+		{2, 0, 0, false},
+		{6, 0, 0, false},
+		{10, 0, 0, false},
+		{-1, 0, 0, false},
+	} {
+		partyIdx, playerIdx, errorOutcome := raidPlayerIndices(raid, test.raidIndex)
+		if (errorOutcome == nil) != test.ok || partyIdx != test.partyIdx || playerIdx != test.playerIdx {
+			t.Errorf("Expected party %d player %d (ok: %t) for raid index %d, got party %d player %d with error %v",
+				test.partyIdx, test.playerIdx, test.ok, test.raidIndex, partyIdx, playerIdx, errorOutcome)
+		}
+	}
+}
+
+func TestRequestSimOptions(t *testing.T) {
+	if simOptions := requestSimOptions(nil, 100); simOptions.Iterations != 100 {
+		t.Fatalf("Expected the default iterations without sim options, got %d", simOptions.Iterations)
+	}
+
+	request := &proto.SimOptions{Iterations: 10, Debug: true}
+	simOptions := requestSimOptions(request, 100)
+	if simOptions.Iterations != 10 || simOptions.Debug {
+		t.Fatalf("Expected the request's iterations without debug logs, got %v", simOptions)
+	}
+	if !request.Debug {
+		t.Fatalf("Expected the request's sim options to be left unmodified")
+	}
+}
+
+func TestRecoverRequestError(t *testing.T) {
+	result := func() (result *proto.RaidCompositionResult) {
+		defer recoverRequestError(func(errorOutcome *proto.ErrorOutcome) {
+			result = &proto.RaidCompositionResult{Error: errorOutcome}
+		})
+		panic("planner failed")
+	}()
+	if !strings.HasPrefix(result.GetError().GetMessage(), "planner failed\nStack Trace:") {
+		t.Fatalf("Expected the panic as the result's error, got %v", result)
+	}
+}
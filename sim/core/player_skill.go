@@ -0,0 +1,113 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+// Human factors applied to a player's actions. The zero value models perfect play.
+type PlayerSkill struct {
+	// Network latency, see proto.PlayerSkill.
+	Latency time.Duration
+
+	// Window before the end of the GCD or a cast in which the next action can be queued.
+	SpellQueueWindow time.Duration
+
+	// Maximum random delay added on top of Unit.ReactionTime.
+	ReactionVariance time.Duration
+
+	// Whether the rotation waits for the reaction time before responding to
+	// resource gains and auto attacks. Only enabled when a skill model is configured,
+	// so that existing sims keep reacting instantly.
+	ReactToEvents bool
+}
+
+type playerSkillPreset struct {
+	reactionTime     time.Duration
+	latency          time.Duration
+	spellQueueWindow time.Duration
+	reactionVariance time.Duration
+}
+
+var playerSkillPresets = map[proto.PlayerSkill_Preset]playerSkillPreset{
+	proto.PlayerSkill_Perfect: {},
+	proto.PlayerSkill_Expert: {
+		reactionTime:     time.Millisecond * 150,
+		latency:          time.Millisecond * 50,
+		spellQueueWindow: time.Millisecond * 400,
+		reactionVariance: time.Millisecond * 50,
+	},
+	proto.PlayerSkill_Average: {
+		reactionTime:     time.Millisecond * 250,
+		latency:          time.Millisecond * 100,
+		spellQueueWindow: time.Millisecond * 50,
+		reactionVariance: time.Millisecond * 150,
+	},
+	proto.PlayerSkill_Novice: {
+		reactionTime:     time.Millisecond * 400,
+		latency:          time.Millisecond * 150,
+		reactionVariance: time.Millisecond * 300,
+	},
+}
+
+// Returns the skill model and reaction time for a player.
+func newPlayerSkill(player *proto.Player) (PlayerSkill, time.Duration) {
+	reactionTime := max(0, time.Duration(player.ReactionTimeMs)*time.Millisecond)
+	config := player.Skill
+	if config == nil {
+		return PlayerSkill{}, reactionTime
+	}
+
+	if preset, ok := playerSkillPresets[config.Preset]; ok {
+		return PlayerSkill{
+			Latency:          preset.latency,
+			SpellQueueWindow: preset.spellQueueWindow,
+			ReactionVariance: preset.reactionVariance,
+			ReactToEvents:    true,
+		}, preset.reactionTime
+	}
+
+	return PlayerSkill{
+		Latency:          max(0, time.Duration(config.LatencyMs)*time.Millisecond),
+		SpellQueueWindow: max(0, time.Duration(config.SpellQueueWindowMs)*time.Millisecond),
+		ReactionVariance: max(0, time.Duration(config.ReactionVarianceMs)*time.Millisecond),
+		ReactToEvents:    true,
+	}, reactionTime
+}
+
+// The delay added to the GCD and cast completion of each action, i.e. the part
+// of the network latency that isn't hidden by the spell queue window.
+func (unit *Unit) ActionLatency() time.Duration {
+	return max(0, unit.Skill.Latency-unit.Skill.SpellQueueWindow)
+}
+
+// Returns a reaction time for responding to procs and other in-game events,
+// randomized by the unit's reaction variance.
+func (unit *Unit) RandomReactionTime(sim *Simulation) time.Duration {
+	if unit.Skill.ReactionVariance <= 0 {
+		return unit.ReactionTime
+	}
+	return unit.ReactionTime + DurationFromSeconds(sim.RandomFloat("Reaction Time")*unit.Skill.ReactionVariance.Seconds())
+}
+
+// Keeps the same random reaction time for the duration of an aura activation.
+type auraReactionTime struct {
+	unit *Unit
+
+	initialized  bool
+	startedAt    time.Duration
+	lastCheckAt  time.Duration
+	reactionTime time.Duration
+}
+
+func (art *auraReactionTime) Get(sim *Simulation, aura *Aura) time.Duration {
+	// Time only moves backwards when a new iteration starts.
+	if !art.initialized || aura.StartedAt() != art.startedAt || sim.CurrentTime < art.lastCheckAt {
+		art.initialized = true
+		art.startedAt = aura.StartedAt()
+		art.reactionTime = art.unit.RandomReactionTime(sim)
+	}
+	art.lastCheckAt = sim.CurrentTime
+	return art.reactionTime
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+func TestPlayerSkillDefaultsToPerfectPlay(t *testing.T) {
+	skill, reactionTime := newPlayerSkill(&proto.Player{ReactionTimeMs: 150})
+	if skill != (PlayerSkill{}) {
+		t.Fatalf("Expected no skill model, got %+v", skill)
+	}
+	if reactionTime != time.Millisecond*150 {
+		t.Fatalf("Expected reaction time 150ms, got %s", reactionTime)
+	}
+}
+
+func TestPlayerSkillPreset(t *testing.T) {
+	skill, reactionTime := newPlayerSkill(&proto.Player{
+		ReactionTimeMs: 10,
+		Skill:          &proto.PlayerSkill{Preset: proto.PlayerSkill_Novice, LatencyMs: 1},
+	})
+	if reactionTime != time.Millisecond*400 {
+		t.Fatalf("Expected preset reaction time 400ms, got %s", reactionTime)
+	}
+	if skill.Latency != time.Millisecond*150 || !skill.ReactToEvents {
+		t.Fatalf("Unexpected preset skill %+v", skill)
+	}
+}
+
+func TestPlayerSkillSpellQueueWindow(t *testing.T) {
+	skill, _ := newPlayerSkill(&proto.Player{
+		Skill: &proto.PlayerSkill{LatencyMs: 120, SpellQueueWindowMs: 100},
+	})
+	unit := &Unit{Skill: skill}
+	if latency := unit.ActionLatency(); latency != time.Millisecond*20 {
+		t.Fatalf("Expected 20ms of latency outside the queue window, got %s", latency)
+	}
+
+	unit.Skill.SpellQueueWindow = time.Millisecond * 400
+	if latency := unit.ActionLatency(); latency != 0 {
+		t.Fatalf("Expected latency to be hidden by the queue window, got %s", latency)
+	}
+}
+
+func TestRandomReactionTime(t *testing.T) {
+	sim := &Simulation{rand: NewSplitMix(1)}
+	unit := &Unit{
+		ReactionTime: time.Millisecond * 200,
+		Skill:        PlayerSkill{ReactionVariance: time.Millisecond * 100},
+	}
+	for i := 0; i < 100; i++ {
+		if reactionTime := unit.RandomReactionTime(sim); reactionTime < time.Millisecond*200 || reactionTime > time.Millisecond*300 {
+			t.Fatalf("Reaction time %s outside of expected range", reactionTime)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package core
+
+import (
+	"math"
+	"slices"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+// Mana and health restored while resting between pulls, as a percent of their maximum
+// per second, for a full bar every 30 seconds of drinking and eating.
+const restPercentPerSecond = 100.0 / 30
+
+type spellCooldown struct {
+	spell     *Spell
+	shared    bool
+	remaining float64
+}
+
+func (cooldown *spellCooldown) timer() *Timer {
+	if cooldown.shared {
+		return cooldown.spell.SharedCD.Timer
+	}
+	return cooldown.spell.CD.Timer
+}
+
+type spellUses struct {
+	spell *Spell
+	uses  int
+}
+
+// Applies a player's state carried over from earlier encounters at each pull, and
+// tracks its state at the end of each fight to carry over to the next.
+type pullStateTracker struct {
+	character *Character
+	pullState *proto.PullState
+
+	// Resolved when the character is finalized.
+	cooldowns []spellCooldown
+	usesLeft  []spellUses
+	cdTimers  []spellCooldown
+
+	// Aggregate values. These are updated after each iteration.
+	numIterations    int
+	missingManaSum   float64
+	missingHealthSum float64
+	cooldownSums     []float64
+	usesLeftSums     []float64
+}
+
+func (character *Character) trackPullState(pullState *proto.PullState) {
+	if pullState == nil {
+		return
+	}
+
+	character.pullState = &pullStateTracker{
+		character: character,
+		pullState: pullState,
+	}
+	character.Metrics.pullState = character.pullState
+}
+
+func (tracker *pullStateTracker) finalize() {
+	character := tracker.character
+
+	for _, cooldown := range tracker.pullState.Cooldowns {
+		spell := character.GetSpell(ProtoToActionID(cooldown.Id))
+		if spell == nil {
+			continue
+		}
+		spellCooldown := spellCooldown{spell: spell, shared: cooldown.Shared, remaining: cooldown.RemainingSeconds}
+		if spellCooldown.timer() != nil && spellCooldown.remaining > 0 {
+			tracker.cooldowns = append(tracker.cooldowns, spellCooldown)
+		}
+	}
+
+	for _, uses := range tracker.pullState.UsesLeft {
+		spell := character.GetSpell(ProtoToActionID(uses.Id))
+		if spell == nil {
+			continue
+		}
+		usesLeft := spellUses{spell: spell, uses: int(math.Round(uses.Uses))}
+		tracker.usesLeft = append(tracker.usesLeft, usesLeft)
+
+		oldCondition := spell.ExtraCastCondition
+		spell.ExtraCastCondition = func(sim *Simulation, target *Unit) bool {
+			return spell.casts < usesLeft.uses && (oldCondition == nil || oldCondition(sim, target))
+		}
+	}
+
+	// Spells sharing a cooldown, like ranks of a spell or potions, only track it once.
+	for _, spell := range character.Spellbook {
+		for _, cooldown := range []spellCooldown{{spell: spell}, {spell: spell, shared: true}} {
+			timer := cooldown.timer()
+			if timer != nil && !slices.ContainsFunc(tracker.cdTimers, func(cdTimer spellCooldown) bool { return cdTimer.timer() == timer }) {
+				tracker.cdTimers = append(tracker.cdTimers, cooldown)
+			}
+		}
+	}
+	tracker.cooldownSums = make([]float64, len(tracker.cdTimers))
+	tracker.usesLeftSums = make([]float64, len(tracker.usesLeft))
+}
+
+func (tracker *pullStateTracker) reset(_ *Simulation) {
+	character := tracker.character
+
+	for _, cooldown := range tracker.cooldowns {
+		cooldown.timer().Set(DurationFromSeconds(cooldown.remaining))
+	}
+
+	if character.HasManaBar() {
+		character.currentMana = character.MaxMana() * (1 - tracker.pullState.MissingManaPercent/100)
+	}
+	if character.HasHealthBar() {
+		character.currentHealth = character.MaxHealth() * (1 - tracker.pullState.MissingHealthPercent/100)
+	}
+}
+
+func (tracker *pullStateTracker) doneIteration(sim *Simulation) {
+	character := tracker.character
+	tracker.numIterations++
+
+	if character.HasManaBar() {
+		tracker.missingManaSum += (1 - character.CurrentManaPercent()) * 100
+	}
+	if character.HasHealthBar() {
+		tracker.missingHealthSum += (1 - character.CurrentHealthPercent()) * 100
+	}
+
+	for i, cdTimer := range tracker.cdTimers {
+		tracker.cooldownSums[i] += cdTimer.timer().TimeToReady(sim).Seconds()
+	}
+	for i, usesLeft := range tracker.usesLeft {
+		tracker.usesLeftSums[i] += float64(max(0, usesLeft.uses-usesLeft.spell.casts))
+	}
+}
+
+func (tracker *pullStateTracker) ToProto() *proto.PullState {
+	n := float64(max(tracker.numIterations, 1))
+	endState := &proto.PullState{
+		MissingManaPercent:   tracker.missingManaSum / n,
+		MissingHealthPercent: tracker.missingHealthSum / n,
+	}
+
+	for i, cdTimer := range tracker.cdTimers {
+		if tracker.cooldownSums[i] > 0 {
+			endState.Cooldowns = append(endState.Cooldowns, &proto.SpellCooldown{
+				Id:               cdTimer.spell.ActionID.ToProto(),
+				RemainingSeconds: tracker.cooldownSums[i] / n,
+				Shared:           cdTimer.shared,
+			})
+		}
+	}
+	for i, usesLeft := range tracker.usesLeft {
+		endState.UsesLeft = append(endState.UsesLeft, &proto.ActionUses{
+			Id:   usesLeft.spell.ActionID.ToProto(),
+			Uses: tracker.usesLeftSums[i] / n,
+		})
+	}
+
+	return endState
+}
+
+// Adds the weighted end state of a concurrent sim, whose running cooldowns may differ.
+func combinePullStates(base *proto.PullState, add *proto.PullState, weight float64) {
+	base.MissingManaPercent += add.MissingManaPercent * weight
+	base.MissingHealthPercent += add.MissingHealthPercent * weight
+
+	for _, addCooldown := range add.Cooldowns {
+		idx := slices.IndexFunc(base.Cooldowns, func(cooldown *proto.SpellCooldown) bool {
+			return ProtoToActionID(cooldown.Id) == ProtoToActionID(addCooldown.Id) && cooldown.Shared == addCooldown.Shared
+		})
+		if idx == -1 {
+			idx = len(base.Cooldowns)
+			base.Cooldowns = append(base.Cooldowns, &proto.SpellCooldown{Id: addCooldown.Id, Shared: addCooldown.Shared})
+		}
+		base.Cooldowns[idx].RemainingSeconds += addCooldown.RemainingSeconds * weight
+	}
+
+	for i, addUses := range add.UsesLeft {
+		if i == len(base.UsesLeft) {
+			base.UsesLeft = append(base.UsesLeft, &proto.ActionUses{Id: addUses.Id})
+		}
+		base.UsesLeft[i].Uses += addUses.Uses * weight
+	}
+}
+
+// Returns the state at the next pull, after some time out of combat of which some was
+// spent resting.
+func pullStateAfter(endState *proto.PullState, elapsed float64, rested float64) *proto.PullState {
+	pullState := &proto.PullState{
+		MissingManaPercent:   max(0, endState.MissingManaPercent-rested*restPercentPerSecond),
+		MissingHealthPercent: max(0, endState.MissingHealthPercent-rested*restPercentPerSecond),
+		UsesLeft:             endState.UsesLeft,
+	}
+	for _, cooldown := range endState.Cooldowns {
+		if remaining := cooldown.RemainingSeconds - elapsed; remaining > 0 {
+			pullState.Cooldowns = append(pullState.Cooldowns, &proto.SpellCooldown{
+				Id:               cooldown.Id,
+				RemainingSeconds: remaining,
+				Shared:           cooldown.Shared,
+			})
+		}
+	}
+	return pullState
+}
@@ -59,6 +59,18 @@ func (party *Party) GetPartyBuffs(basePartyBuffs *proto.PartyBuffs) *proto.Party
 	return partyBuffs
 }
 
+// Returns the raid buffs for this party, without the toggles for buffs which its
+// members provide themselves.
+func (party *Party) getMemberRaidBuffs(raidBuffs *proto.RaidBuffs) *proto.RaidBuffs {
+	partyRaidBuffs := googleProto.Clone(raidBuffs).(*proto.RaidBuffs)
+	for _, player := range party.Players {
+		if provider, ok := player.(PartyBuffProvider); ok {
+			provider.ClearProvidedBuffs(partyRaidBuffs)
+		}
+	}
+	return partyRaidBuffs
+}
+
 func (party *Party) AddStats(newStats stats.Stats) {
 	for _, agent := range party.Players {
 		agent.GetCharacter().AddStats(newStats)
@@ -122,6 +134,9 @@ type Raid struct {
 	AllPlayerUnits []*Unit // Cached list of all Players in the raid.
 	AllUnits       []*Unit // Cached list of all Units (players and pets) in the raid.
 
+	// Whether party buffs come from the simulated party members, see PartyBuffProvider.
+	BuffsFromMembers bool
+
 	nextPetIndex int32
 }
 
@@ -143,9 +158,10 @@ func NewRaid(raidConfig *proto.Raid) *Raid {
 	}
 
 	raid := &Raid{
-		dpsMetrics:   NewDistributionMetrics(),
-		hpsMetrics:   NewDistributionMetrics(),
-		nextPetIndex: int32(numParties) * 5,
+		dpsMetrics:       NewDistributionMetrics(),
+		hpsMetrics:       NewDistributionMetrics(),
+		BuffsFromMembers: raidConfig.BuffsFromMembers,
+		nextPetIndex:     int32(numParties) * 5,
 	}
 
 	for partyIndex, partyConfig := range raidConfig.Parties {
@@ -269,6 +285,10 @@ func (raid *Raid) applyCharacterEffects(raidConfig *proto.Raid) *proto.RaidStats
 	for partyIdx, party := range raid.Parties {
 		partyConfig := raidConfig.Parties[partyIdx]
 		partyBuffs := party.GetPartyBuffs(partyConfig.Buffs)
+		partyRaidBuffs := raidBuffs
+		if raid.BuffsFromMembers {
+			partyRaidBuffs = party.getMemberRaidBuffs(raidBuffs)
+		}
 		partyStats := &proto.PartyStats{
 			Players: make([]*proto.PlayerStats, 5),
 		}
@@ -288,7 +308,10 @@ func (raid *Raid) applyCharacterEffects(raidConfig *proto.Raid) *proto.RaidStats
 			char := player.GetCharacter()
 			char.EnableHealthBar()
 			char.trackChanceOfDeath(playerConfig.HealingModel)
-			partyStats.Players[char.PartyIndex] = char.applyAllEffects(player, raidBuffs, partyBuffs, individualBuffs)
+			char.trackSurvivability(playerConfig.HealingModel)
+			char.trackPullState(playerConfig.PullState)
+			char.trackTrinketSchedule(playerConfig.TrinketSchedule)
+			partyStats.Players[char.PartyIndex] = char.applyAllEffects(player, partyRaidBuffs, partyBuffs, individualBuffs)
 
 			for _, pet := range char.Pets {
 				pet.EnableHealthBar()
@@ -0,0 +1,369 @@
+package core
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultCompositionQuickIterations = 100
+	defaultCompositionCandidates      = 3
+	compositionRestarts               = 20
+)
+
+// Searches party assignments for a roster of players which maximize raid DPS.
+//
+// Party placement only matters for buffs which players give their own party, so the
+// raid always derives party buffs from its members. The value of each party buff is
+// estimated with quick sims of every player paired with one player of each buff giving
+// kind, and the best arrangements found are validated with full raid sims. Shamans are
+// valued by the totems their APL drops, like Windfury Totem, and by Mana Tide Totem when
+// talented.
+func OptimizeRaidComposition(request *proto.RaidCompositionRequest, signals simsignals.Signals) (result *proto.RaidCompositionResult) {
+	defer recoverRequestError(func(errorOutcome *proto.ErrorOutcome) {
+		result = &proto.RaidCompositionResult{Error: errorOutcome}
+	})
+
+	// Defaults are filled in on a copy, so the caller's request isn't modified.
+	request = googleProto.Clone(request).(*proto.RaidCompositionRequest)
+	optimizer, err := newCompositionOptimizer(request, signals)
+	if err != nil {
+		return &proto.RaidCompositionResult{Error: &proto.ErrorOutcome{Message: err.Error()}}
+	}
+
+	optimizer.estimateBuffValues()
+	arrangements := optimizer.search()
+
+	result = &proto.RaidCompositionResult{}
+	for _, arrangement := range arrangements {
+		if signals.Abort.IsTriggered() {
+			return &proto.RaidCompositionResult{Error: &proto.ErrorOutcome{Message: "aborted"}}
+		}
+
+		raid := optimizer.buildRaid(arrangement.slots)
+		simResult := RunSim(&proto.RaidSimRequest{
+			Raid:       raid,
+			Encounter:  request.Encounter,
+			SimOptions: request.SimOptions,
+		}, nil, signals)
+		if simResult.Error != nil {
+			return &proto.RaidCompositionResult{Error: simResult.Error}
+		}
+
+		result.Candidates = append(result.Candidates, &proto.RaidCompositionCandidate{
+			Raid:             raid,
+			EstimatedBuffDps: arrangement.value,
+			Dps:              simResult.RaidMetrics.Dps,
+		})
+	}
+
+	slices.SortStableFunc(result.Candidates, func(a, b *proto.RaidCompositionCandidate) int {
+		if a.Dps.Avg > b.Dps.Avg {
+			return -1
+		} else if a.Dps.Avg < b.Dps.Avg {
+			return 1
+		}
+		return 0
+	})
+	return result
+}
+
+type compositionOptimizer struct {
+	request *proto.RaidCompositionRequest
+	signals simsignals.Signals
+
+	numParties      int
+	quickIterations int32
+	numCandidates   int
+
+	// Index into kinds for each roster player, where players of the same class and
+	// talents give the same buffs.
+	playerKinds []int
+	kinds       []compositionKind
+
+	// DPS each player gains from a party member of each kind, indexed by [player][kind].
+	buffValues [][]float64
+}
+
+type compositionKind struct {
+	class      proto.Class
+	players    []int // Roster indices of the players of this kind.
+	givesBuffs bool
+}
+
+type compositionArrangement struct {
+	slots []int // Roster index for each raid slot, or -1 if empty.
+	value float64
+}
+
+func newCompositionOptimizer(request *proto.RaidCompositionRequest, signals simsignals.Signals) (*compositionOptimizer, error) {
+	if len(request.Roster) == 0 {
+		return nil, fmt.Errorf("roster is empty")
+	}
+	if request.Encounter == nil || len(request.Encounter.Targets) == 0 {
+		return nil, fmt.Errorf("encounter has no targets")
+	}
+	if request.Raid == nil {
+		request.Raid = &proto.Raid{}
+	}
+	if request.SimOptions == nil {
+		request.SimOptions = &proto.SimOptions{}
+	}
+
+	numParties := int(request.Raid.NumActiveParties)
+	if numParties == 0 {
+		numParties = min(8, (len(request.Roster)+4)/5)
+	}
+	if len(request.Roster) > numParties*5 {
+		return nil, fmt.Errorf("roster of %d players doesn't fit in %d parties", len(request.Roster), numParties)
+	}
+
+	optimizer := &compositionOptimizer{
+		request:         request,
+		signals:         signals,
+		numParties:      numParties,
+		quickIterations: TernaryInt32(request.QuickIterations > 0, request.QuickIterations, defaultCompositionQuickIterations),
+		numCandidates:   int(TernaryInt32(request.NumCandidates > 0, request.NumCandidates, defaultCompositionCandidates)),
+	}
+
+	kindIndices := make(map[string]int)
+	for i, player := range request.Roster {
+		key := fmt.Sprintf("%d/%s", player.Class, player.TalentsString)
+		kindIdx, ok := kindIndices[key]
+		if !ok {
+			kindIdx = len(optimizer.kinds)
+			kindIndices[key] = kindIdx
+			optimizer.kinds = append(optimizer.kinds, compositionKind{
+				class:      player.Class,
+				givesBuffs: optimizer.givesPartyBuffs(player),
+			})
+		}
+		optimizer.kinds[kindIdx].players = append(optimizer.kinds[kindIdx].players, i)
+		optimizer.playerKinds = append(optimizer.playerKinds, kindIdx)
+	}
+
+	return optimizer, nil
+}
+
+// Whether a player buffs its own party, when the raid derives party buffs from its members.
+func (co *compositionOptimizer) givesPartyBuffs(player *proto.Player) bool {
+	env, _, _ := NewEnvironment(&proto.Raid{
+		Parties:          []*proto.Party{{Players: []*proto.Player{player}}},
+		BuffsFromMembers: true,
+	}, co.request.Encounter, false)
+
+	agent := env.Raid.Parties[0].Players[0]
+	if _, ok := agent.(PartyBuffProvider); ok {
+		return true
+	}
+	partyBuffs := &proto.PartyBuffs{}
+	agent.AddPartyBuffs(partyBuffs)
+	agent.GetCharacter().AddPartyBuffs(partyBuffs)
+	return !googleProto.Equal(partyBuffs, &proto.PartyBuffs{})
+}
+
+func (co *compositionOptimizer) quickSim(players ...*proto.Player) *proto.RaidSimResult {
+	raid := googleProto.Clone(co.request.Raid).(*proto.Raid)
+	raid.Parties = []*proto.Party{{Players: players}}
+	raid.NumActiveParties = 1
+	raid.Tanks = nil
+	raid.BuffsFromMembers = true
+
+	simOptions := googleProto.Clone(co.request.SimOptions).(*proto.SimOptions)
+	simOptions.Iterations = co.quickIterations
+	simOptions.Debug = false
+
+	result := RunSim(&proto.RaidSimRequest{
+		Raid:       raid,
+		Encounter:  co.request.Encounter,
+		SimOptions: simOptions,
+	}, nil, co.signals)
+	if result.Error != nil {
+		panic(result.Error.Message)
+	}
+	return result
+}
+
+// DPS of a player including its pets.
+func playerDpsWithPets(metrics *proto.UnitMetrics) float64 {
+	dps := metrics.Dps.Avg
+	for _, pet := range metrics.Pets {
+		dps += pet.Dps.Avg
+	}
+	return dps
+}
+
+// Measures how much DPS each player gains from a party member of each buff giving kind.
+func (co *compositionOptimizer) estimateBuffValues() {
+	co.buffValues = make([][]float64, len(co.request.Roster))
+	for i, player := range co.request.Roster {
+		co.buffValues[i] = make([]float64, len(co.kinds))
+		baseDps := -1.0
+
+		for kindIdx, kind := range co.kinds {
+			if !kind.givesBuffs {
+				continue
+			}
+			// Any other player of the kind represents it.
+			providerIdx := slices.IndexFunc(kind.players, func(p int) bool { return p != i })
+			if providerIdx == -1 {
+				continue
+			}
+			if co.signals.Abort.IsTriggered() {
+				panic("aborted")
+			}
+
+			if baseDps < 0 {
+				baseDps = playerDpsWithPets(co.quickSim(player).RaidMetrics.Parties[0].Players[0])
+			}
+			provider := co.request.Roster[kind.players[providerIdx]]
+			pairDps := playerDpsWithPets(co.quickSim(player, provider).RaidMetrics.Parties[0].Players[0])
+			co.buffValues[i][kindIdx] = max(0, pairDps-baseDps)
+		}
+	}
+}
+
+// Estimated DPS the members of a party gain from each other's buffs. Buffs from the same
+// class don't stack, so only the best player of each class counts.
+func (co *compositionOptimizer) partyValue(party []int) float64 {
+	value := 0.0
+	for _, receiver := range party {
+		if receiver == -1 {
+			continue
+		}
+		bestByClass := make(map[proto.Class]float64)
+		for _, provider := range party {
+			if provider == -1 || provider == receiver {
+				continue
+			}
+			kindIdx := co.playerKinds[provider]
+			class := co.kinds[kindIdx].class
+			bestByClass[class] = max(bestByClass[class], co.buffValues[receiver][kindIdx])
+		}
+		for _, classValue := range bestByClass {
+			value += classValue
+		}
+	}
+	return value
+}
+
+func (co *compositionOptimizer) arrangementValue(slots []int) float64 {
+	value := 0.0
+	for partyIdx := 0; partyIdx < co.numParties; partyIdx++ {
+		value += co.partyValue(slots[partyIdx*5 : partyIdx*5+5])
+	}
+	return value
+}
+
+// Hill climbs from several starting arrangements by swapping players between parties,
+// and returns the best distinct arrangements found.
+func (co *compositionOptimizer) search() []*compositionArrangement {
+	rand := rand.New(rand.NewSource(co.request.SimOptions.GetRandomSeed()))
+
+	var found []*compositionArrangement
+	seen := make(map[string]bool)
+	for restart := 0; restart < compositionRestarts; restart++ {
+		slots := co.startingSlots(rand, restart == 0)
+		co.hillClimb(slots)
+
+		key := co.arrangementKey(slots)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		found = append(found, &compositionArrangement{slots: slots, value: co.arrangementValue(slots)})
+	}
+
+	slices.SortStableFunc(found, func(a, b *compositionArrangement) int {
+		if a.value > b.value {
+			return -1
+		} else if a.value < b.value {
+			return 1
+		}
+		return 0
+	})
+	return found[:min(len(found), co.numCandidates)]
+}
+
+// The first start spreads players of each kind across the parties, the others are random.
+func (co *compositionOptimizer) startingSlots(rand *rand.Rand, spread bool) []int {
+	order := make([]int, 0, len(co.request.Roster))
+	if spread {
+		for _, kind := range co.kinds {
+			order = append(order, kind.players...)
+		}
+	} else {
+		order = rand.Perm(len(co.request.Roster))
+	}
+
+	slots := make([]int, co.numParties*5)
+	for i := range slots {
+		slots[i] = -1
+	}
+	for i, player := range order {
+		partyIdx := i % co.numParties
+		slots[partyIdx*5+i/co.numParties] = player
+	}
+	return slots
+}
+
+func (co *compositionOptimizer) hillClimb(slots []int) {
+	for improved := true; improved; {
+		improved = false
+		for a := range slots {
+			for b := a + 1; b < len(slots); b++ {
+				partyA, partyB := a/5, b/5
+				if partyA == partyB || (slots[a] == -1 && slots[b] == -1) {
+					continue
+				}
+
+				membersA := slots[partyA*5 : partyA*5+5]
+				membersB := slots[partyB*5 : partyB*5+5]
+				before := co.partyValue(membersA) + co.partyValue(membersB)
+				slots[a], slots[b] = slots[b], slots[a]
+				if co.partyValue(membersA)+co.partyValue(membersB) > before+1e-9 {
+					improved = true
+				} else {
+					slots[a], slots[b] = slots[b], slots[a]
+				}
+			}
+		}
+	}
+}
+
+// Identifies arrangements which only differ in the order of parties or party members.
+func (co *compositionOptimizer) arrangementKey(slots []int) string {
+	parties := make([]string, co.numParties)
+	for partyIdx := range parties {
+		members := slices.Clone(slots[partyIdx*5 : partyIdx*5+5])
+		slices.Sort(members)
+		parties[partyIdx] = fmt.Sprint(members)
+	}
+	slices.Sort(parties)
+	return strings.Join(parties, ",")
+}
+
+func (co *compositionOptimizer) buildRaid(slots []int) *proto.Raid {
+	raid := googleProto.Clone(co.request.Raid).(*proto.Raid)
+	raid.Parties = make([]*proto.Party, co.numParties)
+	raid.NumActiveParties = int32(co.numParties)
+	raid.Tanks = nil
+	raid.BuffsFromMembers = true
+
+	for partyIdx := range raid.Parties {
+		party := &proto.Party{}
+		for _, player := range slots[partyIdx*5 : partyIdx*5+5] {
+			if player != -1 {
+				party.Players = append(party.Players, co.request.Roster[player])
+			}
+		}
+		raid.Parties[partyIdx] = party
+	}
+	return raid
+}
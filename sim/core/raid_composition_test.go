@@ -0,0 +1,77 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+func TestRaidCompositionSpreadsBuffGivers(t *testing.T) {
+	// Two shamans whose totems are worth 100 DPS to each warrior, and nothing to each other.
+	co := &compositionOptimizer{
+		request: &proto.RaidCompositionRequest{
+			Roster:     make([]*proto.Player, 4),
+			SimOptions: &proto.SimOptions{RandomSeed: 1},
+		},
+		numParties:    2,
+		numCandidates: 2,
+		playerKinds:   []int{0, 0, 1, 1},
+		kinds: []compositionKind{
+			{class: proto.Class_ClassShaman, players: []int{0, 1}, givesBuffs: true},
+			{class: proto.Class_ClassWarrior, players: []int{2, 3}},
+		},
+		buffValues: [][]float64{{0, 0}, {0, 0}, {100, 0}, {100, 0}},
+	}
+
+	slots := []int{0, 1, -1, -1, -1, 2, 3, -1, -1, -1}
+	if value := co.arrangementValue(slots); value != 0 {
+		t.Fatalf("Expected no buff value with both shamans in one party, got %0.1f", value)
+	}
+
+	co.hillClimb(slots)
+	if value := co.arrangementValue(slots); value != 200 {
+		t.Fatalf("Expected each warrior to get a shaman, got %0.1f for %v", value, slots)
+	}
+
+	// A second shaman in the same party doesn't stack.
+	if value := co.partyValue([]int{0, 1, 2, -1, -1}); value != 100 {
+		t.Fatalf("Expected totems from the same class not to stack, got %0.1f", value)
+	}
+
+	arrangements := co.search()
+	if len(arrangements) == 0 || arrangements[0].value != 200 {
+		t.Fatalf("Expected the best arrangement to be found first, got %+v", arrangements)
+	}
+}
+
+func TestRaidCompositionKeepsRequest(t *testing.T) {
+	newPlayer := func(name string) *proto.Player {
+		return &proto.Player{
+			Name:      name,
+			Class:     proto.Class_ClassShaman,
+			Consumes:  &proto.Consumes{},
+			Spec:      &proto.Player_ElementalShaman{},
+			Equipment: &proto.EquipmentSpec{},
+		}
+	}
+	request := &proto.RaidCompositionRequest{
+		Roster: []*proto.Player{newPlayer("A"), newPlayer("B")},
+		Encounter: &proto.Encounter{
+			Targets:  []*proto.Target{{Level: 63, MobType: proto.MobType_MobTypeDemon}},
+			Duration: 30,
+		},
+		SimOptions: &proto.SimOptions{Iterations: 1, IsTest: true},
+	}
+
+	result := OptimizeRaidComposition(request, simsignals.CreateSignals())
+	if result.Error != nil {
+		t.Fatalf("Optimizing failed: %s", result.Error.Message)
+	}
+	if len(result.Candidates) == 0 || len(result.Candidates[0].Raid.Parties) != 1 {
+		t.Fatalf("Expected the roster to fit in one party, got %v", result.Candidates)
+	}
+	if request.Raid != nil {
+		t.Fatalf("Defaults were written into the request's raid: %v", request.Raid)
+	}
+}
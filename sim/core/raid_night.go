@@ -0,0 +1,123 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+const defaultRaidNightIterations = 1000
+
+// Sims a raid night of encounters in order, carrying over each player's world buffs,
+// cooldowns, limited consumables, mana and health from each encounter to the next.
+// Each encounter is simmed on its own, with the average state at the end of the
+// previous one, so the results are per encounter and for the whole night.
+func SimRaidNight(request *proto.RaidNightRequest, signals simsignals.Signals) (result *proto.RaidNightResult) {
+	defer recoverRequestError(func(errorOutcome *proto.ErrorOutcome) {
+		result = &proto.RaidNightResult{Error: errorOutcome}
+	})
+
+	if request.Raid == nil || len(request.Encounters) == 0 {
+		return &proto.RaidNightResult{Error: &proto.ErrorOutcome{Message: "missing raid or encounters"}}
+	}
+
+	simOptions := requestSimOptions(request.SimOptions, defaultRaidNightIterations)
+
+	// Every player gets a pull state, so their state at the end of each encounter is
+	// tracked, and world buffs are counted down from the start of the night.
+	raid := googleProto.Clone(request.Raid).(*proto.Raid)
+	startBuffs := make([][]*proto.IndividualBuffs, len(raid.Parties))
+	for partyIdx, party := range raid.Parties {
+		startBuffs[partyIdx] = make([]*proto.IndividualBuffs, len(party.Players))
+		for playerIdx, player := range party.Players {
+			if !isRaidNightPlayer(player) {
+				continue
+			}
+			if player.PullState == nil {
+				player.PullState = &proto.PullState{}
+			}
+			if player.Buffs != nil {
+				startBuffs[partyIdx][playerIdx] = worldBuffsAfter(player.Buffs, 0)
+			}
+		}
+	}
+
+	result = &proto.RaidNightResult{}
+	var nightTime time.Duration
+	var lastMetrics *proto.RaidMetrics
+	for i, encounter := range request.Encounters {
+		if signals.Abort.IsTriggered() {
+			panic("aborted")
+		}
+		if encounter.Encounter == nil || encounter.GapSeconds < 0 || encounter.RestSeconds < 0 {
+			panic(fmt.Sprintf("encounter %d is missing, or has a negative gap or rest", i+1))
+		}
+
+		nightTime += DurationFromSeconds(encounter.GapSeconds)
+		raid = googleProto.Clone(raid).(*proto.Raid)
+		for partyIdx, party := range raid.Parties {
+			for playerIdx, player := range party.Players {
+				if !isRaidNightPlayer(player) {
+					continue
+				}
+				if player.Buffs != nil {
+					player.Buffs = worldBuffsAfter(startBuffs[partyIdx][playerIdx], nightTime)
+				}
+				if lastMetrics != nil {
+					endState := lastMetrics.Parties[partyIdx].Players[playerIdx].EndState
+					player.PullState = pullStateAfter(endState, encounter.GapSeconds, min(encounter.RestSeconds, encounter.GapSeconds))
+				}
+			}
+		}
+
+		simResult := RunSim(&proto.RaidSimRequest{
+			Raid:       raid,
+			Encounter:  encounter.Encounter,
+			SimOptions: simOptions,
+		}, nil, signals)
+		if simResult.Error != nil {
+			panic(fmt.Sprintf("encounter %d: %s", i+1, simResult.Error.Message))
+		}
+
+		result.Encounters = append(result.Encounters, &proto.RaidNightEncounterResult{
+			Name:            encounter.Name,
+			PullTimeSeconds: nightTime.Seconds(),
+			Raid:            raid,
+			Result:          simResult,
+		})
+
+		duration := simResult.AvgIterationDuration
+		nightTime += DurationFromSeconds(duration)
+		result.CombatSeconds += duration
+		result.TotalDamage += simResult.RaidMetrics.Dps.Avg * duration
+		lastMetrics = simResult.RaidMetrics
+	}
+
+	result.NightSeconds = nightTime.Seconds()
+	result.Dps = result.TotalDamage / result.CombatSeconds
+
+	for partyIdx, party := range raid.Parties {
+		for playerIdx, player := range party.Players {
+			if !isRaidNightPlayer(player) {
+				continue
+			}
+			playerResult := &proto.RaidNightPlayerResult{Name: player.Name}
+			for _, encounter := range result.Encounters {
+				metrics := encounter.Result.RaidMetrics.Parties[partyIdx].Players[playerIdx]
+				playerResult.TotalDamage += metrics.Dps.Avg * encounter.Result.AvgIterationDuration
+			}
+			playerResult.Dps = playerResult.TotalDamage / result.CombatSeconds
+			result.Players = append(result.Players, playerResult)
+		}
+	}
+
+	return result
+}
+
+// Empty raid slots aren't simmed, see NewParty.
+func isRaidNightPlayer(player *proto.Player) bool {
+	return player != nil && player.Class != proto.Class_ClassUnknown
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+func TestSimRaidNightCarriesOverState(t *testing.T) {
+	dynamite := ActionID{ItemID: 18641}
+	encounter := &proto.Encounter{
+		Targets: []*proto.Target{
+			{Level: 63, MobType: proto.MobType_MobTypeDemon},
+		},
+		Duration: 90,
+	}
+
+	result := SimRaidNight(&proto.RaidNightRequest{
+		Raid: SinglePlayerRaidProto(&proto.Player{
+			Name:        "Engineer",
+			Class:       proto.Class_ClassShaman,
+			Profession1: proto.Profession_Engineering,
+			Consumes: &proto.Consumes{
+				FillerExplosive: proto.Explosive_ExplosiveDenseDynamite,
+			},
+			Buffs: &proto.IndividualBuffs{
+				SongflowerSerenade: true,
+				WorldBuffDurations: &proto.WorldBuffDurations{SongflowerSerenade: 95},
+			},
+			Spec:      &proto.Player_ElementalShaman{},
+			Equipment: &proto.EquipmentSpec{},
+			Rotation: &proto.APLRotation{
+				Type: proto.APLRotation_TypeAPL,
+				PriorityList: []*proto.APLListItem{
+					{Action: &proto.APLAction{Action: &proto.APLAction_AutocastOtherCooldowns{AutocastOtherCooldowns: &proto.APLActionAutocastOtherCooldowns{}}}},
+				},
+			},
+			PullState: &proto.PullState{
+				UsesLeft: []*proto.ActionUses{
+					{Id: dynamite.ToProto(), Uses: 3},
+				},
+			},
+		}, &proto.PartyBuffs{}, &proto.RaidBuffs{}, &proto.Debuffs{}),
+		Encounters: []*proto.RaidNightEncounter{
+			{Name: "First", Encounter: encounter},
+			{Name: "Second", Encounter: encounter, GapSeconds: 10},
+		},
+		SimOptions: &proto.SimOptions{
+			Iterations: 5,
+			RandomSeed: 101,
+			IsTest:     true,
+		},
+	}, simsignals.CreateSignals())
+	if result.Error != nil {
+		t.Fatalf("Raid night failed: %s", result.Error.Message)
+	}
+	if len(result.Encounters) != 2 || len(result.Players) != 1 {
+		t.Fatalf("Expected 2 encounters and 1 player, got %d and %d", len(result.Encounters), len(result.Players))
+	}
+
+	casts := func(encounter *proto.RaidNightEncounterResult) float64 {
+		for _, action := range encounter.Result.RaidMetrics.Parties[0].Players[0].Actions {
+			if ProtoToActionID(action.Id) == dynamite {
+				return float64(action.Targets[0].Casts) / 5
+			}
+		}
+		return 0
+	}
+	if first := casts(result.Encounters[0]); first != 2 {
+		t.Fatalf("Expected 2 dynamites in the first encounter, got %0.1f", first)
+	}
+	if second := casts(result.Encounters[1]); second != 1 {
+		t.Fatalf("Expected the last dynamite in the second encounter, got %0.1f", second)
+	}
+
+	second := result.Encounters[1]
+	if second.PullTimeSeconds != 100 {
+		t.Fatalf("Expected the second pull at 100s, got %0.1fs", second.PullTimeSeconds)
+	}
+	player := second.Raid.Parties[0].Players[0]
+	if player.Buffs.SongflowerSerenade {
+		t.Fatalf("Expected Songflower Serenade to be lost by the second pull")
+	}
+	if len(player.PullState.Cooldowns) == 0 {
+		t.Fatalf("Expected the explosives cooldown to carry over")
+	}
+	if uses := player.PullState.UsesLeft[0].Uses; uses != 1 {
+		t.Fatalf("Expected 1 dynamite left, got %0.1f", uses)
+	}
+
+	if result.CombatSeconds != 180 || result.NightSeconds != 190 {
+		t.Fatalf("Unexpected night length %0.1fs in combat of %0.1fs", result.CombatSeconds, result.NightSeconds)
+	}
+	if result.TotalDamage <= 0 || result.Players[0].TotalDamage != result.TotalDamage {
+		t.Fatalf("Expected the engineer to deal all of the damage, got %0.1f of %0.1f", result.Players[0].TotalDamage, result.TotalDamage)
+	}
+}
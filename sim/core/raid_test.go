@@ -0,0 +1,41 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+type battleShoutProvider struct {
+	*TargetDummy
+}
+
+func (provider battleShoutProvider) ClearProvidedBuffs(raidBuffs *proto.RaidBuffs) {
+	raidBuffs.BattleShout = proto.TristateEffect_TristateEffectMissing
+}
+
+func TestMemberRaidBuffs(t *testing.T) {
+	raidBuffs := &proto.RaidBuffs{
+		BattleShout:          proto.TristateEffect_TristateEffectImproved,
+		StrengthOfEarthTotem: proto.TristateEffect_TristateEffectRegular,
+	}
+
+	withProvider := &Party{}
+	withProvider.Players = []Agent{battleShoutProvider{NewTargetDummy(0, withProvider, 0)}}
+	partyRaidBuffs := withProvider.getMemberRaidBuffs(raidBuffs)
+	if partyRaidBuffs.BattleShout != proto.TristateEffect_TristateEffectMissing {
+		t.Fatalf("Battle Shout toggle should be replaced by the party member")
+	}
+	if partyRaidBuffs.StrengthOfEarthTotem != proto.TristateEffect_TristateEffectRegular {
+		t.Fatalf("Toggles the party member doesn't provide should be kept")
+	}
+	if raidBuffs.BattleShout != proto.TristateEffect_TristateEffectImproved {
+		t.Fatalf("Raid buffs of other parties shouldn't change")
+	}
+
+	withoutProvider := &Party{}
+	withoutProvider.Players = []Agent{NewTargetDummy(1, withoutProvider, 0)}
+	if partyRaidBuffs := withoutProvider.getMemberRaidBuffs(raidBuffs); partyRaidBuffs.BattleShout != proto.TristateEffect_TristateEffectImproved {
+		t.Fatalf("Parties without a provider should keep the buff bot toggle")
+	}
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// Re-runs the single iteration of a raid sim which used the given seed, e.g. the
+// max_seed or min_seed of a DistributionMetrics, with debug logs enabled.
+//
+// Every iteration reseeds the sim with RandomSeed + its index (see Simulation.Reseed),
+// so running one iteration starting from that seed reproduces it exactly.
+func ReplayRaidSimIteration(request *proto.RaidSimRequest, seed int64) *proto.RaidSimResult {
+	if seed == 0 {
+		// A seed of 0 would be replaced with a random one.
+		return &proto.RaidSimResult{Error: &proto.ErrorOutcome{Message: "Can't replay an iteration with seed 0"}}
+	}
+
+	request = googleProto.Clone(request).(*proto.RaidSimRequest)
+	if request.SimOptions == nil {
+		request.SimOptions = &proto.SimOptions{}
+	}
+	request.SimOptions.RandomSeed = seed
+	request.SimOptions.Iterations = 1
+	request.SimOptions.Debug = true
+	request.SimOptions.DebugFirstIteration = true
+
+	return RunSim(request, nil, simsignals.CreateSignals())
+}
+
+// Checks that a replayed iteration produced the same results that were recorded for
+// its seed in the original sim. Returns the number of distributions checked.
+func VerifyReplay(original *proto.RaidSimResult, replay *proto.RaidSimResult, seed int64) (int, error) {
+	if replay.Error != nil {
+		return 0, fmt.Errorf("replay failed: %s", replay.Error.Message)
+	}
+
+	numChecked := 0
+	check := func(name string, originalDist *proto.DistributionMetrics, replayDist *proto.DistributionMetrics) error {
+		if originalDist == nil || replayDist == nil {
+			return nil
+		}
+		for _, recorded := range []struct {
+			label string
+			seed  int64
+			value float64
+		}{
+			{"max", originalDist.MaxSeed, originalDist.Max},
+			{"min", originalDist.MinSeed, originalDist.Min},
+		} {
+			if recorded.seed != seed {
+				continue
+			}
+			numChecked++
+			if math.Abs(recorded.value-replayDist.Avg) > 1e-6*max(1, math.Abs(recorded.value)) {
+				return fmt.Errorf("%s: replay gave %0.3f but the recorded %s was %0.3f", name, replayDist.Avg, recorded.label, recorded.value)
+			}
+		}
+		return nil
+	}
+
+	if err := check("Raid DPS", original.RaidMetrics.GetDps(), replay.RaidMetrics.GetDps()); err != nil {
+		return numChecked, err
+	}
+	for i, party := range original.RaidMetrics.GetParties() {
+		for j, player := range party.GetPlayers() {
+			replayPlayer := replay.RaidMetrics.Parties[i].Players[j]
+			if err := check(player.Name+" DPS", player.Dps, replayPlayer.Dps); err != nil {
+				return numChecked, err
+			}
+			if err := check(player.Name+" HPS", player.Hps, replayPlayer.Hps); err != nil {
+				return numChecked, err
+			}
+		}
+	}
+
+	if numChecked == 0 {
+		return 0, fmt.Errorf("seed %d is not the min or max seed of any recorded distribution", seed)
+	}
+	return numChecked, nil
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+func TestVerifyReplay(t *testing.T) {
+	original := &proto.RaidSimResult{
+		RaidMetrics: &proto.RaidMetrics{
+			Dps: &proto.DistributionMetrics{Max: 1500, MaxSeed: 7, Min: 1200, MinSeed: 9},
+		},
+	}
+	replayWithDps := func(dps float64) *proto.RaidSimResult {
+		return &proto.RaidSimResult{
+			RaidMetrics: &proto.RaidMetrics{Dps: &proto.DistributionMetrics{Avg: dps}},
+		}
+	}
+
+	if numChecked, err := VerifyReplay(original, replayWithDps(1500), 7); err != nil || numChecked != 1 {
+		t.Errorf("Expected matching max replay, got %d checked, err: %v", numChecked, err)
+	}
+	if _, err := VerifyReplay(original, replayWithDps(1210), 9); err == nil {
+		t.Errorf("Expected mismatching min replay to fail")
+	}
+	if _, err := VerifyReplay(original, replayWithDps(1500), 8); err == nil {
+		t.Errorf("Expected unrecorded seed to fail")
+	}
+}
+
+func TestReplayRaidSimIteration(t *testing.T) {
+	request := &proto.RaidSimRequest{
+		Raid: SinglePlayerRaidProto(&proto.Player{
+			Name:        "Engineer",
+			Class:       proto.Class_ClassShaman,
+			Profession1: proto.Profession_Engineering,
+			Consumes: &proto.Consumes{
+				FillerExplosive: proto.Explosive_ExplosiveDenseDynamite,
+			},
+			Spec:      &proto.Player_ElementalShaman{},
+			Equipment: &proto.EquipmentSpec{},
+			Rotation: &proto.APLRotation{
+				Type: proto.APLRotation_TypeAPL,
+				PriorityList: []*proto.APLListItem{
+					{Action: &proto.APLAction{Action: &proto.APLAction_AutocastOtherCooldowns{AutocastOtherCooldowns: &proto.APLActionAutocastOtherCooldowns{}}}},
+				},
+			},
+		}, &proto.PartyBuffs{}, &proto.RaidBuffs{}, &proto.Debuffs{}),
+		Encounter: &proto.Encounter{
+			Targets: []*proto.Target{
+				{Level: 63, MobType: proto.MobType_MobTypeDemon},
+			},
+			Duration: 60,
+		},
+		SimOptions: &proto.SimOptions{
+			Iterations: 20,
+			RandomSeed: 101,
+			IsTest:     true,
+		},
+	}
+
+	original := RunSim(request, nil, simsignals.CreateSignals())
+	if original.Error != nil {
+		t.Fatalf("Sim failed: %s", original.Error.Message)
+	}
+	dps := original.RaidMetrics.Dps
+	if dps.Max == dps.Min {
+		t.Fatalf("Expected the dynamite damage to vary between iterations, got %0.1f DPS", dps.Max)
+	}
+
+	for _, seed := range []int64{dps.MaxSeed, dps.MinSeed} {
+		replay := ReplayRaidSimIteration(request, seed)
+		if numChecked, err := VerifyReplay(original, replay, seed); err != nil || numChecked == 0 {
+			t.Fatalf("Replay of seed %d didn't match, %d checked, err: %v", seed, numChecked, err)
+		}
+	}
+	if request.SimOptions.Iterations != 20 || request.SimOptions.Debug {
+		t.Fatalf("Replay modified the request's sim options: %v", request.SimOptions)
+	}
+
+	request.SimOptions = nil
+	if replay := ReplayRaidSimIteration(request, dps.MaxSeed); replay.Error != nil {
+		t.Fatalf("Replay without sim options failed: %s", replay.Error.Message)
+	}
+}
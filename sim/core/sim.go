@@ -32,6 +32,9 @@ type Simulation struct {
 	isTest    bool
 	testRands map[string]Rand
 
+	// Metrics of the same sim without the threat cap, see threat_cap.go.
+	uncappedMetrics *proto.RaidMetrics
+
 	// Current Simulation State
 	pendingActions []*PendingAction
 	CurrentTime    time.Duration // duration that has elapsed in the sim since starting
@@ -61,6 +64,11 @@ type Simulation struct {
 
 	minTaskTime time.Duration
 	tasks       []Task
+
+	spellBatchWindow time.Duration // 0 without spell batching
+	spellBatch       *spellBatch   // Next batch with pending effects
+	lastSpellBatchAt time.Duration
+	inSpellBatch     bool
 }
 
 func (sim *Simulation) rescheduleTracker(trackerTime time.Duration) {
@@ -176,6 +184,11 @@ func runSim(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, ski
 		}
 	}
 
+	// The uncapped sim runs first, so the final result is complete when it's reported.
+	if rsr.Encounter.GetThreatCap() != nil && !skipPresim {
+		sim.uncappedMetrics = runUncappedSim(rsr, signals)
+	}
+
 	// using a variable here allows us to mutate it in the deferred recover, sending out error info
 	result = sim.run()
 
@@ -204,6 +217,8 @@ func newSimWithEnv(env *Environment, simOptions *proto.SimOptions, signals simsi
 		testRands: make(map[string]Rand),
 
 		Signals: signals,
+
+		spellBatchWindow: DurationFromSeconds(max(simOptions.SpellBatchWindow, 0)),
 	}
 }
 
@@ -349,6 +364,10 @@ func (sim *Simulation) run() *proto.RaidSimResult {
 		IterationsDone:         sim.Options.Iterations,
 	}
 
+	if sim.uncappedMetrics != nil {
+		addThreatCapMetrics(result.RaidMetrics, sim.uncappedMetrics)
+	}
+
 	// Final progress report
 	if sim.ProgressReport != nil {
 		sim.ProgressReport(&proto.ProgressMetrics{TotalIterations: sim.Options.Iterations, CompletedIterations: sim.Options.Iterations, Dps: result.RaidMetrics.Dps.Avg, FinalRaidResult: result})
@@ -417,6 +436,10 @@ func (sim *Simulation) reset() {
 	sim.tasks = sim.tasks[:0]
 	sim.minTaskTime = NeverExpires
 
+	sim.spellBatch = nil
+	sim.lastSpellBatchAt = -NeverExpires
+	sim.inSpellBatch = false
+
 	sim.Environment.reset(sim)
 
 	sim.initManaTickAction()
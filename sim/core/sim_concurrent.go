@@ -90,6 +90,37 @@ func (rsrc *raidSimResultCombiner) newUnitMetrics(baseUnit *proto.UnitMetrics) *
 		}
 	}
 
+	for _, slot := range baseUnit.DebuffSlots {
+		newUm.DebuffSlots = append(newUm.DebuffSlots, &proto.DebuffSlotMetrics{
+			Id:          slot.Id,
+			TargetIndex: slot.TargetIndex,
+		})
+	}
+
+	if survivability := baseUnit.Survivability; survivability != nil {
+		newUm.Survivability = &proto.TankSurvivabilityMetrics{
+			WindowSeconds:        survivability.WindowSeconds,
+			WorstWindow:          rsrc.newDistMetrics(),
+			WorstWindowHist:      make(map[int32]int32),
+			Outcomes:             &proto.MeleeOutcomeBreakdown{},
+			AttackTable:          survivability.AttackTable,
+			MaxHealth:            survivability.MaxHealth,
+			EffectiveHealth:      survivability.EffectiveHealth,
+			CrushImmunityDeficit: survivability.CrushImmunityDeficit,
+		}
+	}
+
+	if baseUnit.EndState != nil {
+		newUm.EndState = &proto.PullState{}
+	}
+
+	for _, trinketUses := range baseUnit.TrinketUses {
+		newUm.TrinketUses = append(newUm.TrinketUses, &proto.TrinketUseMetrics{
+			Id:   trinketUses.Id,
+			Slot: trinketUses.Slot,
+		})
+	}
+
 	for i, pet := range baseUnit.Pets {
 		newUm.Pets[i] = rsrc.newUnitMetrics(pet)
 	}
@@ -199,6 +230,11 @@ func (rsrc *raidSimResultCombiner) addActionMetrics(unit *proto.UnitMetrics, add
 		baseTgt.CrushDamage += addTgt.CrushDamage
 		baseTgt.BlockDamage += addTgt.BlockDamage
 		baseTgt.BlockedCritDamage += addTgt.BlockedCritDamage
+		baseTgt.PartialResists_0 += addTgt.PartialResists_0
+		baseTgt.PartialResists_25 += addTgt.PartialResists_25
+		baseTgt.PartialResists_50 += addTgt.PartialResists_50
+		baseTgt.PartialResists_75 += addTgt.PartialResists_75
+		baseTgt.ResistedDamageLost += addTgt.ResistedDamageLost
 		baseTgt.Threat += addTgt.Threat
 		baseTgt.Healing += addTgt.Healing
 		baseTgt.CritHealing += addTgt.CritHealing
@@ -256,6 +292,9 @@ func (rsrc *raidSimResultCombiner) combineUnitMetrics(base *proto.UnitMetrics, a
 
 	base.SecondsOomAvg += add.SecondsOomAvg * weight
 	base.ChanceOfDeath += add.ChanceOfDeath * weight
+	base.SecondsWithAggroAvg += add.SecondsWithAggroAvg * weight
+	base.ChanceOfPullingAggro += add.ChanceOfPullingAggro * weight
+	base.DpsLostToThreatCap += add.DpsLostToThreatCap * weight
 
 	for _, addAction := range add.Actions {
 		rsrc.addActionMetrics(base, addAction)
@@ -269,11 +308,43 @@ func (rsrc *raidSimResultCombiner) combineUnitMetrics(base *proto.UnitMetrics, a
 		rsrc.addResourceMetrics(base, addResource)
 	}
 
+	for i, addSlot := range add.DebuffSlots {
+		base.DebuffSlots[i].DeniedAvg += addSlot.DeniedAvg * weight
+		base.DebuffSlots[i].EvictedAvg += addSlot.EvictedAvg * weight
+	}
+
+	if add.Survivability != nil {
+		rsrc.combineSurvivabilityMetrics(base.Survivability, add.Survivability, isLast, weight)
+	}
+	if add.EndState != nil {
+		combinePullStates(base.EndState, add.EndState, weight)
+	}
+	combineTrinketUses(base.TrinketUses, add.TrinketUses, isLast, weight)
+
 	for i, addPet := range add.Pets {
 		rsrc.combineUnitMetrics(base.Pets[i], addPet, isLast, weight)
 	}
 }
 
+func (rsrc *raidSimResultCombiner) combineSurvivabilityMetrics(base *proto.TankSurvivabilityMetrics, add *proto.TankSurvivabilityMetrics, isLast bool, weight float64) {
+	rsrc.combineDistMetrics(base.WorstWindow, add.WorstWindow, isLast, weight)
+	for bin, count := range add.WorstWindowHist {
+		base.WorstWindowHist[bin] += count
+	}
+
+	base.Outcomes.Miss += add.Outcomes.Miss * weight
+	base.Outcomes.Dodge += add.Outcomes.Dodge * weight
+	base.Outcomes.Parry += add.Outcomes.Parry * weight
+	base.Outcomes.Block += add.Outcomes.Block * weight
+	base.Outcomes.Crit += add.Outcomes.Crit * weight
+	base.Outcomes.Crush += add.Outcomes.Crush * weight
+	base.Outcomes.Hit += add.Outcomes.Hit * weight
+
+	if isLast {
+		base.SpikeDamage = spikeDamageToProto(base.WorstWindowHist)
+	}
+}
+
 func (rsrc *raidSimResultCombiner) AddResult(result *proto.RaidSimResult, isLast bool, weight float64) {
 	rsrc.combineDistMetrics(rsrc.Combined.RaidMetrics.Dps, result.RaidMetrics.Dps, isLast, weight)
 	rsrc.combineDistMetrics(rsrc.Combined.RaidMetrics.Hps, result.RaidMetrics.Hps, isLast, weight)
@@ -124,6 +124,8 @@ type Spell struct {
 
 	casts int // Sum of casts on all targets, for efficient CPM calculation
 
+	spellBatchesPending int32 // Casts waiting for a spell batch to land
+
 	// Performs the actions of this spell.
 	ApplyEffects ApplySpellResults
 
@@ -354,6 +356,20 @@ func (unit *Unit) GetSpell(actionID ActionID) *Spell {
 	return nil
 }
 
+// Retrieves the given rank of a spell, by looking for the spell with the same SpellCode
+// and that rank. Returns nil if the unit doesn't know that rank.
+func (unit *Unit) GetSpellRank(spell *Spell, rank int) *Spell {
+	if spell.SpellCode == 0 {
+		return nil
+	}
+	for _, rankSpell := range unit.Spellbook {
+		if rankSpell.SpellCode == spell.SpellCode && rankSpell.Rank == rank {
+			return rankSpell
+		}
+	}
+	return nil
+}
+
 // Retrieves an existing spell with the same ID as the config uses, or registers it if there is none.
 func (unit *Unit) GetOrRegisterSpell(config SpellConfig) *Spell {
 	registered := unit.GetSpell(config.ActionID)
@@ -451,6 +467,7 @@ func (spell *Spell) reset(_ *Simulation) {
 	}
 	spell.casts = 0
 	spell.LastCastAt = 0
+	spell.spellBatchesPending = 0
 }
 
 func (spell *Spell) SetMetricsSplit(splitIdx int32) {
@@ -512,6 +529,11 @@ func (spell *Spell) CanCast(sim *Simulation, target *Unit) bool {
 		return false
 	}
 
+	// The client waits for the previous cast to land before casting the spell again
+	if spell.spellBatchesPending > 0 {
+		return false
+	}
+
 	// While moving only instant casts are possible
 	if spell.DefaultCast.CastTime > 0 && spell.Unit.IsMoving() {
 		//if sim.Log != nil {
@@ -520,6 +542,11 @@ func (spell *Spell) CanCast(sim *Simulation, target *Unit) bool {
 		return false
 	}
 
+	// Melee abilities can't reach the target while an encounter mechanic keeps the unit away
+	if spell.ProcMask.Matches(ProcMaskMeleeSpecial) && spell.Unit.IsOutOfRange() {
+		return false
+	}
+
 	// While casting no other action is possible except rare cast-while-casting spells
 	if spell.Unit.IsCasting(sim) {
 		//if sim.Log != nil {
@@ -570,6 +597,15 @@ func (spell *Spell) Cast(sim *Simulation, target *Unit) bool {
 }
 
 func (spell *Spell) applyEffects(sim *Simulation, target *Unit) {
+	if sim.SpellBatchingEnabled() && !sim.inSpellBatch {
+		spell.spellBatchesPending++
+		sim.DoInNextSpellBatch(func(sim *Simulation) {
+			spell.spellBatchesPending--
+			spell.applyEffects(sim, target)
+		})
+		return
+	}
+
 	spell.SpellMetrics[target.UnitIndex].Casts++
 	spell.casts++
 
@@ -611,6 +647,34 @@ func (spell *Spell) ExpectedTickDamageFromCurrentSnapshot(sim *Simulation, targe
 	return result.Damage
 }
 
+// Expected damage of a cast of the spell, including all ticks of its dot. Spells without
+// expected damage calculators use their average damage and healing per cast so far.
+func (spell *Spell) ExpectedDamagePerCast(sim *Simulation, target *Unit) float64 {
+	if spell.expectedInitialDamageInternal == nil && spell.expectedTickDamageInternal == nil {
+		var casts int32
+		var total float64
+		for _, splitMetrics := range spell.splitSpellMetrics {
+			for _, targetMetrics := range splitMetrics {
+				casts += targetMetrics.Casts
+				total += targetMetrics.TotalDamage + targetMetrics.TotalHealing + targetMetrics.TotalShielding
+			}
+		}
+		if casts == 0 {
+			return 0
+		}
+		return total / float64(casts)
+	}
+
+	damage := 0.0
+	if spell.expectedInitialDamageInternal != nil {
+		damage += spell.ExpectedInitialDamage(sim, target)
+	}
+	if spell.expectedTickDamageInternal != nil && spell.dots != nil {
+		damage += spell.ExpectedTickDamage(sim, target) * float64(spell.Dot(target).NumberOfTicks)
+	}
+	return damage
+}
+
 // Time until either the cast is finished or GCD is ready again, whichever is longer
 func (spell *Spell) EffectiveCastTime() time.Duration {
 	// TODO: this is wrong for spells like shadowfury, that have a GCD of less than 1s
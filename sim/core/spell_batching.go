@@ -0,0 +1,86 @@
+package core
+
+import (
+	"time"
+)
+
+// Effects waiting for a server spell batch.
+type spellBatch struct {
+	at      time.Duration
+	effects []func(*Simulation)
+}
+
+// Whether the results of casts, swings and procs are quantized into server batches.
+func (sim *Simulation) SpellBatchingEnabled() bool {
+	return sim.spellBatchWindow > 0
+}
+
+// Returns when the next spell batch lands. Without spell batching, this is the
+// default batch window from now.
+//
+// A batch which already landed at the current time is over, so e.g. a reaction to a
+// dodge in a batch lands in the following one.
+func (sim *Simulation) NextSpellBatchAt() time.Duration {
+	if !sim.SpellBatchingEnabled() {
+		return sim.CurrentTime + SpellBatchWindow
+	}
+
+	window := sim.spellBatchWindow
+	at := sim.CurrentTime
+	if rem := at % window; rem > 0 {
+		at += window - rem
+	} else if rem < 0 {
+		at -= rem
+	}
+	if at <= sim.lastSpellBatchAt {
+		at = sim.lastSpellBatchAt + window
+	}
+	return at
+}
+
+// Performs the action when the next spell batch lands. Actions taken while a batch is
+// landing, e.g. procs from its hits, are part of that batch and happen immediately.
+// Without spell batching, the action happens after the default batch window.
+func (sim *Simulation) DoInNextSpellBatch(action func(*Simulation)) {
+	if !sim.SpellBatchingEnabled() {
+		StartDelayedAction(sim, DelayedActionOptions{
+			DoAt:     sim.CurrentTime + SpellBatchWindow,
+			OnAction: action,
+		})
+		return
+	}
+
+	if sim.inSpellBatch {
+		action(sim)
+		return
+	}
+
+	at := sim.NextSpellBatchAt()
+	if sim.spellBatch == nil || sim.spellBatch.at != at {
+		batch := &spellBatch{at: at}
+		sim.spellBatch = batch
+		sim.AddPendingAction(&PendingAction{
+			NextActionAt: at,
+			Priority:     ActionPrioritySpellBatch,
+			OnAction: func(sim *Simulation) {
+				sim.landSpellBatch(batch)
+			},
+		})
+	}
+	sim.spellBatch.effects = append(sim.spellBatch.effects, action)
+}
+
+// Applies the batch's effects in the order they were queued, as the server does, so
+// auras and procs from earlier effects affect later ones.
+func (sim *Simulation) landSpellBatch(batch *spellBatch) {
+	if sim.spellBatch == batch {
+		sim.spellBatch = nil
+	}
+	sim.lastSpellBatchAt = batch.at
+
+	sim.inSpellBatch = true
+	for _, effect := range batch.effects {
+		effect(sim)
+	}
+	sim.inSpellBatch = false
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+// Runs the sim's pending actions up to and including the given time.
+func runSpellBatchSimUntil(sim *Simulation, until time.Duration) {
+	for {
+		if next := sim.pendingActions[len(sim.pendingActions)-1]; next.NextActionAt > until {
+			return
+		}
+		if finished := sim.Step(); finished {
+			return
+		}
+	}
+}
+
+func doSpellBatchTestAt(sim *Simulation, at time.Duration, action func(*Simulation)) {
+	StartDelayedAction(sim, DelayedActionOptions{DoAt: at, OnAction: action})
+}
+
+func TestSpellBatchingDisabled(t *testing.T) {
+	sim := SetupFakeSim()
+
+	var landedAt time.Duration
+	doSpellBatchTestAt(sim, time.Millisecond*130, func(sim *Simulation) {
+		if at := sim.NextSpellBatchAt(); at != time.Millisecond*140 {
+			t.Fatalf("Expected the default batch window without spell batching, got %s", at)
+		}
+		sim.DoInNextSpellBatch(func(sim *Simulation) { landedAt = sim.CurrentTime })
+	})
+	runSpellBatchSimUntil(sim, time.Second)
+
+	if landedAt != time.Millisecond*140 {
+		t.Fatalf("Expected the action to happen after the default batch window, got %s", landedAt)
+	}
+}
+
+func TestSpellBatchingQuantizesEffects(t *testing.T) {
+	sim := SetupFakeSim()
+	sim.spellBatchWindow = time.Millisecond * 400
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	target := sim.GetTargetUnit(0)
+
+	var order []string
+	var landedAt []time.Duration
+	record := func(label string) func(*Simulation) {
+		return func(sim *Simulation) {
+			order = append(order, label)
+			landedAt = append(landedAt, sim.CurrentTime)
+		}
+	}
+
+	doSpellBatchTestAt(sim, time.Millisecond*130, func(sim *Simulation) {
+		fa.Spell.Cast(sim, target)
+		if fa.Dot.IsActive() {
+			t.Fatalf("Expected the cast to land at the end of the batch")
+		}
+		if fa.Spell.CanCast(sim, target) {
+			t.Fatalf("Expected the spell to wait for its previous cast to land")
+		}
+		sim.DoInNextSpellBatch(record("first"))
+	})
+	doSpellBatchTestAt(sim, time.Millisecond*390, func(sim *Simulation) { sim.DoInNextSpellBatch(record("second")) })
+	doSpellBatchTestAt(sim, time.Millisecond*410, func(sim *Simulation) { sim.DoInNextSpellBatch(record("third")) })
+
+	runSpellBatchSimUntil(sim, time.Millisecond*400)
+	if !fa.Dot.IsActive() || fa.Dot.StartedAt() != time.Millisecond*400 {
+		t.Fatalf("Expected the dot to be applied when the batch lands")
+	}
+
+	runSpellBatchSimUntil(sim, time.Second)
+	expectedOrder := []string{"first", "second", "third"}
+	expectedAt := []time.Duration{time.Millisecond * 400, time.Millisecond * 400, time.Millisecond * 800}
+	for i := range expectedOrder {
+		if order[i] != expectedOrder[i] || landedAt[i] != expectedAt[i] {
+			t.Fatalf("Expected %s to land at %s, got %v at %v", expectedOrder[i], expectedAt[i], order, landedAt)
+		}
+	}
+}
+
+func TestSpellBatchingReactions(t *testing.T) {
+	sim := SetupFakeSim()
+	sim.spellBatchWindow = time.Millisecond * 400
+
+	var procAt, reactionAt time.Duration
+	doSpellBatchTestAt(sim, time.Millisecond*130, func(sim *Simulation) {
+		// E.g. a dodge, which procs something and enables Overpower.
+		sim.DoInNextSpellBatch(func(sim *Simulation) {
+			sim.DoInNextSpellBatch(func(sim *Simulation) { procAt = sim.CurrentTime })
+			doSpellBatchTestAt(sim, sim.CurrentTime, func(sim *Simulation) {
+				sim.DoInNextSpellBatch(func(sim *Simulation) { reactionAt = sim.CurrentTime })
+			})
+		})
+	})
+	runSpellBatchSimUntil(sim, time.Second)
+
+	if procAt != time.Millisecond*400 {
+		t.Fatalf("Expected procs to land in the same batch, got %s", procAt)
+	}
+	if reactionAt != time.Millisecond*800 {
+		t.Fatalf("Expected reactions to land in the following batch, got %s", reactionAt)
+	}
+}
+
+func TestSpellBatchingRecastsWhenBatchLands(t *testing.T) {
+	// The fake agent's spell has no GCD, cast time or cooldown, so only its pending batch
+	// keeps the rotation from casting it again.
+	result := RunSim(&proto.RaidSimRequest{
+		Raid: SinglePlayerRaidProto(&proto.Player{
+			Name:      "Caster",
+			Class:     proto.Class_ClassShaman,
+			Consumes:  &proto.Consumes{},
+			Spec:      &proto.Player_ElementalShaman{},
+			Equipment: &proto.EquipmentSpec{},
+			Rotation: &proto.APLRotation{
+				Type: proto.APLRotation_TypeAPL,
+				PriorityList: []*proto.APLListItem{
+					{Action: &proto.APLAction{Action: &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{
+						SpellId: ActionID{SpellID: 42}.ToProto(),
+					}}}},
+				},
+			},
+		}, &proto.PartyBuffs{}, &proto.RaidBuffs{}, &proto.Debuffs{}),
+		Encounter: &proto.Encounter{
+			Targets:  []*proto.Target{{Level: 63, MobType: proto.MobType_MobTypeDemon}},
+			Duration: 10,
+		},
+		SimOptions: &proto.SimOptions{Iterations: 1, SpellBatchWindow: 0.4},
+	}, nil, simsignals.CreateSignals())
+	if result.Error != nil {
+		t.Fatalf("Sim failed: %s", result.Error.Message)
+	}
+
+	// The rotation casts again as soon as each batch lands, so a cast lands in the batch
+	// at the start of the fight and in every batch after it.
+	if casts := result.RaidMetrics.Parties[0].Players[0].Actions[0].Targets[0].Casts; casts != 26 {
+		t.Fatalf("Expected a cast in each of the 26 batches, got %d", casts)
+	}
+}
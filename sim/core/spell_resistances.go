@@ -56,6 +56,17 @@ func (spell *Spell) ResistanceMultiplier(sim *Simulation, isPeriodic bool, attac
 	}
 }
 
+// Whether damage from this spell rolls for partial resists against the target, see ResistanceMultiplier().
+func (spell *Spell) canPartiallyResist(target *Unit) bool {
+	if spell.Flags.Matches(SpellFlagIgnoreResists | SpellFlagBinary) {
+		return false
+	}
+	if spell.SpellSchool.Matches(SpellSchoolPhysical) {
+		return spell.SchoolIndex != stats.SchoolIndexPhysical && !MultiSchoolShouldUseArmor(spell, target)
+	}
+	return true
+}
+
 // Decide whether to use armor for physical multi school spells.
 //
 // TODO: This is most likely not accurate for the case: armor near resistance but not 0
@@ -95,6 +106,15 @@ func (at *AttackTable) GetBinaryHitChance(spell *Spell) float64 {
 	return at.Defender.binaryHitChance(spell, at.Attacker)
 }
 
+// Resistance stat for each magic school that has one.
+var schoolResistanceStats = map[stats.SchoolIndex]stats.Stat{
+	stats.SchoolIndexArcane: stats.ArcaneResistance,
+	stats.SchoolIndexFire:   stats.FireResistance,
+	stats.SchoolIndexFrost:  stats.FrostResistance,
+	stats.SchoolIndexNature: stats.NatureResistance,
+	stats.SchoolIndexShadow: stats.ShadowResistance,
+}
+
 // Only for base schools!
 func (unit *Unit) GetResistanceForSchool(schoolIndex stats.SchoolIndex) float64 {
 	switch schoolIndex {
@@ -242,3 +242,32 @@ func Test_ResistBinary(t *testing.T) {
 		}
 	}
 }
+
+func Test_CanPartiallyResist(t *testing.T) {
+	target := &Unit{stats: stats.Stats{stats.Armor: 3731}}
+
+	for _, tc := range []struct {
+		spell    *Spell
+		expected bool
+	}{
+		{&Spell{SpellSchool: SpellSchoolFire, SchoolIndex: stats.SchoolIndexFire}, true},
+		{&Spell{SpellSchool: SpellSchoolFire, SchoolIndex: stats.SchoolIndexFire, Flags: SpellFlagBinary}, false},
+		{&Spell{SpellSchool: SpellSchoolShadow, SchoolIndex: stats.SchoolIndexShadow, Flags: SpellFlagIgnoreResists}, false},
+		{&Spell{SpellSchool: SpellSchoolPhysical, SchoolIndex: stats.SchoolIndexPhysical}, false},
+	} {
+		if actual := tc.spell.canPartiallyResist(target); actual != tc.expected {
+			t.Errorf("School %d, flags %d: expected %t, got %t", tc.spell.SchoolIndex, tc.spell.Flags, tc.expected, actual)
+		}
+	}
+
+	for outcome, expected := range map[HitOutcome]int{
+		OutcomeHit:                      0,
+		OutcomeHit | OutcomePartial1_4:  1,
+		OutcomeCrit | OutcomePartial2_4: 2,
+		OutcomeHit | OutcomePartial3_4:  3,
+	} {
+		if actual := outcome.PartialResistIndex(); actual != expected {
+			t.Errorf("Outcome %s: expected partial resist index %d, got %d", outcome, expected, actual)
+		}
+	}
+}
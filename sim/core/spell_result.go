@@ -431,6 +431,16 @@ func (spell *Spell) dealDamageInternal(sim *Simulation, isPeriodic bool, result
 			spell.SpellMetrics[result.Target.UnitIndex].TotalCrushDamage += result.Damage
 		}
 		spell.SpellMetrics[result.Target.UnitIndex].TotalThreat += result.Threat
+		if result.Target.ThreatTable != nil {
+			result.Target.ThreatTable.AddThreat(sim, spell.Unit, result.Threat)
+		}
+
+		if result.Landed() && spell.canPartiallyResist(result.Target) {
+			spell.SpellMetrics[result.Target.UnitIndex].PartialResists[result.Outcome.PartialResistIndex()]++
+			if isPartialResist {
+				spell.SpellMetrics[result.Target.UnitIndex].TotalResistedDamageLost += result.Damage/result.ResistanceMultiplier - result.Damage
+			}
+		}
 	}
 
 	// Mark total damage done in raid so far for health based fights.
@@ -543,6 +553,10 @@ func (spell *Spell) dealHealingInternal(sim *Simulation, isPeriodic bool, result
 	}
 	spell.SpellMetrics[result.Target.UnitIndex].TotalHealing += result.Damage
 	spell.SpellMetrics[result.Target.UnitIndex].TotalThreat += result.Threat
+	if sim.Encounter.UseThreatTable && sim.CurrentTime >= 0 {
+		// Healing threat is split between all enemies.
+		spell.Unit.addThreatToAllTargets(sim, result.Threat/float64(sim.GetNumTargets()))
+	}
 	if result.Target.HasHealthBar() {
 		result.Target.GainHealth(sim, result.Damage, spell.HealthMetrics(result.Target))
 	}
@@ -0,0 +1,273 @@
+package core
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/stats"
+)
+
+// Window length used when the healing model doesn't set a burst window.
+const defaultSurvivabilityWindow = time.Second * 6
+
+// Percentiles of the worst damage windows reported as spike damage.
+var spikeDamagePercentiles = []float64{0.5, 0.9, 0.95, 0.99}
+
+type damageTakenEvent struct {
+	at     time.Duration
+	damage float64 // % of max health
+}
+
+// Counts of the attacker's auto attack outcomes, in the order of MeleeOutcomeBreakdown.
+type meleeOutcomeCounts [7]int64
+
+const (
+	meleeOutcomeMiss = iota
+	meleeOutcomeDodge
+	meleeOutcomeParry
+	meleeOutcomeBlock
+	meleeOutcomeCrit
+	meleeOutcomeCrush
+	meleeOutcomeHit
+)
+
+func (counts meleeOutcomeCounts) toProto() *proto.MeleeOutcomeBreakdown {
+	total := 0.0
+	for _, count := range counts {
+		total += float64(count)
+	}
+	total = max(total, 1)
+	return &proto.MeleeOutcomeBreakdown{
+		Miss:  float64(counts[meleeOutcomeMiss]) / total,
+		Dodge: float64(counts[meleeOutcomeDodge]) / total,
+		Parry: float64(counts[meleeOutcomeParry]) / total,
+		Block: float64(counts[meleeOutcomeBlock]) / total,
+		Crit:  float64(counts[meleeOutcomeCrit]) / total,
+		Crush: float64(counts[meleeOutcomeCrush]) / total,
+		Hit:   float64(counts[meleeOutcomeHit]) / total,
+	}
+}
+
+// Survivability metrics of a tank.
+type tankSurvivability struct {
+	tank     *Unit
+	attacker *Unit
+	window   time.Duration
+
+	// Values for the current iteration.
+	damageTaken []damageTakenEvent
+
+	// Aggregate values. These are updated after each iteration.
+	worstWindow     DistributionMetrics
+	worstWindowHist map[int32]int32
+	outcomes        meleeOutcomeCounts
+
+	// From the tank's stats at the start of the first iteration.
+	attackTable          *proto.MeleeOutcomeBreakdown
+	maxHealth            float64
+	effectiveHealth      float64
+	crushImmunityDeficit float64
+}
+
+// Tracks the survivability of a player tanking a target, against the first target
+// attacking them.
+func (character *Character) trackSurvivability(healingModel *proto.HealingModel) {
+	var attacker *Unit
+	for _, target := range character.Env.Encounter.TargetUnits {
+		if target.CurrentTarget == &character.Unit {
+			attacker = target
+			break
+		}
+	}
+	if attacker == nil {
+		return
+	}
+
+	survivability := &tankSurvivability{
+		tank:            &character.Unit,
+		attacker:        attacker,
+		window:          defaultSurvivabilityWindow,
+		worstWindow:     NewDistributionMetrics(),
+		worstWindowHist: make(map[int32]int32),
+	}
+	if healingModel != nil && healingModel.BurstWindow > 0 {
+		survivability.window = time.Second * time.Duration(healingModel.BurstWindow)
+	}
+	character.Metrics.survivability = survivability
+
+	character.RegisterAura(Aura{
+		Label:    "Survivability",
+		Duration: NeverExpires,
+		OnReset: func(aura *Aura, sim *Simulation) {
+			if survivability.attackTable == nil {
+				survivability.computeAttackTable()
+			}
+			aura.Activate(sim)
+		},
+		OnSpellHitTaken: func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
+			survivability.addDamage(sim, result.Damage)
+			if spell.Unit == attacker && spell.ProcMask.Matches(ProcMaskMeleeWhiteHit) {
+				survivability.addOutcome(result)
+			}
+		},
+		OnPeriodicDamageTaken: func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
+			survivability.addDamage(sim, result.Damage)
+		},
+	})
+}
+
+func (survivability *tankSurvivability) addDamage(sim *Simulation, damage float64) {
+	if damage <= 0 {
+		return
+	}
+	survivability.damageTaken = append(survivability.damageTaken, damageTakenEvent{
+		at:     sim.CurrentTime,
+		damage: damage / survivability.tank.MaxHealth() * 100,
+	})
+}
+
+func (survivability *tankSurvivability) addOutcome(result *SpellResult) {
+	switch {
+	case result.Outcome.Matches(OutcomeMiss):
+		survivability.outcomes[meleeOutcomeMiss]++
+	case result.DidDodge():
+		survivability.outcomes[meleeOutcomeDodge]++
+	case result.DidParry():
+		survivability.outcomes[meleeOutcomeParry]++
+	case result.DidBlock():
+		survivability.outcomes[meleeOutcomeBlock]++
+	case result.DidCrush():
+		survivability.outcomes[meleeOutcomeCrush]++
+	case result.DidCrit():
+		survivability.outcomes[meleeOutcomeCrit]++
+	default:
+		survivability.outcomes[meleeOutcomeHit]++
+	}
+}
+
+// Computes the attacker's attack table against the tank, the same way as
+// OutcomeEnemyMeleeWhite, and the tank's effective health.
+func (survivability *tankSurvivability) computeAttackTable() {
+	tank, attacker := survivability.tank, survivability.attacker
+	at := attacker.AttackTables[tank.UnitIndex][proto.CastType_CastTypeMainHand]
+	defense := tank.stats[stats.Defense] * DefenseRatingToChanceReduction
+
+	miss := at.BaseMissChance + attacker.PseudoStats.IncreasedMissChance + defense
+	if attacker.AutoAttacks.IsDualWielding && !attacker.PseudoStats.DisableDWMissPenalty {
+		miss += 0.19
+	}
+	dodge := at.BaseDodgeChance + tank.GetStat(stats.Dodge)/100
+	var parry, block, crush float64
+	if tank.PseudoStats.CanParry {
+		parry = at.BaseParryChance + tank.GetStat(stats.Parry)/100
+	}
+	if tank.PseudoStats.CanBlock {
+		block = at.BaseBlockChance + tank.stats[stats.Block]/BlockRatingPerBlockChance/100
+	}
+	crit := at.BaseCritChance - defense - tank.PseudoStats.ReducedCritTakenChance
+	if attacker.PseudoStats.CanCrush {
+		crush = at.BaseCrushChance
+	}
+
+	// Single roll table: each layer only gets what's left after the previous ones.
+	remaining := 1.0
+	layer := func(chance float64) float64 {
+		chance = min(max(chance, 0), remaining)
+		remaining -= chance
+		return chance
+	}
+	survivability.attackTable = &proto.MeleeOutcomeBreakdown{
+		Miss:  layer(miss),
+		Dodge: layer(dodge),
+		Parry: layer(parry),
+		Block: layer(block),
+		Crit:  layer(crit),
+		Crush: layer(crush),
+	}
+	survivability.attackTable.Hit = remaining
+
+	// Crushing blows only come off the table once the layers before them fill it.
+	if crush > 0 {
+		survivability.crushImmunityDeficit = remaining + survivability.attackTable.Crush
+	}
+
+	survivability.maxHealth = tank.MaxHealth()
+	damageTaken := at.GetArmorDamageModifier() * tank.PseudoStats.DamageTakenMultiplier *
+		tank.PseudoStats.SchoolDamageTakenMultiplier[stats.SchoolIndexPhysical]
+	if damageTaken > 0 {
+		survivability.effectiveHealth = survivability.maxHealth / damageTaken
+	}
+}
+
+// Most damage taken in any window of the iteration, in % of max health.
+func (survivability *tankSurvivability) worstWindowDamage() float64 {
+	worst, sum := 0.0, 0.0
+	first := 0
+	for _, event := range survivability.damageTaken {
+		sum += event.damage
+		for survivability.damageTaken[first].at <= event.at-survivability.window {
+			sum -= survivability.damageTaken[first].damage
+			first++
+		}
+		worst = max(worst, sum)
+	}
+	return worst
+}
+
+func (survivability *tankSurvivability) doneIteration(sim *Simulation) {
+	worst := survivability.worstWindowDamage()
+	survivability.damageTaken = survivability.damageTaken[:0]
+
+	// Hack because of the way DistributionMetrics does its calculations.
+	survivability.worstWindow.Total = worst * sim.Duration.Seconds()
+	survivability.worstWindow.doneIteration(sim)
+	survivability.worstWindowHist[int32(math.Round(worst))]++
+}
+
+// Returns the damage below which the given fraction of the worst windows lie.
+func spikeDamagePercentile(hist map[int32]int32, percentile float64) float64 {
+	bins := make([]int32, 0, len(hist))
+	total := 0
+	for bin, count := range hist {
+		bins = append(bins, bin)
+		total += int(count)
+	}
+	slices.Sort(bins)
+
+	threshold := percentile * float64(total)
+	seen := 0
+	for _, bin := range bins {
+		seen += int(hist[bin])
+		if float64(seen) >= threshold {
+			return float64(bin)
+		}
+	}
+	return 0
+}
+
+func spikeDamageToProto(hist map[int32]int32) []*proto.SpikeDamagePercentile {
+	spikes := make([]*proto.SpikeDamagePercentile, len(spikeDamagePercentiles))
+	for i, percentile := range spikeDamagePercentiles {
+		spikes[i] = &proto.SpikeDamagePercentile{
+			Percentile: percentile,
+			Damage:     spikeDamagePercentile(hist, percentile),
+		}
+	}
+	return spikes
+}
+
+func (survivability *tankSurvivability) ToProto() *proto.TankSurvivabilityMetrics {
+	return &proto.TankSurvivabilityMetrics{
+		WindowSeconds:        survivability.window.Seconds(),
+		WorstWindow:          survivability.worstWindow.ToProto(),
+		SpikeDamage:          spikeDamageToProto(survivability.worstWindowHist),
+		WorstWindowHist:      survivability.worstWindowHist,
+		Outcomes:             survivability.outcomes.toProto(),
+		AttackTable:          survivability.attackTable,
+		MaxHealth:            survivability.maxHealth,
+		EffectiveHealth:      survivability.effectiveHealth,
+		CrushImmunityDeficit: survivability.crushImmunityDeficit,
+	}
+}
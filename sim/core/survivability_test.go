@@ -0,0 +1,140 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+func TestSurvivabilityWorstWindowDamage(t *testing.T) {
+	survivability := &tankSurvivability{
+		window: time.Second * 6,
+		damageTaken: []damageTakenEvent{
+			{at: time.Second * 0, damage: 10},
+			{at: time.Second * 2, damage: 20},
+			{at: time.Second * 6, damage: 15}, // The first hit is out of the window.
+			{at: time.Second * 7, damage: 5},
+			{at: time.Second * 20, damage: 30},
+		},
+	}
+
+	if worst := survivability.worstWindowDamage(); worst != 40 {
+		t.Fatalf("Expected worst window damage of 40, got %0.2f", worst)
+	}
+}
+
+func TestSurvivabilitySpikeDamagePercentile(t *testing.T) {
+	hist := map[int32]int32{
+		20: 50,
+		30: 40,
+		45: 9,
+		60: 1,
+	}
+
+	expected := map[float64]float64{
+		0.5:  20,
+		0.9:  30,
+		0.95: 45,
+		0.99: 45,
+		1:    60,
+	}
+	for percentile, damage := range expected {
+		if actual := spikeDamagePercentile(hist, percentile); actual != damage {
+			t.Fatalf("Expected %0.0f%% spike damage of %0.0f, got %0.0f", percentile*100, damage, actual)
+		}
+	}
+}
+
+func TestSurvivabilityTankingBoss(t *testing.T) {
+	result := RunRaidSim(&proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{
+			Iterations: 200,
+			RandomSeed: 101,
+			IsTest:     true,
+		},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{
+							Name:      "Tank",
+							Class:     proto.Class_ClassShaman,
+							Consumes:  &proto.Consumes{},
+							Buffs:     &proto.IndividualBuffs{},
+							Spec:      &proto.Player_ElementalShaman{},
+							Equipment: &proto.EquipmentSpec{},
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				},
+			},
+			Tanks: []*proto.UnitReference{
+				{Type: proto.UnitReference_Player, Index: 0},
+			},
+		},
+		Encounter: &proto.Encounter{
+			Targets:  []*proto.Target{googleProto.Clone(DefaultTargetProtoLvl60).(*proto.Target)},
+			Duration: 60,
+		},
+	})
+	if result.Error != nil {
+		t.Fatalf("Sim failed: %s", result.Error.Message)
+	}
+
+	survivability := result.RaidMetrics.Parties[0].Players[0].Survivability
+	if survivability == nil {
+		t.Fatalf("Expected survivability metrics for the tank")
+	}
+	if survivability.WindowSeconds != defaultSurvivabilityWindow.Seconds() {
+		t.Fatalf("Expected the default window, got %0.1fs", survivability.WindowSeconds)
+	}
+	if survivability.WorstWindow.Avg <= 0 {
+		t.Fatalf("Expected the tank to take damage")
+	}
+	for i := 1; i < len(survivability.SpikeDamage); i++ {
+		if survivability.SpikeDamage[i].Damage < survivability.SpikeDamage[i-1].Damage {
+			t.Fatalf("Expected spike damage to grow with the percentile, got %v", survivability.SpikeDamage)
+		}
+	}
+
+	sumOutcomes := func(outcomes *proto.MeleeOutcomeBreakdown) float64 {
+		return outcomes.Miss + outcomes.Dodge + outcomes.Parry + outcomes.Block + outcomes.Crit + outcomes.Crush + outcomes.Hit
+	}
+	if sum := sumOutcomes(survivability.Outcomes); !WithinToleranceFloat64(1, sum, 0.0001) {
+		t.Fatalf("Expected outcomes to add up to 1, got %0.4f", sum)
+	}
+	if sum := sumOutcomes(survivability.AttackTable); !WithinToleranceFloat64(1, sum, 0.0001) {
+		t.Fatalf("Expected the attack table to add up to 1, got %0.4f", sum)
+	}
+
+	// An ungeared caster is nowhere near crush immunity against a level 63 boss.
+	if !WithinToleranceFloat64(0.15, survivability.AttackTable.Crush, 0.0001) {
+		t.Fatalf("Expected a 15%% crush chance, got %0.4f", survivability.AttackTable.Crush)
+	}
+	if survivability.CrushImmunityDeficit < survivability.AttackTable.Crush {
+		t.Fatalf("Expected the crush immunity deficit to include the crush chance, got %0.4f", survivability.CrushImmunityDeficit)
+	}
+	if !WithinToleranceFloat64(survivability.AttackTable.Crush, survivability.Outcomes.Crush, 0.03) {
+		t.Fatalf("Expected crushes to match the attack table, got %0.4f", survivability.Outcomes.Crush)
+	}
+	if survivability.EffectiveHealth < survivability.MaxHealth {
+		t.Fatalf("Expected effective health of at least the max health, got %0.0f", survivability.EffectiveHealth)
+	}
+
+	combined := CombineConcurrentSimResults([]*proto.RaidSimResult{result, result}, false).RaidMetrics.Parties[0].Players[0].Survivability
+	if !WithinToleranceFloat64(survivability.Outcomes.Crush, combined.Outcomes.Crush, 0.0001) ||
+		combined.SpikeDamage[len(combined.SpikeDamage)-1].Damage != survivability.SpikeDamage[len(survivability.SpikeDamage)-1].Damage ||
+		combined.CrushImmunityDeficit != survivability.CrushImmunityDeficit {
+		t.Fatalf("Unexpected combined survivability metrics: %v", combined)
+	}
+}
+
+func TestSurvivabilityNotTanking(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	if fa.Metrics.survivability != nil {
+		t.Fatalf("Expected no survivability metrics for players not tanking")
+	}
+}
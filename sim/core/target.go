@@ -24,6 +24,18 @@ type Encounter struct {
 	// In health fight: set to true until we get something to base on
 	DurationIsEstimate bool
 
+	// Mechanics which force players to move, see encounter_movement.go.
+	MovementPhases []MovementPhase
+
+	// Whether targets track threat and switch to whoever holds aggro, see threat.go.
+	UseThreatTable bool
+
+	// Threat budget for damage dealers, see threat_cap.go.
+	ThreatCap *ThreatCap
+
+	// Debuff slot limit and priorities, see debuff_slots.go.
+	debuffSlots *proto.DebuffSlots
+
 	// Value to multiply by, for damage spells which are subject to the aoe cap.
 	aoeCapMultiplier float64
 }
@@ -39,6 +51,10 @@ func NewEncounter(options *proto.Encounter) Encounter {
 		ExecuteProportion_25: max(options.ExecuteProportion_25, 0),
 		ExecuteProportion_35: max(options.ExecuteProportion_35, 0),
 		Targets:              []*Target{},
+		MovementPhases:       newMovementPhases(options.MovementPhases),
+		UseThreatTable:       options.UseThreatTable,
+		ThreatCap:            newThreatCap(options.ThreatCap),
+		debuffSlots:          options.DebuffSlots,
 	}
 	// If UseHealth is set, we use the sum of targets health.
 	if options.UseHealth {
@@ -84,7 +100,13 @@ func (encounter *Encounter) updateAOECapMultiplier() {
 func (encounter *Encounter) doneIteration(sim *Simulation) {
 	for i := range encounter.Targets {
 		target := encounter.Targets[i]
+		if target.ThreatTable != nil {
+			target.ThreatTable.flushAggroTime(sim)
+		}
 		target.doneIteration(sim)
+		if target.debuffSlots != nil {
+			target.debuffSlots.doneIteration()
+		}
 	}
 }
 
@@ -107,6 +129,12 @@ type Target struct {
 	Unit
 
 	AI TargetAI
+
+	// Overrides the encounter's debuff slot limit if set.
+	debuffSlotLimit int32
+
+	// When this target wipes its threat table, see threat.go.
+	threatWipeTimes []time.Duration
 }
 
 func NewTarget(options *proto.Target, targetIndex int32) *Target {
@@ -129,6 +157,8 @@ func NewTarget(options *proto.Target, targetIndex int32) *Target {
 
 			StatDependencyManager: stats.NewStatDependencyManager(),
 		},
+		debuffSlotLimit: options.DebuffSlotLimit,
+		threatWipeTimes: MapSlice(options.ThreatWipeTimes, DurationFromSeconds),
 	}
 	defaultRaidBossLevel := int32(CharacterMaxLevel + 3)
 	target.GCD = target.NewTimer()
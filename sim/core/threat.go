@@ -0,0 +1,171 @@
+package core
+
+import (
+	"time"
+)
+
+// Threat needed to pull aggro from the current holder, relative to the holder's threat.
+const (
+	MeleeAggroPullThreshold  = 1.1
+	RangedAggroPullThreshold = 1.3
+)
+
+// Tracks the threat of every raid unit on an enemy target, and moves the target's
+// CurrentTarget to whoever holds aggro. Only used when the encounter enables it,
+// otherwise targets stay on their assigned tank. Encounters with a threat cap also
+// track threat, but without aggro changes.
+//
+// Damage, healing and explicit threat changes are tracked in real time. Threat from
+// resource gains is only added to the metrics at the end of each iteration, so it
+// isn't included.
+type ThreatTable struct {
+	target *Unit
+	tank   *Unit // Assigned tank, which holds aggro at the start of each iteration.
+
+	trackAggro bool
+	threatCap  *ThreatCap
+
+	threat []float64 // Indexed by UnitIndex.
+
+	holder      *Unit
+	holderSince time.Duration
+}
+
+func newThreatTable(target *Unit, trackAggro bool, threatCap *ThreatCap) *ThreatTable {
+	return &ThreatTable{
+		target:     target,
+		tank:       target.CurrentTarget,
+		trackAggro: trackAggro,
+		threatCap:  threatCap,
+		threat:     make([]float64, len(target.Env.AllUnits)),
+	}
+}
+
+func (tt *ThreatTable) reset() {
+	for i := range tt.threat {
+		tt.threat[i] = 0
+	}
+	tt.holderSince = 0
+	if tt.trackAggro {
+		tt.holder = tt.tank
+		tt.target.CurrentTarget = tt.tank
+	}
+}
+
+// Returns the current threat of a unit on this table's target.
+func (tt *ThreatTable) Threat(unit *Unit) float64 {
+	return tt.threat[unit.UnitIndex]
+}
+
+// Returns the unit currently holding aggro, or nil.
+func (tt *ThreatTable) Holder() *Unit {
+	return tt.holder
+}
+
+// Returns the threat a unit needs to exceed to pull aggro from the current holder.
+func (tt *ThreatTable) PullThreshold(unit *Unit) float64 {
+	if tt.holder == nil {
+		return 0
+	}
+	return tt.Threat(tt.holder) * aggroPullThreshold(unit)
+}
+
+func aggroPullThreshold(unit *Unit) float64 {
+	if unit.DistanceFromTarget <= MaxMeleeAttackDistance {
+		return MeleeAggroPullThreshold
+	}
+	return RangedAggroPullThreshold
+}
+
+// Adds (or removes, for negative amounts like Feint's) threat for a unit.
+func (tt *ThreatTable) AddThreat(sim *Simulation, unit *Unit, amount float64) {
+	if amount == 0 || unit.Type == EnemyUnit {
+		return
+	}
+
+	tt.threat[unit.UnitIndex] = max(0, tt.threat[unit.UnitIndex]+amount)
+
+	if !tt.trackAggro {
+		return
+	}
+	if unit == tt.holder {
+		if amount < 0 {
+			tt.checkAllForAggro(sim)
+		}
+	} else if tt.Threat(unit) > 0 && tt.Threat(unit) > tt.PullThreshold(unit) {
+		tt.setHolder(sim, unit)
+	}
+}
+
+// Resets the threat of all units, as done by some boss abilities. The current holder
+// keeps aggro until someone else generates threat.
+func (tt *ThreatTable) Wipe(sim *Simulation) {
+	for i := range tt.threat {
+		tt.threat[i] = 0
+	}
+	if sim.Log != nil {
+		tt.target.Log(sim, "Threat wiped")
+	}
+}
+
+// Schedules the threat wipes of this target's encounter mechanics.
+func (target *Target) scheduleThreatWipes(sim *Simulation) {
+	if target.ThreatTable == nil {
+		return
+	}
+	for _, wipeAt := range target.threatWipeTimes {
+		StartDelayedAction(sim, DelayedActionOptions{
+			DoAt:     wipeAt,
+			OnAction: target.ThreatTable.Wipe,
+		})
+	}
+}
+
+// Gives aggro to the unit with the most threat among those above their pull threshold.
+func (tt *ThreatTable) checkAllForAggro(sim *Simulation) {
+	var newHolder *Unit
+	for _, unit := range tt.target.Env.Raid.AllUnits {
+		if unit == tt.holder || tt.Threat(unit) <= 0 || tt.Threat(unit) <= tt.PullThreshold(unit) {
+			continue
+		}
+		if newHolder == nil || tt.Threat(unit) > tt.Threat(newHolder) {
+			newHolder = unit
+		}
+	}
+	if newHolder != nil {
+		tt.setHolder(sim, newHolder)
+	}
+}
+
+func (tt *ThreatTable) setHolder(sim *Simulation, unit *Unit) {
+	tt.flushAggroTime(sim)
+
+	if sim.Log != nil {
+		tt.target.Log(sim, "Aggro pulled by %s with %0.1f threat", unit.Label, tt.Threat(unit))
+	}
+
+	tt.holder = unit
+	tt.target.CurrentTarget = unit
+	if !unit.Metrics.isTanking {
+		unit.Metrics.PulledAggro = true
+	}
+}
+
+func (tt *ThreatTable) flushAggroTime(sim *Simulation) {
+	if !tt.trackAggro {
+		return
+	}
+	if tt.holder != nil && sim.CurrentTime > tt.holderSince {
+		tt.holder.Metrics.AggroTime += sim.CurrentTime - max(0, tt.holderSince)
+	}
+	tt.holderSince = sim.CurrentTime
+}
+
+// Adds threat to the threat tables of all enemy targets, e.g. for healing.
+func (unit *Unit) addThreatToAllTargets(sim *Simulation, amount float64) {
+	for _, target := range unit.Env.Encounter.TargetUnits {
+		if target.ThreatTable != nil {
+			target.ThreatTable.AddThreat(sim, unit, amount)
+		}
+	}
+}
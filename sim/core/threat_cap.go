@@ -0,0 +1,79 @@
+package core
+
+import (
+	"math"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// Limits damage dealers to the threat a tank can generate. When the target has an
+// assigned tank its tracked threat is used, otherwise the tank is modeled as a flat
+// amount of threat per second.
+type ThreatCap struct {
+	TankTPS           float64
+	TankInitialThreat float64
+}
+
+func newThreatCap(config *proto.ThreatCap) *ThreatCap {
+	if config == nil {
+		return nil
+	}
+	return &ThreatCap{
+		TankTPS:           config.TankTps,
+		TankInitialThreat: config.TankInitialThreat,
+	}
+}
+
+// Returns the threat of the tank on this table's target.
+func (tt *ThreatTable) TankThreat(sim *Simulation) float64 {
+	if tt.tank != nil && tt.tank.Type != EnemyUnit {
+		return tt.Threat(tt.tank)
+	}
+	if tt.threatCap == nil {
+		return 0
+	}
+	return tt.threatCap.TankInitialThreat + tt.threatCap.TankTPS*max(0, sim.CurrentTime.Seconds())
+}
+
+// Returns how much more threat a unit can generate before pulling aggro off the tank.
+// Negative when the unit is already above the tank's pull threshold.
+func (tt *ThreatTable) ThreatMargin(sim *Simulation, unit *Unit) float64 {
+	return tt.TankThreat(sim)*aggroPullThreshold(unit) - tt.Threat(unit)
+}
+
+// Returns the threat margin of a unit on its current target, or math.MaxFloat64 when
+// the encounter doesn't track threat.
+func (unit *Unit) ThreatMargin(sim *Simulation) float64 {
+	if unit.CurrentTarget == nil || unit.CurrentTarget.ThreatTable == nil {
+		return math.MaxFloat64
+	}
+	return unit.CurrentTarget.ThreatTable.ThreatMargin(sim, unit)
+}
+
+// Runs the same sim, with the same seed, without the threat cap. The threat table is
+// dropped too, so rotations respecting the threat margin aren't limited by it.
+func runUncappedSim(rsr *proto.RaidSimRequest, signals simsignals.Signals) *proto.RaidMetrics {
+	uncapped := googleProto.Clone(rsr).(*proto.RaidSimRequest)
+	uncapped.Encounter.ThreatCap = nil
+	uncapped.Encounter.UseThreatTable = false
+	uncapped.SimOptions.Debug = false
+	uncapped.SimOptions.DebugFirstIteration = false
+
+	uncappedResult := runSim(uncapped, nil, false, signals)
+	if uncappedResult.Error != nil {
+		return nil
+	}
+	return uncappedResult.RaidMetrics
+}
+
+// Records how much DPS each player lost to the threat cap.
+func addThreatCapMetrics(metrics *proto.RaidMetrics, uncappedMetrics *proto.RaidMetrics) {
+	for i, party := range metrics.Parties {
+		for j, player := range party.Players {
+			uncappedPlayer := uncappedMetrics.Parties[i].Players[j]
+			player.DpsLostToThreatCap = max(0, uncappedPlayer.Dps.Avg-player.Dps.Avg)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package core
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+func TestThreatTablePullRules(t *testing.T) {
+	env := &Environment{Raid: &Raid{}}
+	newUnit := func(index int32, distance float64) *Unit {
+		return &Unit{UnitIndex: index, Env: env, DistanceFromTarget: distance, Metrics: NewUnitMetrics()}
+	}
+	target := &Unit{Type: EnemyUnit, Env: env}
+	tank := newUnit(1, 5)
+	tank.Metrics.isTanking = true
+	melee := newUnit(2, 5)
+	ranged := newUnit(3, 30)
+	env.AllUnits = []*Unit{target, tank, melee, ranged}
+	env.Raid.AllUnits = []*Unit{tank, melee, ranged}
+	target.CurrentTarget = tank
+
+	sim := &Simulation{Environment: env}
+	tt := newThreatTable(target, true, nil)
+	tt.reset()
+
+	tt.AddThreat(sim, tank, 1000)
+	tt.AddThreat(sim, melee, 1100)
+	if tt.Holder() != tank {
+		t.Fatalf("Melee shouldn't pull aggro at exactly 110%% threat")
+	}
+	tt.AddThreat(sim, ranged, 1250)
+	if tt.Holder() != tank {
+		t.Fatalf("Ranged shouldn't pull aggro below 130%% threat")
+	}
+
+	sim.CurrentTime = time.Second * 10
+	tt.AddThreat(sim, melee, 1)
+	if tt.Holder() != melee || target.CurrentTarget != melee {
+		t.Fatalf("Melee should pull aggro above 110%% threat")
+	}
+	if !melee.Metrics.PulledAggro || tank.Metrics.AggroTime != time.Second*10 {
+		t.Fatalf("Expected aggro pull to be recorded, got pulled = %t, tank aggro time = %s", melee.Metrics.PulledAggro, tank.Metrics.AggroTime)
+	}
+
+	// Dropping threat, like Feint, hands aggro to the highest threat above the threshold.
+	tt.AddThreat(sim, melee, -600)
+	if tt.Holder() != ranged {
+		t.Fatalf("Ranged should gain aggro after the melee drops threat")
+	}
+
+	tt.reset()
+	if tt.Holder() != tank || tt.Threat(melee) != 0 {
+		t.Fatalf("Reset should clear threat and return aggro to the tank")
+	}
+}
+
+func TestThreatCapMargin(t *testing.T) {
+	env := &Environment{Raid: &Raid{}}
+	target := &Unit{Type: EnemyUnit, Env: env}
+	melee := &Unit{UnitIndex: 1, Env: env, DistanceFromTarget: 5, Metrics: NewUnitMetrics(), CurrentTarget: target}
+	env.AllUnits = []*Unit{target, melee}
+	env.Raid.AllUnits = []*Unit{melee}
+	target.CurrentTarget = target // No tank in the raid.
+
+	sim := &Simulation{Environment: env}
+	if margin := melee.ThreatMargin(sim); margin != math.MaxFloat64 {
+		t.Fatalf("Expected no threat limit without a threat table, got %0.1f", margin)
+	}
+
+	target.ThreatTable = newThreatTable(target, false, &ThreatCap{TankTPS: 500, TankInitialThreat: 1000})
+	target.ThreatTable.reset()
+
+	sim.CurrentTime = time.Second * 2
+	target.ThreatTable.AddThreat(sim, melee, 1500)
+	if margin := melee.ThreatMargin(sim); math.Abs(margin-700) > 1e-9 {
+		t.Fatalf("Expected margin of 2000 * 1.1 - 1500 = 700, got %0.1f", margin)
+	}
+	if target.ThreatTable.Holder() != nil || target.CurrentTarget != target {
+		t.Fatalf("Threat cap alone shouldn't change aggro")
+	}
+}
+
+func TestDpsLostToThreatCapWithThreatTable(t *testing.T) {
+	// Dynamite is only thrown with threat to spare, which the first one uses up.
+	throwWithThreatToSpare := &proto.APLAction{
+		Condition: &proto.APLValue{Value: &proto.APLValue_Cmp{Cmp: &proto.APLValueCompare{
+			Op:  proto.APLValueCompare_OpGt,
+			Lhs: &proto.APLValue{Value: &proto.APLValue_ThreatMargin{ThreatMargin: &proto.APLValueThreatMargin{}}},
+			Rhs: &proto.APLValue{Value: &proto.APLValue_Const{Const: &proto.APLValueConst{Val: "1"}}},
+		}}},
+		Action: &proto.APLAction_AutocastOtherCooldowns{AutocastOtherCooldowns: &proto.APLActionAutocastOtherCooldowns{}},
+	}
+
+	result := RunSim(&proto.RaidSimRequest{
+		Raid: SinglePlayerRaidProto(&proto.Player{
+			Name:        "Engineer",
+			Class:       proto.Class_ClassShaman,
+			Profession1: proto.Profession_Engineering,
+			Consumes: &proto.Consumes{
+				FillerExplosive: proto.Explosive_ExplosiveDenseDynamite,
+			},
+			Spec:      &proto.Player_ElementalShaman{},
+			Equipment: &proto.EquipmentSpec{},
+			Rotation: &proto.APLRotation{
+				Type:         proto.APLRotation_TypeAPL,
+				PriorityList: []*proto.APLListItem{{Action: throwWithThreatToSpare}},
+			},
+		}, &proto.PartyBuffs{}, &proto.RaidBuffs{}, &proto.Debuffs{}),
+		Encounter: &proto.Encounter{
+			Targets: []*proto.Target{
+				{Level: 63, MobType: proto.MobType_MobTypeDemon},
+			},
+			Duration:       90,
+			UseThreatTable: true,
+			ThreatCap:      &proto.ThreatCap{TankTps: 1},
+		},
+		SimOptions: &proto.SimOptions{
+			Iterations: 1,
+			IsTest:     true,
+		},
+	}, nil, simsignals.CreateSignals())
+	if result.Error != nil {
+		t.Fatalf("Sim failed: %s", result.Error.Message)
+	}
+
+	player := result.RaidMetrics.Parties[0].Players[0]
+	if player.DpsLostToThreatCap < player.Dps.Avg/2 {
+		t.Fatalf("Expected the threat cap to cost the second dynamite, got %0.1f DPS lost of %0.1f DPS", player.DpsLostToThreatCap, player.Dps.Avg)
+	}
+}
+
+func TestThreatWipe(t *testing.T) {
+	result := RunSim(&proto.RaidSimRequest{
+		Raid: SinglePlayerRaidProto(&proto.Player{
+			Name:      "Caster",
+			Class:     proto.Class_ClassShaman,
+			Consumes:  &proto.Consumes{},
+			Spec:      &proto.Player_ElementalShaman{},
+			Equipment: &proto.EquipmentSpec{},
+		}, &proto.PartyBuffs{}, &proto.RaidBuffs{}, &proto.Debuffs{}),
+		Encounter: &proto.Encounter{
+			Targets: []*proto.Target{
+				{Level: 63, MobType: proto.MobType_MobTypeDemon, ThreatWipeTimes: []float64{20, 40}},
+			},
+			Duration:       60,
+			UseThreatTable: true,
+		},
+		SimOptions: &proto.SimOptions{
+			Iterations: 1,
+			IsTest:     true,
+			Debug:      true,
+		},
+	}, nil, simsignals.CreateSignals())
+	if result.Error != nil {
+		t.Fatalf("Sim failed: %s", result.Error.Message)
+	}
+
+	for _, wipe := range []string{"[20.00] [Target 1] Threat wiped", "[40.00] [Target 1] Threat wiped"} {
+		if !strings.Contains(result.Logs, wipe) {
+			t.Fatalf("Expected the log to contain %q", wipe)
+		}
+	}
+}
+
+func TestThreatWipeKeepsHolder(t *testing.T) {
+	env := &Environment{Raid: &Raid{}}
+	target := &Unit{Type: EnemyUnit, Env: env}
+	tank := &Unit{UnitIndex: 1, Env: env, DistanceFromTarget: 5, Metrics: NewUnitMetrics()}
+	melee := &Unit{UnitIndex: 2, Env: env, DistanceFromTarget: 5, Metrics: NewUnitMetrics()}
+	env.AllUnits = []*Unit{target, tank, melee}
+	env.Raid.AllUnits = []*Unit{tank, melee}
+	target.CurrentTarget = tank
+
+	sim := &Simulation{Environment: env}
+	tt := newThreatTable(target, true, nil)
+	tt.reset()
+
+	tt.AddThreat(sim, tank, 5000)
+	tt.AddThreat(sim, melee, 4000)
+	tt.Wipe(sim)
+	if tt.Threat(tank) != 0 || tt.Threat(melee) != 0 || tt.Holder() != tank {
+		t.Fatalf("Expected a wipe to clear all threat, with the tank keeping aggro")
+	}
+
+	tt.AddThreat(sim, melee, 1)
+	if tt.Holder() != melee {
+		t.Fatalf("Expected the first unit to generate threat after a wipe to pull aggro")
+	}
+}
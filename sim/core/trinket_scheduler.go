@@ -0,0 +1,324 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+// Equipping a trinket puts its on-use effect on cooldown for this long.
+const trinketSwapLockout = time.Second * 30
+
+var trinketSlots = [2]proto.ItemSlot{proto.ItemSlot_ItemSlotTrinket1, proto.ItemSlot_ItemSlotTrinket2}
+
+// An on-use trinket, either equipped or waiting to be swapped in.
+type scheduledTrinket struct {
+	item  Item
+	slot  proto.ItemSlot
+	spell *Spell
+
+	// Metrics for the current iteration.
+	useTimes []time.Duration
+	swapIns  int
+
+	// Aggregate values. These are updated after each iteration.
+	useSecondsSums []float64
+	useCounts      []int
+	swapInsSum     int
+}
+
+// Swaps on-use trinkets after they're used, and holds DPS trinkets for class cooldowns
+// and the execute phase.
+type trinketScheduler struct {
+	character *Character
+	config    *proto.TrinketSchedule
+
+	// Trinkets equipped at the pull, and those waiting to be swapped in, per slot.
+	startItems [2]Item
+	benchItems [2]Item
+
+	// Permanent auras registered by each trinket's effect, e.g. equip procs, which are
+	// only active while it's equipped.
+	passiveAuras map[int32][]*Aura
+
+	// Resolved when the character is finalized.
+	trinkets       []*scheduledTrinket
+	classCooldowns []*Spell
+
+	numIterations int
+}
+
+func (character *Character) trackTrinketSchedule(config *proto.TrinketSchedule) {
+	if config == nil {
+		return
+	}
+
+	scheduler := &trinketScheduler{
+		character:    character,
+		config:       config,
+		passiveAuras: make(map[int32][]*Aura),
+	}
+	for i, swap := range []*proto.ItemSpec{config.Trinket1Swap, config.Trinket2Swap} {
+		scheduler.startItems[i] = character.Equipment[trinketSlots[i]]
+		if swap == nil || swap.Id == 0 {
+			continue
+		}
+
+		item := toItem(swap)
+		if item.Type != proto.ItemType_ItemTypeTrinket {
+			panic(fmt.Sprintf("%s can't be swapped into a trinket slot", item.Name))
+		}
+		if character.HasTrinketEquipped(item.ID) || scheduler.benchItems[0].ID == item.ID {
+			panic(fmt.Sprintf("%s is already equipped or being swapped in", item.Name))
+		}
+		scheduler.benchItems[i] = item
+	}
+
+	character.trinketScheduler = scheduler
+	character.Metrics.trinketScheduler = scheduler
+}
+
+// Benched trinkets have their effects applied with the equipped ones, so their on-use
+// spells exist when they're swapped in.
+func (scheduler *trinketScheduler) applyBenchItemEffects(agent Agent) {
+	for _, item := range scheduler.benchItems {
+		if applyItemEffect, ok := itemEffects[item.ID]; ok {
+			scheduler.applyItemEffect(agent, item.ID, applyItemEffect)
+		}
+	}
+}
+
+// Applies the effect of a trinket which may be swapped, so the permanent auras it
+// registers are only activated while it's equipped.
+func (scheduler *trinketScheduler) applyItemEffect(agent Agent, itemID int32, applyItemEffect ApplyEffect) {
+	character := scheduler.character
+	numAuras := len(character.auras)
+	applyItemEffect(agent)
+
+	for _, aura := range character.auras[numAuras:] {
+		if aura.Duration != NeverExpires || aura.OnReset == nil {
+			continue
+		}
+
+		oldOnReset := aura.OnReset
+		aura.OnReset = func(aura *Aura, sim *Simulation) {
+			// Equipment is restored after auras are reset, so check the pull's trinkets.
+			if scheduler.startItems[0].ID == itemID || scheduler.startItems[1].ID == itemID {
+				oldOnReset(aura, sim)
+			}
+		}
+		scheduler.passiveAuras[itemID] = append(scheduler.passiveAuras[itemID], aura)
+	}
+}
+
+func (scheduler *trinketScheduler) finalize() {
+	character := scheduler.character
+
+	for i, slot := range trinketSlots {
+		for _, item := range []Item{scheduler.startItems[i], scheduler.benchItems[i]} {
+			if item.ID == 0 {
+				continue
+			}
+			spell := scheduler.onUseSpell(item.ID)
+			if slices.ContainsFunc(scheduler.trinkets, func(trinket *scheduledTrinket) bool { return trinket.spell == spell }) {
+				// The same trinket in both slots shares its on-use effect.
+				continue
+			}
+			if spell == nil {
+				if item.ID == scheduler.benchItems[i].ID {
+					panic(fmt.Sprintf("%s has no on-use effect to swap in for", item.Name))
+				}
+				continue
+			}
+			scheduler.trinkets = append(scheduler.trinkets, &scheduledTrinket{
+				item:  item,
+				slot:  slot,
+				spell: spell,
+			})
+		}
+	}
+
+	for _, trinket := range scheduler.trinkets {
+		trinket := trinket
+		spell := trinket.spell
+
+		oldCondition := spell.ExtraCastCondition
+		spell.ExtraCastCondition = func(sim *Simulation, target *Unit) bool {
+			return character.Equipment[trinket.slot].ID == trinket.item.ID && (oldCondition == nil || oldCondition(sim, target))
+		}
+
+		oldApplyEffects := spell.ApplyEffects
+		spell.ApplyEffects = func(sim *Simulation, target *Unit, spell *Spell) {
+			oldApplyEffects(sim, target, spell)
+			scheduler.onUse(sim, trinket)
+		}
+	}
+
+	for _, mcd := range character.initialMajorCooldowns {
+		if mcd.Type.Matches(CooldownTypeDPS) && mcd.Spell.ActionID.ItemID == 0 && mcd.Spell.ActionID.SpellID != 0 {
+			scheduler.classCooldowns = append(scheduler.classCooldowns, mcd.Spell)
+		}
+	}
+
+	// Trinkets cast by the APL are removed from the MCDs afterwards, so they aren't held.
+	for i := range character.initialMajorCooldowns {
+		mcd := &character.initialMajorCooldowns[i]
+		idx := slices.IndexFunc(scheduler.trinkets, func(trinket *scheduledTrinket) bool { return trinket.spell == mcd.Spell })
+		if idx == -1 || !mcd.Type.Matches(CooldownTypeDPS) {
+			continue
+		}
+
+		trinket := scheduler.trinkets[idx]
+		oldShouldActivate := mcd.ShouldActivate
+		mcd.ShouldActivate = func(sim *Simulation, character *Character) bool {
+			return !scheduler.shouldHold(sim, trinket) && oldShouldActivate(sim, character)
+		}
+	}
+}
+
+// Returns the spell registered as the on-use effect of a trinket, if it has one.
+func (scheduler *trinketScheduler) onUseSpell(itemID int32) *Spell {
+	for _, spell := range scheduler.character.Spellbook {
+		if spell.ActionID.ItemID == itemID && spell.Flags.Matches(SpellFlagMCD) {
+			return spell
+		}
+	}
+	return nil
+}
+
+func (scheduler *trinketScheduler) shouldHold(sim *Simulation, trinket *scheduledTrinket) bool {
+	if window := DurationFromSeconds(scheduler.config.AlignWindowSeconds); window > 0 {
+		for _, spell := range scheduler.classCooldowns {
+			timeToReady := spell.TimeToReady(sim)
+			if timeToReady > 0 && timeToReady <= window && sim.CurrentTime+timeToReady < sim.Duration {
+				return true
+			}
+		}
+	}
+
+	// The execute phase of fights ending at a target's health can't be predicted.
+	encounter := sim.Encounter
+	if scheduler.config.HoldForExecute && encounter.EndFightAtHealth == 0 && encounter.ExecuteProportion_20 > 0 && !sim.IsExecutePhase20() {
+		executeAt := time.Duration((1 - encounter.ExecuteProportion_20) * float64(sim.Duration))
+		if sim.CurrentTime+trinket.spell.CD.Duration > executeAt {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (scheduler *trinketScheduler) onUse(sim *Simulation, trinket *scheduledTrinket) {
+	trinket.useTimes = append(trinket.useTimes, sim.CurrentTime)
+
+	idx := slices.IndexFunc(scheduler.trinkets, func(bench *scheduledTrinket) bool {
+		return bench.slot == trinket.slot && bench != trinket
+	})
+	if idx == -1 {
+		return
+	}
+
+	// Swapping only pays off if the benched trinket will be ready first, accounting for
+	// the lockout and any cooldown it shares with the trinket just used.
+	bench := scheduler.trinkets[idx]
+	if max(bench.spell.ReadyAt(), sim.CurrentTime+trinketSwapLockout) < trinket.spell.ReadyAt() {
+		scheduler.swap(sim, trinket, bench)
+	}
+}
+
+func (scheduler *trinketScheduler) swap(sim *Simulation, equipped *scheduledTrinket, bench *scheduledTrinket) {
+	character := scheduler.character
+
+	character.Equipment[bench.slot] = bench.item
+	character.AddStatsDynamic(sim, bench.item.Stats.Subtract(equipped.item.Stats))
+	for _, aura := range scheduler.passiveAuras[equipped.item.ID] {
+		aura.Deactivate(sim)
+	}
+	for _, aura := range scheduler.passiveAuras[bench.item.ID] {
+		aura.Activate(sim)
+	}
+
+	if timer := bench.spell.CD.Timer; timer != nil {
+		timer.Set(max(timer.ReadyAt(), sim.CurrentTime+trinketSwapLockout))
+	}
+	bench.swapIns++
+	character.UpdateMajorCooldowns()
+
+	if sim.Log != nil {
+		character.Log(sim, "Swapped %s for %s", equipped.item.Name, bench.item.Name)
+	}
+}
+
+func (scheduler *trinketScheduler) reset(_ *Simulation) {
+	for _, trinket := range scheduler.trinkets {
+		trinket.useTimes = trinket.useTimes[:0]
+		trinket.swapIns = 0
+	}
+
+	// Stats are reset with the unit, so only the equipment needs restoring.
+	for i, slot := range trinketSlots {
+		scheduler.character.Equipment[slot] = scheduler.startItems[i]
+	}
+}
+
+func (scheduler *trinketScheduler) doneIteration(_ *Simulation) {
+	scheduler.numIterations++
+
+	for _, trinket := range scheduler.trinkets {
+		for i, useTime := range trinket.useTimes {
+			if i == len(trinket.useCounts) {
+				trinket.useSecondsSums = append(trinket.useSecondsSums, 0)
+				trinket.useCounts = append(trinket.useCounts, 0)
+			}
+			trinket.useSecondsSums[i] += useTime.Seconds()
+			trinket.useCounts[i]++
+		}
+		trinket.swapInsSum += trinket.swapIns
+	}
+}
+
+func (scheduler *trinketScheduler) ToProto() []*proto.TrinketUseMetrics {
+	n := float64(max(scheduler.numIterations, 1))
+
+	trinketUses := make([]*proto.TrinketUseMetrics, len(scheduler.trinkets))
+	for i, trinket := range scheduler.trinkets {
+		trinketUses[i] = &proto.TrinketUseMetrics{
+			Id:         trinket.spell.ActionID.ToProto(),
+			Slot:       trinket.slot,
+			SwapInsAvg: float64(trinket.swapInsSum) / n,
+		}
+		for use, count := range trinket.useCounts {
+			trinketUses[i].UseSecondsAvg = append(trinketUses[i].UseSecondsAvg, trinket.useSecondsSums[use]/float64(count))
+			trinketUses[i].UseChance = append(trinketUses[i].UseChance, float64(count)/n)
+		}
+	}
+	return trinketUses
+}
+
+// Adds the weighted trinket uses of a concurrent sim. Use times are weighted by their
+// chance, and divided by the combined chance once the last sim is added.
+func combineTrinketUses(base []*proto.TrinketUseMetrics, add []*proto.TrinketUseMetrics, isLast bool, weight float64) {
+	for i, addUses := range add {
+		baseUses := base[i]
+		baseUses.SwapInsAvg += addUses.SwapInsAvg * weight
+
+		for use, chance := range addUses.UseChance {
+			if use == len(baseUses.UseChance) {
+				baseUses.UseSecondsAvg = append(baseUses.UseSecondsAvg, 0)
+				baseUses.UseChance = append(baseUses.UseChance, 0)
+			}
+			baseUses.UseSecondsAvg[use] += addUses.UseSecondsAvg[use] * chance * weight
+			baseUses.UseChance[use] += chance * weight
+		}
+
+		if isLast {
+			for use, chance := range baseUses.UseChance {
+				if chance > 0 {
+					baseUses.UseSecondsAvg[use] /= chance
+				}
+			}
+		}
+	}
+}
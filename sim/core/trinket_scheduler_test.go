@@ -0,0 +1,145 @@
+package core
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	"github.com/wowsims/classic/sim/core/stats"
+)
+
+const (
+	itemTalismanOfEphemeralPower = 18820
+	itemEarthstrike              = 21180
+	itemKissOfTheSpider          = 22954
+)
+
+// Item effects aren't registered in core tests, so the trinkets are given their effects
+// from sim/common, and Kiss of the Spider a permanent aura for its equip bonus.
+func registerTestTrinkets() {
+	if HasItemEffect(itemTalismanOfEphemeralPower) {
+		return
+	}
+
+	NewSimpleStatOffensiveTrinketEffect(itemTalismanOfEphemeralPower, stats.Stats{stats.SpellPower: 175}, time.Second*15, time.Second*90)
+	NewSimpleStatOffensiveTrinketEffect(itemEarthstrike, stats.Stats{stats.AttackPower: 280, stats.RangedAttackPower: 280}, time.Second*20, time.Second*120)
+	NewItemEffect(itemKissOfTheSpider, func(agent Agent) {
+		character := agent.GetCharacter()
+		MakePermanent(character.RegisterAura(Aura{
+			Label:    "Kiss of the Spider Equip",
+			ActionID: ActionID{ItemID: itemKissOfTheSpider},
+		}))
+		character.AddMajorCooldown(MajorCooldown{
+			Type: CooldownTypeDPS,
+			Spell: character.RegisterSpell(SpellConfig{
+				ActionID: ActionID{ItemID: itemKissOfTheSpider, Tag: 1},
+				Flags:    SpellFlagNoOnCastComplete | SpellFlagOffensiveEquipment,
+				Cast: CastConfig{
+					CD: Cooldown{Timer: character.NewTimer(), Duration: time.Minute * 2},
+				},
+				ApplyEffects: func(_ *Simulation, _ *Unit, _ *Spell) {},
+			}),
+		})
+	})
+}
+
+func simTrinketSchedule(t *testing.T, schedule *proto.TrinketSchedule, duration float64, autocast bool) *proto.UnitMetrics {
+	registerTestTrinkets()
+
+	rotation := &proto.APLRotation{Type: proto.APLRotation_TypeAPL}
+	if autocast {
+		rotation.PriorityList = []*proto.APLListItem{
+			{Action: &proto.APLAction{Action: &proto.APLAction_AutocastOtherCooldowns{AutocastOtherCooldowns: &proto.APLActionAutocastOtherCooldowns{}}}},
+		}
+	}
+
+	equipment := &proto.EquipmentSpec{}
+	for slot := proto.ItemSlot(0); slot < proto.ItemSlot_ItemSlotTrinket1; slot++ {
+		equipment.Items = append(equipment.Items, &proto.ItemSpec{})
+	}
+	equipment.Items = append(equipment.Items, &proto.ItemSpec{Id: itemTalismanOfEphemeralPower})
+
+	result := RunSim(&proto.RaidSimRequest{
+		Raid: SinglePlayerRaidProto(&proto.Player{
+			Name:            "Caster",
+			Class:           proto.Class_ClassShaman,
+			Consumes:        &proto.Consumes{},
+			Spec:            &proto.Player_ElementalShaman{},
+			Equipment:       equipment,
+			Rotation:        rotation,
+			TrinketSchedule: schedule,
+		}, &proto.PartyBuffs{}, &proto.RaidBuffs{}, &proto.Debuffs{}),
+		Encounter: &proto.Encounter{
+			Targets: []*proto.Target{
+				{Level: 63, MobType: proto.MobType_MobTypeDemon},
+			},
+			Duration:             duration,
+			ExecuteProportion_20: 0.2,
+		},
+		SimOptions: &proto.SimOptions{
+			Iterations: 2,
+			IsTest:     true,
+		},
+	}, nil, simsignals.CreateSignals())
+	if result.Error != nil {
+		t.Fatalf("Sim failed: %s", result.Error.Message)
+	}
+
+	return result.RaidMetrics.Parties[0].Players[0]
+}
+
+func trinketUses(metrics *proto.UnitMetrics) map[int32]*proto.TrinketUseMetrics {
+	uses := make(map[int32]*proto.TrinketUseMetrics)
+	for _, trinketUses := range metrics.TrinketUses {
+		uses[trinketUses.Id.GetItemId()] = trinketUses
+	}
+	return uses
+}
+
+func TestTrinketScheduleSwapsAfterUse(t *testing.T) {
+	uses := trinketUses(simTrinketSchedule(t, &proto.TrinketSchedule{
+		Trinket1Swap: &proto.ItemSpec{Id: itemEarthstrike},
+	}, 140, true))
+
+	// The talisman is used at the pull, Earthstrike once its lockout ends, and the talisman
+	// again once it's back off cooldown, with Earthstrike swapped back in after each use
+	// of the talisman.
+	if talisman := uses[itemTalismanOfEphemeralPower]; talisman == nil || !slices.Equal(talisman.UseSecondsAvg, []float64{0, 90}) || talisman.SwapInsAvg != 1 {
+		t.Fatalf("Expected the talisman to be used at 0s and 90s after being swapped back in, got %v", talisman)
+	}
+	if earthstrike := uses[itemEarthstrike]; earthstrike == nil || !slices.Equal(earthstrike.UseSecondsAvg, []float64{30}) || earthstrike.SwapInsAvg != 2 {
+		t.Fatalf("Expected Earthstrike to be swapped in twice and used at 30s, got %v", earthstrike)
+	}
+}
+
+func TestTrinketScheduleHoldsForExecute(t *testing.T) {
+	// The execute phase starts at 80s, before the talisman would be back off cooldown.
+	uses := trinketUses(simTrinketSchedule(t, &proto.TrinketSchedule{HoldForExecute: true}, 100, true))
+
+	if talisman := uses[itemTalismanOfEphemeralPower]; talisman == nil || !slices.Equal(talisman.UseSecondsAvg, []float64{80}) || talisman.UseChance[0] != 1 {
+		t.Fatalf("Expected the talisman to be held for the execute phase at 80s, got %v", talisman)
+	}
+}
+
+func TestTrinketScheduleBenchPassives(t *testing.T) {
+	equipUptime := func(metrics *proto.UnitMetrics) float64 {
+		for _, aura := range metrics.Auras {
+			if aura.Id.GetItemId() == itemKissOfTheSpider {
+				return aura.UptimeSecondsAvg
+			}
+		}
+		return 0
+	}
+	schedule := &proto.TrinketSchedule{Trinket1Swap: &proto.ItemSpec{Id: itemKissOfTheSpider}}
+
+	// Without using the talisman, Kiss of the Spider is never swapped in.
+	if uptime := equipUptime(simTrinketSchedule(t, schedule, 60, false)); uptime != 0 {
+		t.Fatalf("Expected the benched trinket's equip aura to be inactive, got %0.1fs uptime", uptime)
+	}
+	// It's swapped in at the pull, and out again after its use at 30s.
+	if uptime := equipUptime(simTrinketSchedule(t, schedule, 60, true)); uptime != 30 {
+		t.Fatalf("Expected the equip aura to be active while swapped in, got %0.1fs uptime", uptime)
+	}
+}
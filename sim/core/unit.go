@@ -56,6 +56,9 @@ type Unit struct {
 	// Amount of time following a post-GCD channel tick, to when the next action can be performed.
 	ChannelClipDelay time.Duration
 
+	// Latency and reaction model for this unit, see PlayerSkill.
+	Skill PlayerSkill
+
 	// How far this unit is from its target(s). Measured in yards, this is used
 	// for calculating spell travel time for certain spells.
 	StartDistanceFromTarget float64
@@ -152,6 +155,14 @@ type Unit struct {
 	CurrentTarget *Unit
 	defaultTarget *Unit
 
+	// Threat of each raid unit on this unit, only set for enemies when the encounter
+	// uses threat tables.
+	ThreatTable *ThreatTable
+
+	// Limited debuff slots of this unit, only set for enemies when the encounter
+	// limits debuffs.
+	debuffSlots *debuffSlots
+
 	// The currently-channeled DOT spell, otherwise nil.
 	ChanneledDot *Dot
 }
@@ -541,8 +552,10 @@ func (unit *Unit) GetMetadata() *proto.UnitMetadata {
 	}
 
 	metadata.Spells = MapSlice(unit.Spellbook, func(spell *Spell) *proto.SpellStats {
+		id := spell.ActionID.ToProto()
+		id.Rank = int32(spell.Rank)
 		return &proto.SpellStats{
-			Id: spell.ActionID.ToProto(),
+			Id: id,
 
 			IsCastable:      spell.Flags.Matches(SpellFlagAPL),
 			IsChanneled:     spell.Flags.Matches(SpellFlagChanneled),
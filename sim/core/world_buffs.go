@@ -0,0 +1,168 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// A world buff in IndividualBuffs, with its remaining duration in WorldBuffDurations.
+type worldBuff struct {
+	name string
+	// How long the buff lasts when it's received.
+	duration time.Duration
+
+	isActive  func(buffs *proto.IndividualBuffs) bool
+	clear     func(buffs *proto.IndividualBuffs)
+	remaining func(durations *proto.WorldBuffDurations) *float64
+}
+
+var worldBuffs = []worldBuff{
+	{
+		name:      "Rallying Cry of the Dragonslayer",
+		duration:  time.Hour * 2,
+		isActive:  func(buffs *proto.IndividualBuffs) bool { return buffs.RallyingCryOfTheDragonslayer },
+		clear:     func(buffs *proto.IndividualBuffs) { buffs.RallyingCryOfTheDragonslayer = false },
+		remaining: func(durations *proto.WorldBuffDurations) *float64 { return &durations.RallyingCryOfTheDragonslayer },
+	},
+	{
+		name:      "Spirit of Zandalar",
+		duration:  time.Hour * 2,
+		isActive:  func(buffs *proto.IndividualBuffs) bool { return buffs.SpiritOfZandalar },
+		clear:     func(buffs *proto.IndividualBuffs) { buffs.SpiritOfZandalar = false },
+		remaining: func(durations *proto.WorldBuffDurations) *float64 { return &durations.SpiritOfZandalar },
+	},
+	{
+		name:      "Songflower Serenade",
+		duration:  time.Hour,
+		isActive:  func(buffs *proto.IndividualBuffs) bool { return buffs.SongflowerSerenade },
+		clear:     func(buffs *proto.IndividualBuffs) { buffs.SongflowerSerenade = false },
+		remaining: func(durations *proto.WorldBuffDurations) *float64 { return &durations.SongflowerSerenade },
+	},
+	{
+		name:      "Warchief's Blessing",
+		duration:  time.Hour,
+		isActive:  func(buffs *proto.IndividualBuffs) bool { return buffs.WarchiefsBlessing },
+		clear:     func(buffs *proto.IndividualBuffs) { buffs.WarchiefsBlessing = false },
+		remaining: func(durations *proto.WorldBuffDurations) *float64 { return &durations.WarchiefsBlessing },
+	},
+	{
+		name:      "Fengus' Ferocity",
+		duration:  time.Hour * 2,
+		isActive:  func(buffs *proto.IndividualBuffs) bool { return buffs.FengusFerocity },
+		clear:     func(buffs *proto.IndividualBuffs) { buffs.FengusFerocity = false },
+		remaining: func(durations *proto.WorldBuffDurations) *float64 { return &durations.FengusFerocity },
+	},
+	{
+		name:      "Moldar's Moxie",
+		duration:  time.Hour * 2,
+		isActive:  func(buffs *proto.IndividualBuffs) bool { return buffs.MoldarsMoxie },
+		clear:     func(buffs *proto.IndividualBuffs) { buffs.MoldarsMoxie = false },
+		remaining: func(durations *proto.WorldBuffDurations) *float64 { return &durations.MoldarsMoxie },
+	},
+	{
+		name:      "Slip'kik's Savvy",
+		duration:  time.Hour * 2,
+		isActive:  func(buffs *proto.IndividualBuffs) bool { return buffs.SlipkiksSavvy },
+		clear:     func(buffs *proto.IndividualBuffs) { buffs.SlipkiksSavvy = false },
+		remaining: func(durations *proto.WorldBuffDurations) *float64 { return &durations.SlipkiksSavvy },
+	},
+	{
+		name:     "Sayge's Dark Fortune",
+		duration: time.Hour * 2,
+		isActive: func(buffs *proto.IndividualBuffs) bool {
+			return buffs.SaygesFortune != proto.SaygesFortune_SaygesUnknown
+		},
+		clear:     func(buffs *proto.IndividualBuffs) { buffs.SaygesFortune = proto.SaygesFortune_SaygesUnknown },
+		remaining: func(durations *proto.WorldBuffDurations) *float64 { return &durations.SaygesFortune },
+	},
+}
+
+// Makes a permanent world buff fall off once its remaining duration at the pull runs out.
+// The buff stays permanent while building the character, so it's part of the measured stats.
+func applyWorldBuffDuration(aura *Aura, remainingSeconds float64) {
+	if aura == nil || remainingSeconds <= 0 {
+		return
+	}
+
+	remaining := DurationFromSeconds(remainingSeconds)
+	oldOnReset := aura.OnReset
+	aura.OnReset = func(aura *Aura, sim *Simulation) {
+		aura.Duration = remaining
+		oldOnReset(aura, sim)
+	}
+}
+
+// Returns the world buffs left after some time has passed, with their remaining durations
+// reduced by it. Buffs without a remaining duration were just received.
+func worldBuffsAfter(buffs *proto.IndividualBuffs, elapsed time.Duration) *proto.IndividualBuffs {
+	buffs = googleProto.Clone(buffs).(*proto.IndividualBuffs)
+	if buffs.WorldBuffDurations == nil {
+		buffs.WorldBuffDurations = &proto.WorldBuffDurations{}
+	}
+
+	for _, worldBuff := range worldBuffs {
+		if !worldBuff.isActive(buffs) {
+			continue
+		}
+		remaining := worldBuff.remainingDuration(buffs) - elapsed
+		if remaining <= 0 {
+			worldBuff.clear(buffs)
+			remaining = 0
+		}
+		*worldBuff.remaining(buffs.WorldBuffDurations) = remaining.Seconds()
+	}
+	return buffs
+}
+
+func (worldBuff *worldBuff) remainingDuration(buffs *proto.IndividualBuffs) time.Duration {
+	if remaining := *worldBuff.remaining(buffs.WorldBuffDurations); remaining > 0 {
+		return DurationFromSeconds(remaining)
+	}
+	return worldBuff.duration
+}
+
+// Lists, for each encounter of a raid, the world buffs which last through it, those which
+// fall off during it, and those lost before the pull.
+func WorldBuffTimeline(request *proto.WorldBuffTimelineRequest) *proto.WorldBuffTimelineResult {
+	if request.Buffs == nil {
+		return &proto.WorldBuffTimelineResult{Error: &proto.ErrorOutcome{Message: "missing buffs"}}
+	}
+
+	raidBuffs := worldBuffsAfter(request.Buffs, 0)
+	result := &proto.WorldBuffTimelineResult{}
+	var pullTime time.Duration
+	for i, pull := range request.Pulls {
+		if pull.GapSeconds < 0 || pull.DurationSeconds <= 0 {
+			return &proto.WorldBuffTimelineResult{Error: &proto.ErrorOutcome{Message: fmt.Sprintf("encounter %d has an invalid gap or duration", i+1)}}
+		}
+
+		pullTime += DurationFromSeconds(pull.GapSeconds)
+		encounter := &proto.EncounterWorldBuffs{
+			Name:            pull.Name,
+			PullTimeSeconds: pullTime.Seconds(),
+			Buffs:           worldBuffsAfter(raidBuffs, pullTime),
+		}
+
+		fightDuration := DurationFromSeconds(pull.DurationSeconds)
+		for _, worldBuff := range worldBuffs {
+			if !worldBuff.isActive(raidBuffs) {
+				continue
+			}
+			if !worldBuff.isActive(encounter.Buffs) {
+				encounter.LostBuffs = append(encounter.LostBuffs, worldBuff.name)
+			} else if worldBuff.remainingDuration(encounter.Buffs) < fightDuration {
+				encounter.ExpiringBuffs = append(encounter.ExpiringBuffs, worldBuff.name)
+			} else {
+				encounter.ActiveBuffs = append(encounter.ActiveBuffs, worldBuff.name)
+			}
+		}
+
+		result.Encounters = append(result.Encounters, encounter)
+		pullTime += fightDuration
+	}
+
+	return result
+}
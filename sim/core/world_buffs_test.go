@@ -0,0 +1,106 @@
+package core
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+func TestWorldBuffFallsOff(t *testing.T) {
+	result := RunSim(&proto.RaidSimRequest{
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{
+							Name:     "Caster",
+							Class:    proto.Class_ClassShaman,
+							Consumes: &proto.Consumes{},
+							Buffs: &proto.IndividualBuffs{
+								SongflowerSerenade: true,
+								WarchiefsBlessing:  true,
+								WorldBuffDurations: &proto.WorldBuffDurations{SongflowerSerenade: 20},
+							},
+							Spec:      &proto.Player_ElementalShaman{},
+							Equipment: &proto.EquipmentSpec{},
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				},
+			},
+		},
+		Encounter: &proto.Encounter{
+			Targets: []*proto.Target{
+				{Level: 63, MobType: proto.MobType_MobTypeDemon},
+			},
+			Duration: 60,
+		},
+		SimOptions: &proto.SimOptions{
+			Iterations: 1,
+			IsTest:     true,
+		},
+	}, nil, simsignals.CreateSignals())
+	if result.Error != nil {
+		t.Fatalf("Sim failed: %s", result.Error.Message)
+	}
+
+	uptimes := make(map[int32]float64)
+	for _, aura := range result.RaidMetrics.Parties[0].Players[0].Auras {
+		uptimes[aura.Id.GetSpellId()] = aura.UptimeSecondsAvg
+	}
+	if uptime := uptimes[15366]; !WithinToleranceFloat64(20, uptime, 0.001) {
+		t.Fatalf("Expected Songflower Serenade to fall off after 20s, got %0.2fs", uptime)
+	}
+	if uptime := uptimes[16609]; !WithinToleranceFloat64(60, uptime, 0.001) {
+		t.Fatalf("Expected Warchief's Blessing to last the whole fight, got %0.2fs", uptime)
+	}
+}
+
+func TestWorldBuffTimeline(t *testing.T) {
+	result := WorldBuffTimeline(&proto.WorldBuffTimelineRequest{
+		Buffs: &proto.IndividualBuffs{
+			RallyingCryOfTheDragonslayer: true,
+			SongflowerSerenade:           true,
+			WarchiefsBlessing:            true,
+			WorldBuffDurations: &proto.WorldBuffDurations{
+				SongflowerSerenade: 1800,
+				WarchiefsBlessing:  900,
+			},
+		},
+		Pulls: []*proto.EncounterPull{
+			{Name: "Lucifron", GapSeconds: 600, DurationSeconds: 120},
+			{Name: "Magmadar", GapSeconds: 900, DurationSeconds: 240},
+		},
+	})
+	if result.Error != nil {
+		t.Fatalf("Timeline failed: %s", result.Error.Message)
+	}
+	if len(result.Encounters) != 2 {
+		t.Fatalf("Expected 2 encounters, got %d", len(result.Encounters))
+	}
+
+	first := result.Encounters[0]
+	if !slices.Equal(first.ActiveBuffs, []string{"Rallying Cry of the Dragonslayer", "Songflower Serenade", "Warchief's Blessing"}) {
+		t.Fatalf("Expected all buffs to last the first encounter, got %v", first.ActiveBuffs)
+	}
+	if remaining := first.Buffs.WorldBuffDurations.WarchiefsBlessing; remaining != 300 {
+		t.Fatalf("Expected 300s of Warchief's Blessing left at the first pull, got %0.0fs", remaining)
+	}
+
+	// Pulled at 1620s, with Songflower Serenade falling off at 1800s.
+	second := result.Encounters[1]
+	if second.PullTimeSeconds != 1620 {
+		t.Fatalf("Expected the second pull at 1620s, got %0.0fs", second.PullTimeSeconds)
+	}
+	if !slices.Equal(second.LostBuffs, []string{"Warchief's Blessing"}) || second.Buffs.WarchiefsBlessing {
+		t.Fatalf("Expected Warchief's Blessing to be lost, got %v", second.LostBuffs)
+	}
+	if !slices.Equal(second.ExpiringBuffs, []string{"Songflower Serenade"}) {
+		t.Fatalf("Expected Songflower Serenade to fall off during the fight, got %v", second.ExpiringBuffs)
+	}
+	if !slices.Equal(second.ActiveBuffs, []string{"Rallying Cry of the Dragonslayer"}) {
+		t.Fatalf("Expected Rallying Cry to last, got %v", second.ActiveBuffs)
+	}
+}
@@ -60,6 +60,10 @@ func (druid *Druid) newStarfireSpellConfig(rank int) core.SpellConfig {
 		ThreatMultiplier: 1,
 		BonusCoefficient: 1,
 
+		ExpectedInitialDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
+			baseDamage := (baseDamageLow + baseDamageHigh) / 2
+			return spell.CalcDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicHitAndCrit)
+		},
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
 			baseDamage := sim.Roll(baseDamageLow, baseDamageHigh)
 			spell.CalcAndDealDamage(sim, target, baseDamage, spell.OutcomeMagicHitAndCrit)
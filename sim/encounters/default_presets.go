@@ -20,6 +20,10 @@ func addLevel60(bossPrefix string) {
 				stats.Health:      127_393, // TODO:
 				stats.Armor:       3731,    // TODO:
 				stats.AttackPower: 805,     // TODO:
+				// No resistances: raid bosses have no base resistance to any school, so
+				// their partial resists come only from their level, which the resist
+				// thresholds add for enemies above the caster's level. Bosses which
+				// do resist a school, like Molten Core's against fire, should set it here.
 			}.ToFloatArray(),
 
 			SpellSchool:      proto.SpellSchool_SpellSchoolPhysical,
@@ -123,20 +123,29 @@ func (hunter *Hunter) GetHunter() *Hunter {
 	return hunter
 }
 
+// Attack power given by Trueshot Aura, by the Hunter's level.
+var TrueshotAuraAttackPower = map[int32]float64{
+	25: 0,
+	40: 50,
+	50: 75,
+	60: 100,
+}
+
 func (hunter *Hunter) AddRaidBuffs(raidBuffs *proto.RaidBuffs) {
-	if raidBuffs.TrueshotAura && hunter.Talents.TrueshotAura {
-		hunter.AddStat(stats.RangedAttackPower, map[int32]float64{
-			25: 0,
-			40: 50,
-			50: 75,
-			60: 100,
-		}[hunter.Level])
+	if raidBuffs.TrueshotAura && hunter.Talents.TrueshotAura && !hunter.Env.Raid.BuffsFromMembers {
+		hunter.AddStat(stats.RangedAttackPower, TrueshotAuraAttackPower[hunter.Level])
 	}
 
 }
 func (hunter *Hunter) AddPartyBuffs(_ *proto.PartyBuffs) {
 }
 
+func (hunter *Hunter) ClearProvidedBuffs(raidBuffs *proto.RaidBuffs) {
+	if hunter.Talents.TrueshotAura {
+		raidBuffs.TrueshotAura = false
+	}
+}
+
 func (hunter *Hunter) Initialize() {
 	hunter.OnSpellRegistered(func(spell *core.Spell) {
 		if spell.Flags.Matches(SpellFlagShot) {
@@ -156,6 +165,15 @@ func (hunter *Hunter) Initialize() {
 
 	hunter.registerAspectOfTheHawkSpell()
 
+	if hunter.Talents.TrueshotAura && hunter.Env.Raid.BuffsFromMembers {
+		hunter.NewPartyAuraArray(func(unit *core.Unit) *core.Aura {
+			if aura := unit.GetAura("Trueshot Aura"); aura != nil {
+				return aura
+			}
+			return core.TrueshotAura(unit, TrueshotAuraAttackPower[hunter.Level])
+		})
+	}
+
 	multiShotTimer := hunter.NewTimer()
 	arcaneShotTimer := hunter.NewTimer()
 
@@ -86,6 +86,10 @@ func (mage *Mage) newFireballSpellConfig(rank int) core.SpellConfig {
 		ThreatMultiplier: 1,
 		BonusCoefficient: spellCoeff,
 
+		ExpectedInitialDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
+			baseDamage := (baseDamageLow + baseDamageHigh) / 2
+			return spell.CalcDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicHitAndCrit)
+		},
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
 			baseDamage := sim.Roll(baseDamageLow, baseDamageHigh)
 			result := spell.CalcDamage(sim, target, baseDamage, spell.OutcomeMagicHitAndCrit)
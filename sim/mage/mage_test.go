@@ -6,6 +6,7 @@ import (
 	_ "github.com/wowsims/classic/sim/common"
 	"github.com/wowsims/classic/sim/core"
 	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
 )
 
 func init() {
@@ -34,6 +35,65 @@ func TestP1Mage(t *testing.T) {
 	}))
 }
 
+// An ungeared mage casting max rank Frostbolt runs out of mana early in the fight, so
+// its best mana plan downranks Frostbolt or drinks its Major Mana Potion.
+func TestPlanManaForOomMage(t *testing.T) {
+	maxRankID := core.ActionID{SpellID: FrostboltSpellId[core.TernaryInt(core.IncludeAQ, FrostboltRanks, FrostboltRanks-1)]}
+	potionID := core.ActionID{ItemID: 13444}
+
+	result := core.PlanMana(&proto.ManaPlanRequest{
+		Raid: core.SinglePlayerRaidProto(&proto.Player{
+			Name:      "Mage",
+			Race:      proto.Race_RaceTroll,
+			Class:     proto.Class_ClassMage,
+			Equipment: &proto.EquipmentSpec{},
+			Rotation: &proto.APLRotation{
+				Type: proto.APLRotation_TypeAPL,
+				PriorityList: []*proto.APLListItem{
+					{Action: &proto.APLAction{Action: &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{
+						SpellId: maxRankID.ToProto(),
+					}}}},
+				},
+			},
+			Spec:     PlayerOptions,
+			Consumes: &proto.Consumes{DefaultPotion: proto.Potions_MajorManaPotion},
+			Buffs:    &proto.IndividualBuffs{},
+		}, &proto.PartyBuffs{}, &proto.RaidBuffs{}, &proto.Debuffs{}),
+		Encounter: &proto.Encounter{
+			Targets:  []*proto.Target{{Level: 63, MobType: proto.MobType_MobTypeDemon}},
+			Duration: 180,
+		},
+		SimOptions:      &proto.SimOptions{Iterations: 20, RandomSeed: 101},
+		DownrankSpells:  []*proto.ActionID{maxRankID.ToProto()},
+		TimeStepSeconds: 60,
+	}, simsignals.CreateSignals())
+	if result.Error != nil {
+		t.Fatalf("Planning failed: %s", result.Error.Message)
+	}
+	if result.BaseSecondsOomAvg == 0 {
+		t.Fatalf("Expected the mage to run out of mana with the max rank only")
+	}
+
+	best := result.BestPlan
+	if best.DpsGain <= 0 {
+		t.Fatalf("Expected the best plan to gain DPS, got %0.1f for %q", best.DpsGain, best.Description)
+	}
+	usesPlan := false
+	for _, item := range best.Rotation.PriorityList {
+		action := item.Action
+		if schedule := action.GetSchedule(); schedule != nil {
+			action = schedule.InnerAction
+		}
+		// Only downranked casts have a rank set.
+		if castSpell := action.GetCastSpell(); castSpell != nil {
+			usesPlan = usesPlan || core.ProtoToActionID(castSpell.SpellId).SameAction(potionID) || castSpell.SpellId.Rank > 0
+		}
+	}
+	if !usesPlan {
+		t.Fatalf("Expected the best plan to downrank Frostbolt or use the potion, got %q", best.Description)
+	}
+}
+
 var P1Talents = "-0550320003021-2035020310035105"
 
 var PlayerOptions = &proto.Player_Mage{
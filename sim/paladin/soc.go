@@ -110,11 +110,8 @@ func (paladin *Paladin) registerSealOfCommand() {
 				baseDamage := spell.Unit.MHWeaponDamage(sim, spell.MeleeAttackPower())
 				result := spell.CalcDamage(sim, target, baseDamage, spell.OutcomeMeleeSpecialHitAndCrit)
 
-				core.StartDelayedAction(sim, core.DelayedActionOptions{
-					DoAt: sim.CurrentTime + core.SpellBatchWindow,
-					OnAction: func(s *core.Simulation) {
-						spell.DealDamage(sim, result)
-					},
+				sim.DoInNextSpellBatch(func(sim *core.Simulation) {
+					spell.DealDamage(sim, result)
 				})
 			},
 		})
@@ -5,7 +5,9 @@ import (
 
 	"github.com/wowsims/classic/sim/core"
 	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
 	"github.com/wowsims/classic/sim/core/stats"
+	"github.com/wowsims/classic/sim/shaman"
 )
 
 func init() {
@@ -84,6 +86,276 @@ func TestBasicRaid(t *testing.T) {
 	core.RaidSimTest("P1 ST", t, rsr, 6323.79)
 }
 
+// Tests that a Shaman's totems buff the other members of its party, when party buffs come
+// from the party members.
+func TestPartyMemberTotemBuffs(t *testing.T) {
+	newEnhancementShaman := func(name string) *proto.Player {
+		return &proto.Player{
+			Name:      name,
+			Race:      proto.Race_RaceTroll,
+			Class:     proto.Class_ClassShaman,
+			Equipment: &proto.EquipmentSpec{},
+			Spec: &proto.Player_EnhancementShaman{
+				EnhancementShaman: &proto.EnhancementShaman{
+					Options: &proto.EnhancementShaman_Options{},
+				},
+			},
+			Consumes: &proto.Consumes{},
+			Buffs:    &proto.IndividualBuffs{},
+		}
+	}
+
+	sim := core.NewSim(&proto.RaidSimRequest{
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{Players: []*proto.Player{newEnhancementShaman("Totems"), newEnhancementShaman("Member")}},
+			},
+			BuffsFromMembers: true,
+		},
+		Encounter:  STEncounter,
+		SimOptions: SimOptions,
+	}, simsignals.CreateSignals())
+	sim.Reset()
+
+	party := sim.Raid.Parties[0]
+	totems := party.Players[0].(shaman.ShamanAgent).GetShaman()
+	member := party.Players[1].GetCharacter()
+	strength := member.GetStat(stats.Strength)
+
+	totems.StrengthOfEarthTotem[shaman.StrengthOfEarthTotemRanks].Cast(sim, sim.GetTargetUnit(0))
+	if gained := member.GetStat(stats.Strength) - strength; gained <= 0 {
+		t.Fatalf("Expected Strength of Earth Totem to buff the party member, got %0.1f Strength", gained)
+	}
+
+	// Windfury Totem is an air totem, so it doesn't replace Strength of Earth.
+	sim.CurrentTime += core.GCDDefault
+	totems.WindfuryTotem[shaman.WindfuryTotemRanks].Cast(sim, sim.GetTargetUnit(0))
+	if !member.GetAura("Strength of Earth Totem").IsActive() {
+		t.Fatalf("Windfury Totem replaced Strength of Earth Totem")
+	}
+}
+
+// Tests that Trueshot Aura gives party members the attack power of the Hunter's level.
+func TestPartyMemberTrueshotAura(t *testing.T) {
+	member := &proto.Player{
+		Name:      "Member",
+		Race:      proto.Race_RaceTroll,
+		Class:     proto.Class_ClassShaman,
+		Equipment: &proto.EquipmentSpec{},
+		Spec: &proto.Player_EnhancementShaman{
+			EnhancementShaman: &proto.EnhancementShaman{
+				Options: &proto.EnhancementShaman_Options{},
+			},
+		},
+		Consumes: &proto.Consumes{},
+		Buffs:    &proto.IndividualBuffs{},
+	}
+	hunter := &proto.Player{
+		Name:          "Hunter",
+		Race:          proto.Race_RaceTroll,
+		Class:         proto.Class_ClassHunter,
+		TalentsString: "-05451002503051",
+		Equipment:     &proto.EquipmentSpec{},
+		Spec: &proto.Player_Hunter{
+			Hunter: &proto.Hunter{
+				Options: &proto.Hunter_Options{},
+			},
+		},
+		Consumes: &proto.Consumes{},
+		Buffs:    &proto.IndividualBuffs{},
+	}
+
+	memberAttackPower := func(players ...*proto.Player) float64 {
+		sim := core.NewSim(&proto.RaidSimRequest{
+			Raid: &proto.Raid{
+				Parties:          []*proto.Party{{Players: players}},
+				BuffsFromMembers: true,
+			},
+			Encounter:  STEncounter,
+			SimOptions: SimOptions,
+		}, simsignals.CreateSignals())
+		sim.Reset()
+		return sim.Raid.Parties[0].Players[0].GetCharacter().GetStat(stats.AttackPower)
+	}
+
+	if gained := memberAttackPower(member, hunter) - memberAttackPower(member); gained != 100 {
+		t.Fatalf("Expected a level 60 Hunter's Trueshot Aura to give 100 attack power, got %0.1f", gained)
+	}
+}
+
+// Tests that the composition optimizer moves a melee player into the party of the Shaman
+// dropping Windfury Totem.
+func TestRaidCompositionMovesMeleeToWindfury(t *testing.T) {
+	windfuryTotem := &proto.APLListItem{
+		Action: &proto.APLAction{Action: &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{
+			SpellId: &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: shaman.WindfuryTotemSpellId[shaman.WindfuryTotemRanks]}},
+		}}},
+	}
+	roster := []*proto.Player{
+		{
+			Name:      "Shaman",
+			Race:      proto.Race_RaceTroll,
+			Class:     proto.Class_ClassShaman,
+			Equipment: &proto.EquipmentSpec{},
+			Rotation:  &proto.APLRotation{PriorityList: []*proto.APLListItem{windfuryTotem}},
+			Spec: &proto.Player_EnhancementShaman{
+				EnhancementShaman: &proto.EnhancementShaman{
+					Options: &proto.EnhancementShaman_Options{},
+				},
+			},
+			Consumes: &proto.Consumes{},
+			Buffs:    &proto.IndividualBuffs{},
+		},
+		{
+			// Auto attacks with Arcanite Reaper only, so the warrior gains from Windfury's extra attacks.
+			Name:  "Warrior",
+			Race:  proto.Race_RaceOrc,
+			Class: proto.Class_ClassWarrior,
+			Equipment: &proto.EquipmentSpec{
+				Items: []*proto.ItemSpec{{}, {}, {}, {}, {}, {}, {}, {}, {}, {}, {}, {}, {}, {}, {Id: 12784}, {}, {}},
+			},
+			Rotation: &proto.APLRotation{},
+			Spec: &proto.Player_Warrior{
+				Warrior: &proto.Warrior{
+					Options: &proto.Warrior_Options{},
+				},
+			},
+			Consumes: &proto.Consumes{},
+			Buffs:    &proto.IndividualBuffs{},
+		},
+	}
+	// Fill the raid to two parties with players who gain nothing from either.
+	for len(roster) < 7 {
+		roster = append(roster, &proto.Player{
+			Name:      "Mage",
+			Race:      proto.Race_RaceGnome,
+			Class:     proto.Class_ClassMage,
+			Equipment: &proto.EquipmentSpec{},
+			Rotation:  &proto.APLRotation{},
+			Spec: &proto.Player_Mage{
+				Mage: &proto.Mage{
+					Options: &proto.Mage_Options{},
+				},
+			},
+			Consumes: &proto.Consumes{},
+			Buffs:    &proto.IndividualBuffs{},
+		})
+	}
+
+	result := core.OptimizeRaidComposition(&proto.RaidCompositionRequest{
+		Roster: roster,
+		Encounter: &proto.Encounter{
+			Duration: 60,
+			Targets:  []*proto.Target{StandardTarget},
+		},
+		SimOptions:      &proto.SimOptions{Iterations: 10, RandomSeed: 101, IsTest: true},
+		QuickIterations: 20,
+		NumCandidates:   1,
+	}, simsignals.CreateSignals())
+	if result.Error != nil {
+		t.Fatalf("Optimizing failed: %s", result.Error.Message)
+	}
+
+	best := result.Candidates[0]
+	if best.EstimatedBuffDps <= 0 {
+		t.Fatalf("Expected Windfury Totem to be worth DPS to the warrior, got %0.1f", best.EstimatedBuffDps)
+	}
+	for _, party := range best.Raid.Parties {
+		names := make(map[string]bool)
+		for _, player := range party.Players {
+			names[player.GetName()] = true
+		}
+		if names["Shaman"] != names["Warrior"] {
+			t.Fatalf("Expected the warrior in the Shaman's party, got %v", best.Raid)
+		}
+	}
+}
+
+// Tests that a Rogue who uses Feint pulls aggro off the tank less often, when the
+// target tracks threat.
+func TestRogueFeintReducesAggroPulls(t *testing.T) {
+	feint := &proto.APLListItem{
+		Action: &proto.APLAction{Action: &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{
+			SpellId: &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: 1966}},
+		}}},
+	}
+
+	rogueAggro := func(useFeint bool) *proto.UnitMetrics {
+		rotation := core.GetAplRotation("../ui/rogue/apls", "combat_sinister_strike").Rotation
+		if useFeint {
+			rotation.PriorityList = append([]*proto.APLListItem{feint}, rotation.PriorityList...)
+		}
+		result := core.RunRaidSim(&proto.RaidSimRequest{
+			Raid: &proto.Raid{
+				Parties: []*proto.Party{{
+					Players: []*proto.Player{
+						{
+							// Auto attacks only, so the Rogue out-threats the tank without Feint.
+							Name:          "Tank",
+							Race:          proto.Race_RaceOrc,
+							Class:         proto.Class_ClassWarrior,
+							TalentsString: "20304300302-03-55200110530201051",
+							Equipment:     core.GetGearSet("../ui/tank_warrior/gear_sets", "p0.bis").GearSet,
+							Rotation:      &proto.APLRotation{},
+							Spec: &proto.Player_TankWarrior{
+								TankWarrior: &proto.TankWarrior{
+									Options: &proto.TankWarrior_Options{},
+								},
+							},
+							Consumes: &proto.Consumes{},
+							Buffs:    &proto.IndividualBuffs{},
+						},
+						{
+							// Ungeared, so its threat is close to the tank's.
+							Name:          "Rogue",
+							Race:          proto.Race_RaceHuman,
+							Class:         proto.Class_ClassRogue,
+							TalentsString: "005323105-0240052020050150231",
+							Equipment:     &proto.EquipmentSpec{},
+							Rotation:      rotation,
+							Spec: &proto.Player_Rogue{
+								Rogue: &proto.Rogue{
+									Options: &proto.RogueOptions{},
+								},
+							},
+							Consumes: &proto.Consumes{},
+							Buffs:    &proto.IndividualBuffs{},
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				}},
+				Tanks: []*proto.UnitReference{{Type: proto.UnitReference_Player, Index: 0}},
+			},
+			Encounter: &proto.Encounter{
+				Duration:       60,
+				UseThreatTable: true,
+				Targets:        []*proto.Target{StandardTarget},
+			},
+			SimOptions: &proto.SimOptions{
+				Iterations: 200,
+				RandomSeed: 101,
+				IsTest:     true,
+			},
+		})
+		if result.Error != nil {
+			t.Fatalf("Sim failed: %s", result.Error.Message)
+		}
+		return result.RaidMetrics.Parties[0].Players[1]
+	}
+
+	// Feint's energy and GCDs alone lower the Rogue's threat a little, while dropping
+	// threat lets the tank take aggro back soon after every pull.
+	withoutFeint, withFeint := rogueAggro(false), rogueAggro(true)
+	if withFeint.ChanceOfPullingAggro >= withoutFeint.ChanceOfPullingAggro {
+		t.Fatalf("Expected Feint to lower the Rogue's %0.2f chance of pulling aggro, got %0.2f",
+			withoutFeint.ChanceOfPullingAggro, withFeint.ChanceOfPullingAggro)
+	}
+	if withFeint.SecondsWithAggroAvg >= withoutFeint.SecondsWithAggroAvg/4 {
+		t.Fatalf("Expected Feint to cut the Rogue's %0.1fs with aggro to under a quarter, got %0.1fs",
+			withoutFeint.SecondsWithAggroAvg, withFeint.SecondsWithAggroAvg)
+	}
+}
+
 // To quickly debug raid sim issues, uncomment this test and copy in a request string.
 /*
 func testRaidString(t *testing.T, raidString string) {
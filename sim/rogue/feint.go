@@ -7,6 +7,13 @@ import (
 )
 
 func (rogue *Rogue) registerFeintSpell() {
+	threatReduction := map[int32]float64{
+		25: 150,
+		40: 390,
+		50: 390,
+		60: core.TernaryFloat64(core.IncludeAQ, 800, 600),
+	}[rogue.Level]
+
 	rogue.Feint = rogue.RegisterSpell(core.SpellConfig{
 		ActionID:    core.ActionID{SpellID: 1966},
 		SpellSchool: core.SpellSchoolPhysical,
@@ -32,7 +39,12 @@ func (rogue *Rogue) registerFeintSpell() {
 
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
 			rogue.BreakStealth(sim)
-			spell.CalcAndDealOutcome(sim, target, spell.OutcomeMeleeSpecialHit)
+			result := spell.CalcAndDealOutcome(sim, target, spell.OutcomeMeleeSpecialHit)
+
+			// Only targets tracking aggro have threat to lose, so other sims are unchanged.
+			if result.Landed() && target.ThreatTable != nil && sim.Encounter.UseThreatTable {
+				target.ThreatTable.AddThreat(sim, &rogue.Unit, -threatReduction)
+			}
 		},
 	})
 }
@@ -39,12 +39,22 @@ func (shaman *Shaman) newWindfuryTotemSpellConfig(rank int) core.SpellConfig {
 
 	duration := time.Second * 120
 
+	// Without party buffs from members, Windfury is covered by the Windfury weapon imbue consumable.
+	var buffAuras core.AuraArray
+	if shaman.Env.Raid.BuffsFromMembers {
+		buffAuras = shaman.NewPartyAuraArray(func(unit *core.Unit) *core.Aura {
+			return core.WindfuryTotemAura(unit, int32(rank))
+		})
+	}
+
 	spell := shaman.newTotemSpellConfig(manaCost, spellId)
 	spell.RequiredLevel = level
 	spell.Rank = rank
 	spell.ApplyEffects = func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
-		shaman.TotemExpirations[EarthTotem] = sim.CurrentTime + duration
-		shaman.ActiveTotems[EarthTotem] = spell
+		shaman.TotemExpirations[AirTotem] = sim.CurrentTime + duration
+		shaman.ActiveTotems[AirTotem] = spell
+
+		shaman.activateTotemBuffs(sim, AirTotem, buffAuras)
 	}
 	return spell
 }
@@ -80,7 +90,9 @@ func (shaman *Shaman) newGraceOfAirTotemSpellConfig(rank int) core.SpellConfig {
 	duration := time.Second * 120
 	multiplier := []float64{1, 1.08, 1.15}[shaman.Talents.EnhancingTotems]
 
-	buffAura := core.GraceOfAirTotemAura(&shaman.Unit, multiplier)
+	buffAuras := shaman.newTotemBuffAuras(func(unit *core.Unit) *core.Aura {
+		return core.GraceOfAirTotemAura(unit, multiplier)
+	})
 
 	spell := shaman.newTotemSpellConfig(manaCost, spellId)
 	spell.RequiredLevel = level
@@ -89,7 +101,7 @@ func (shaman *Shaman) newGraceOfAirTotemSpellConfig(rank int) core.SpellConfig {
 		shaman.TotemExpirations[AirTotem] = sim.CurrentTime + duration
 		shaman.ActiveTotems[AirTotem] = spell
 
-		buffAura.Activate(sim)
+		shaman.activateTotemBuffs(sim, AirTotem, buffAuras)
 	}
 	return spell
 }
@@ -130,6 +142,8 @@ func (shaman *Shaman) newWindwallTotemSpellConfig(rank int) core.SpellConfig {
 	spell.ApplyEffects = func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
 		shaman.TotemExpirations[AirTotem] = sim.CurrentTime + duration
 		shaman.ActiveTotems[AirTotem] = spell
+
+		shaman.activateTotemBuffs(sim, AirTotem, nil)
 	}
 	return spell
 }
@@ -37,7 +37,9 @@ func (shaman *Shaman) newStrengthOfEarthTotemSpellConfig(rank int) core.SpellCon
 	duration := time.Second * 120
 	multiplier := []float64{1, 1.08, 1.15}[shaman.Talents.EnhancingTotems]
 
-	buffAura := core.StrengthOfEarthTotemAura(&shaman.Unit, multiplier)
+	buffAuras := shaman.newTotemBuffAuras(func(unit *core.Unit) *core.Aura {
+		return core.StrengthOfEarthTotemAura(unit, multiplier)
+	})
 
 	spell := shaman.newTotemSpellConfig(manaCost, spellId)
 	spell.RequiredLevel = level
@@ -46,7 +48,7 @@ func (shaman *Shaman) newStrengthOfEarthTotemSpellConfig(rank int) core.SpellCon
 		shaman.TotemExpirations[EarthTotem] = sim.CurrentTime + duration
 		shaman.ActiveTotems[EarthTotem] = spell
 
-		buffAura.Activate(sim)
+		shaman.activateTotemBuffs(sim, EarthTotem, buffAuras)
 	}
 	return spell
 }
@@ -81,6 +83,10 @@ func (shaman *Shaman) newStoneskinTotemSpellConfig(rank int) core.SpellConfig {
 
 	duration := time.Second * 120
 
+	buffAuras := shaman.newTotemBuffAuras(func(unit *core.Unit) *core.Aura {
+		return core.StoneskinTotemAura(unit, shaman.Talents.GuardianTotems)
+	})
+
 	spell := shaman.newTotemSpellConfig(manaCost, spellId)
 	spell.RequiredLevel = level
 	spell.Rank = rank
@@ -88,7 +94,7 @@ func (shaman *Shaman) newStoneskinTotemSpellConfig(rank int) core.SpellConfig {
 		shaman.TotemExpirations[EarthTotem] = sim.CurrentTime + duration
 		shaman.ActiveTotems[EarthTotem] = spell
 
-		core.StoneskinTotemAura(&shaman.Unit, shaman.Talents.GuardianTotems).Activate(sim)
+		shaman.activateTotemBuffs(sim, EarthTotem, buffAuras)
 	}
 	return spell
 }
@@ -104,6 +110,8 @@ func (shaman *Shaman) registerTremorTotemSpell() {
 	spell.ApplyEffects = func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
 		shaman.TotemExpirations[EarthTotem] = sim.CurrentTime + duration
 		shaman.ActiveTotems[EarthTotem] = spell
+
+		shaman.activateTotemBuffs(sim, EarthTotem, nil)
 	}
 	shaman.TremorTotem = shaman.RegisterSpell(spell)
 	shaman.EarthTotems = append(shaman.EarthTotems, shaman.TremorTotem)
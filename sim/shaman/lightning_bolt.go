@@ -47,6 +47,10 @@ func (shaman *Shaman) newLightningBoltSpellConfig(rank int) core.SpellConfig {
 	spell.Rank = rank
 	spell.BonusCoefficient = spellCoeff
 
+	spell.ExpectedInitialDamage = func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
+		baseDamage := (baseDamageLow + baseDamageHigh) / 2
+		return spell.CalcDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicHitAndCrit)
+	}
 	spell.ApplyEffects = func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
 		baseDamage := sim.Roll(baseDamageLow, baseDamageHigh)
 		result := spell.CalcDamage(sim, target, baseDamage, spell.OutcomeMagicHitAndCrit)
@@ -125,6 +125,8 @@ type Shaman struct {
 	Totems           *proto.ShamanTotems
 	TotemExpirations [4]time.Duration // The expiration time of each totem (earth, air, fire, water).
 
+	totemBuffAuras [4]core.AuraArray // Party buffs of the active totems, when the raid derives buffs from its members.
+
 	// Shield
 	ActiveShield     *core.Spell // Tracks the Shaman's active shield spell
 	ActiveShieldAura *core.Aura
@@ -146,6 +148,14 @@ func (shaman *Shaman) AddRaidBuffs(_ *proto.RaidBuffs) {
 	// Buffs are handled explicitly through APLs now
 }
 
+// Totems are dropped through the APL, but Mana Tide Totem is a cooldown of its own which
+// goes to the party when the raid derives buffs from its members.
+func (shaman *Shaman) AddPartyBuffs(partyBuffs *proto.PartyBuffs) {
+	if shaman.Talents.ManaTideTotem && shaman.Env.Raid.BuffsFromMembers {
+		partyBuffs.ManaTideTotems++
+	}
+}
+
 func (shaman *Shaman) Initialize() {
 	// Core abilities
 	shaman.registerChainLightningSpell()
@@ -192,5 +202,6 @@ func (shaman *Shaman) Reset(_ *core.Simulation) {
 
 	for i := range shaman.TotemExpirations {
 		shaman.TotemExpirations[i] = 0
+		shaman.totemBuffAuras[i] = nil
 	}
 }
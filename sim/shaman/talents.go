@@ -243,16 +243,13 @@ func (shaman *Shaman) registerElementalMasteryCD() {
 		OnCastComplete: func(aura *core.Aura, sim *core.Simulation, spell *core.Spell) {
 			if shaman.isShamanDamagingSpell(spell) {
 				// Elemental mastery can be batched
-				core.StartDelayedAction(sim, core.DelayedActionOptions{
-					DoAt: sim.CurrentTime + core.SpellBatchWindow,
-					OnAction: func(sim *core.Simulation) {
-						if aura.IsActive() {
-							// Remove the buff and put skill on CD
-							aura.Deactivate(sim)
-							cdTimer.Set(sim.CurrentTime + cd)
-							shaman.UpdateMajorCooldowns()
-						}
-					},
+				sim.DoInNextSpellBatch(func(sim *core.Simulation) {
+					if aura.IsActive() {
+						// Remove the buff and put skill on CD
+						aura.Deactivate(sim)
+						cdTimer.Set(sim.CurrentTime + cd)
+						shaman.UpdateMajorCooldowns()
+					}
 				})
 			}
 		},
@@ -2,6 +2,7 @@ package shaman
 
 import (
 	"github.com/wowsims/classic/sim/core"
+	"github.com/wowsims/classic/sim/core/proto"
 )
 
 func (shaman *Shaman) newTotemSpellConfig(flatCost float64, spellID int32) core.SpellConfig {
@@ -21,3 +22,39 @@ func (shaman *Shaman) newTotemSpellConfig(flatCost float64, spellID int32) core.
 		},
 	}
 }
+
+// Returns the buff auras of a totem. When the raid derives buffs from its members, totems
+// buff the Shaman's whole party. Otherwise only the Shaman, with the raid buff toggles
+// covering everyone else.
+func (shaman *Shaman) newTotemBuffAuras(makeAura func(*core.Unit) *core.Aura) core.AuraArray {
+	if shaman.Env.Raid.BuffsFromMembers {
+		return shaman.NewPartyAuraArray(makeAura)
+	}
+	return core.AuraArray{makeAura(&shaman.Unit)}
+}
+
+// Activates the buffs of a newly dropped totem, which may have none. When totems buff
+// the party, the buffs of the totem it replaces end with it.
+func (shaman *Shaman) activateTotemBuffs(sim *core.Simulation, totemType int, auras core.AuraArray) {
+	if shaman.Env.Raid.BuffsFromMembers {
+		for _, aura := range shaman.totemBuffAuras[totemType] {
+			if aura != nil {
+				aura.Deactivate(sim)
+			}
+		}
+		shaman.totemBuffAuras[totemType] = auras
+	}
+
+	for _, aura := range auras {
+		if aura != nil {
+			aura.Activate(sim)
+		}
+	}
+}
+
+func (shaman *Shaman) ClearProvidedBuffs(raidBuffs *proto.RaidBuffs) {
+	raidBuffs.StrengthOfEarthTotem = proto.TristateEffect_TristateEffectMissing
+	raidBuffs.GraceOfAirTotem = proto.TristateEffect_TristateEffectMissing
+	raidBuffs.ManaSpringTotem = proto.TristateEffect_TristateEffectMissing
+	raidBuffs.StoneskinTotem = proto.TristateEffect_TristateEffectMissing
+}
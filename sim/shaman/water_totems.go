@@ -78,6 +78,7 @@ func (shaman *Shaman) newHealingStreamTotemSpellConfig(rank int) core.SpellConfi
 		shaman.TotemExpirations[WaterTotem] = sim.CurrentTime + duration
 		shaman.ActiveTotems[WaterTotem] = spell
 
+		shaman.activateTotemBuffs(sim, WaterTotem, nil)
 		for _, agent := range shaman.Party.Players {
 			spell.Hot(&agent.GetCharacter().Unit).Activate(sim)
 		}
@@ -119,12 +120,23 @@ func (shaman *Shaman) newManaSpringTotemSpellConfig(rank int) core.SpellConfig {
 
 	duration := time.Second * 60
 
+	// Without party buffs from members, Mana Spring is covered by the raid buff toggle.
+	var buffAuras core.AuraArray
+	if shaman.Env.Raid.BuffsFromMembers {
+		multiplier := 1 + shaman.restorativeTotemsModifier()
+		buffAuras = shaman.NewPartyAuraArray(func(unit *core.Unit) *core.Aura {
+			return core.ManaSpringTotemAura(unit, multiplier)
+		})
+	}
+
 	spell := shaman.newTotemSpellConfig(manaCost, spellId)
 	spell.RequiredLevel = level
 	spell.Rank = rank
 	spell.ApplyEffects = func(sim *core.Simulation, _ *core.Unit, spell *core.Spell) {
 		shaman.TotemExpirations[WaterTotem] = sim.CurrentTime + duration
 		shaman.ActiveTotems[WaterTotem] = spell
+
+		shaman.activateTotemBuffs(sim, WaterTotem, buffAuras)
 	}
 	return spell
 }
@@ -2,10 +2,13 @@ package dpswarrior
 
 import (
 	"testing"
+	"time"
 
 	_ "github.com/wowsims/classic/sim/common" // imported to get item effects included.
 	"github.com/wowsims/classic/sim/core"
 	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	"github.com/wowsims/classic/sim/warrior"
 )
 
 func init() {
@@ -34,6 +37,68 @@ func TestP1DPSWarrior(t *testing.T) {
 	}))
 }
 
+// With a 400ms spell batch window, the dodge which enables Overpower lands in one batch
+// and the Overpower cast in reaction to it lands in the next.
+func TestOverpowerSpellBatching(t *testing.T) {
+	sim := core.NewSim(&proto.RaidSimRequest{
+		Raid: core.SinglePlayerRaidProto(&proto.Player{
+			Name:          "Warrior",
+			Race:          proto.Race_RaceOrc,
+			Class:         proto.Class_ClassWarrior,
+			TalentsString: P1Talents,
+			Equipment:     core.GetGearSet("../../../ui/warrior/gear_sets", "p0.bis").GearSet,
+			Rotation: &proto.APLRotation{
+				Type: proto.APLRotation_TypeAPL,
+				PriorityList: []*proto.APLListItem{
+					{Action: &proto.APLAction{Action: &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{
+						SpellId: core.ActionID{SpellID: 11585}.ToProto(),
+					}}}},
+				},
+			},
+			Spec: &proto.Player_Warrior{
+				Warrior: &proto.Warrior{
+					Options: &proto.Warrior_Options{StartingRage: 100, Stance: proto.WarriorStance_WarriorStanceBattle},
+				},
+			},
+			Consumes: &proto.Consumes{},
+			Buffs:    &proto.IndividualBuffs{},
+		}, &proto.PartyBuffs{}, &proto.RaidBuffs{}, &proto.Debuffs{}),
+		Encounter: &proto.Encounter{
+			Targets:  []*proto.Target{{Level: 63, MobType: proto.MobType_MobTypeDemon}},
+			Duration: 300,
+		},
+		SimOptions: &proto.SimOptions{RandomSeed: 101, SpellBatchWindow: 0.4},
+	}, simsignals.CreateSignals())
+
+	player := sim.Raid.Parties[0].Players[0].(warrior.WarriorAgent).GetWarrior()
+	var dodgeAt, overpowerAt time.Duration = -1, -1
+	player.OverpowerAura.ApplyOnGain(func(_ *core.Aura, sim *core.Simulation) {
+		if dodgeAt == -1 {
+			dodgeAt = sim.CurrentTime
+		}
+	})
+	applyOverpower := player.Overpower.ApplyEffects
+	player.Overpower.ApplyEffects = func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
+		if overpowerAt == -1 {
+			overpowerAt = sim.CurrentTime
+		}
+		applyOverpower(sim, target, spell)
+	}
+
+	sim.Reset()
+	sim.PrePull()
+	for finished := false; !finished && overpowerAt == -1; {
+		finished = sim.Step()
+	}
+
+	if dodgeAt <= 0 || dodgeAt%(time.Millisecond*400) != 0 {
+		t.Fatalf("Expected a dodge to enable Overpower in a spell batch, got %s", dodgeAt)
+	}
+	if overpowerAt != dodgeAt+time.Millisecond*400 {
+		t.Fatalf("Expected Overpower to land in the batch after the dodge at %s, got %s", dodgeAt, overpowerAt)
+	}
+}
+
 var P1Talents = "20305020302-05050005525010051"
 
 var P1Consumes = core.ConsumesCombo{
@@ -6,6 +6,8 @@ import (
 	_ "github.com/wowsims/classic/sim/common" // imported to get item effects included.
 	"github.com/wowsims/classic/sim/core"
 	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/stats"
+	googleProto "google.golang.org/protobuf/proto"
 )
 
 func init() {
@@ -34,6 +36,67 @@ func TestP1TankWarrior(t *testing.T) {
 	}))
 }
 
+func TestTankWarriorCrushImmunity(t *testing.T) {
+	equipment := core.GetGearSet("../../../ui/tank_warrior/gear_sets", "p0.bis").GearSet
+	// Drillborer Disk, since blocking needs a shield.
+	equipment.Items[proto.ItemSlot_ItemSlotOffHand] = &proto.ItemSpec{Id: 17066}
+
+	result := core.RunRaidSim(&proto.RaidSimRequest{
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{
+							Name:          "Tank",
+							Race:          proto.Race_RaceOrc,
+							Class:         proto.Class_ClassWarrior,
+							TalentsString: P1Talents,
+							Equipment:     equipment,
+							// Only auto attack, since a tank casting Slam can't dodge, parry or block.
+							Rotation: &proto.APLRotation{},
+							Consumes: &proto.Consumes{},
+							Buffs:    &proto.IndividualBuffs{},
+							Spec:     PlayerOptionsBasic,
+							// Enough avoidance and block to push crushing blows off the table.
+							BonusStats: &proto.UnitStats{
+								Stats: stats.Stats{stats.Dodge: 30, stats.Block: 40}.ToFloatArray(),
+							},
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				},
+			},
+			Tanks: []*proto.UnitReference{
+				{Type: proto.UnitReference_Player, Index: 0},
+			},
+		},
+		Encounter: &proto.Encounter{
+			Targets:  []*proto.Target{googleProto.Clone(core.DefaultTargetProtoLvl60).(*proto.Target)},
+			Duration: 60,
+		},
+		SimOptions: &proto.SimOptions{
+			Iterations: 20,
+			RandomSeed: 101,
+			IsTest:     true,
+		},
+	})
+	if result.Error != nil {
+		t.Fatalf("Sim failed: %s", result.Error.Message)
+	}
+
+	survivability := result.RaidMetrics.Parties[0].Players[0].Survivability
+	if survivability == nil {
+		t.Fatalf("Expected survivability metrics for the tank")
+	}
+	if survivability.AttackTable.Parry <= 0 || survivability.AttackTable.Block <= 0 {
+		t.Fatalf("Expected the shield-equipped warrior to parry and block, got %v", survivability.AttackTable)
+	}
+	if survivability.AttackTable.Crush != 0 || survivability.CrushImmunityDeficit != 0 || survivability.Outcomes.Crush != 0 {
+		t.Fatalf("Expected the warrior to be crush immune, got a %0.4f crush chance, %0.4f deficit and %0.4f crushes",
+			survivability.AttackTable.Crush, survivability.CrushImmunityDeficit, survivability.Outcomes.Crush)
+	}
+}
+
 var P1Talents = "20304300302-03-55200110530201051"
 
 var PlayerOptionsBasic = &proto.Player_TankWarrior{
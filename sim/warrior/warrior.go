@@ -128,6 +128,10 @@ func (warrior *Warrior) AddRaidBuffs(raidBuffs *proto.RaidBuffs) {
 func (warrior *Warrior) AddPartyBuffs(_ *proto.PartyBuffs) {
 }
 
+func (warrior *Warrior) ClearProvidedBuffs(raidBuffs *proto.RaidBuffs) {
+	raidBuffs.BattleShout = proto.TristateEffect_TristateEffectMissing
+}
+
 func (warrior *Warrior) RegisterSpell(stanceMask Stance, config core.SpellConfig) *WarriorSpell {
 	ws := &WarriorSpell{
 		StanceMask: stanceMask,
@@ -38,10 +38,67 @@ func main() {
 	js.Global().Set("statWeightsAsync", js.FuncOf(statWeightsAsync))
 	js.Global().Set("bulkSimAsync", js.FuncOf(bulkSimAsync))
 	js.Global().Set("abortById", js.FuncOf(abortById))
+	for name, handler := range protoFuncs {
+		js.Global().Set(name, js.FuncOf(handler.call))
+	}
 	js.Global().Call("wasmready")
 	<-c
 }
 
+// A function taking and returning a binary proto, which handles a request synchronously.
+type protoFunc struct {
+	msg    func() googleProto.Message
+	handle func(msg googleProto.Message) googleProto.Message
+}
+
+// Functions with the same names and protos as the web server's routes.
+var protoFuncs = map[string]protoFunc{
+	"raidComposition": {msg: func() googleProto.Message { return &proto.RaidCompositionRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunRaidComposition(msg.(*proto.RaidCompositionRequest))
+	}},
+	"blessingsPlan": {msg: func() googleProto.Message { return &proto.BlessingsPlanRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunBlessingsPlan(msg.(*proto.BlessingsPlanRequest))
+	}},
+	"characterImport": {msg: func() googleProto.Message { return &proto.CharacterImportRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunCharacterImport(msg.(*proto.CharacterImportRequest))
+	}},
+	"defensivePlan": {msg: func() googleProto.Message { return &proto.DefensivePlanRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunDefensivePlan(msg.(*proto.DefensivePlanRequest))
+	}},
+	"manaPlan": {msg: func() googleProto.Message { return &proto.ManaPlanRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunManaPlan(msg.(*proto.ManaPlanRequest))
+	}},
+	"consumableValue": {msg: func() googleProto.Message { return &proto.ConsumableValueRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunConsumableValue(msg.(*proto.ConsumableValueRequest))
+	}},
+	"worldBuffTimeline": {msg: func() googleProto.Message { return &proto.WorldBuffTimelineRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunWorldBuffTimeline(msg.(*proto.WorldBuffTimelineRequest))
+	}},
+	"raidNight": {msg: func() googleProto.Message { return &proto.RaidNightRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunRaidNight(msg.(*proto.RaidNightRequest))
+	}},
+}
+
+func (pf protoFunc) call(this js.Value, args []js.Value) interface{} {
+	msg := pf.msg()
+	if err := googleProto.Unmarshal(getArgsBinary(args[0]), msg); err != nil {
+		log.Printf("Failed to parse request: %s", err)
+		return nil
+	}
+	result := pf.handle(msg)
+
+	outbytes, err := googleProto.Marshal(result)
+	if err != nil {
+		log.Printf("[ERROR] Failed to marshal result: %s", err.Error())
+		return nil
+	}
+
+	outArray := js.Global().Get("Uint8Array").New(len(outbytes))
+	js.CopyBytesToJS(outArray, outbytes)
+
+	return outArray
+}
+
 func computeStats(this js.Value, args []js.Value) (response interface{}) {
 	defer func() {
 		if err := recover(); err != nil {
@@ -106,6 +106,30 @@ var handlers = map[string]apiHandler{
 	"/computeStats": {msg: func() googleProto.Message { return &proto.ComputeStatsRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
 		return core.ComputeStats(msg.(*proto.ComputeStatsRequest))
 	}},
+	"/raidComposition": {msg: func() googleProto.Message { return &proto.RaidCompositionRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunRaidComposition(msg.(*proto.RaidCompositionRequest))
+	}},
+	"/blessingsPlan": {msg: func() googleProto.Message { return &proto.BlessingsPlanRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunBlessingsPlan(msg.(*proto.BlessingsPlanRequest))
+	}},
+	"/characterImport": {msg: func() googleProto.Message { return &proto.CharacterImportRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunCharacterImport(msg.(*proto.CharacterImportRequest))
+	}},
+	"/defensivePlan": {msg: func() googleProto.Message { return &proto.DefensivePlanRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunDefensivePlan(msg.(*proto.DefensivePlanRequest))
+	}},
+	"/manaPlan": {msg: func() googleProto.Message { return &proto.ManaPlanRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunManaPlan(msg.(*proto.ManaPlanRequest))
+	}},
+	"/consumableValue": {msg: func() googleProto.Message { return &proto.ConsumableValueRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunConsumableValue(msg.(*proto.ConsumableValueRequest))
+	}},
+	"/worldBuffTimeline": {msg: func() googleProto.Message { return &proto.WorldBuffTimelineRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunWorldBuffTimeline(msg.(*proto.WorldBuffTimelineRequest))
+	}},
+	"/raidNight": {msg: func() googleProto.Message { return &proto.RaidNightRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunRaidNight(msg.(*proto.RaidNightRequest))
+	}},
 	"/abortById": {msg: func() googleProto.Message { return &proto.AbortRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
 		requestId := msg.(*proto.AbortRequest).RequestId
 		triggered := simsignals.AbortById(requestId)
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wowsims/classic/sim/core"
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/stats"
+	"github.com/wowsims/classic/tools"
+)
+
+// Kinds of item effects which can't be represented as stats, and need an effect
+// registered in sim/common or a class package.
+const (
+	ItemEffectUse         = "Use"
+	ItemEffectChanceOnHit = "Chance on hit"
+	ItemEffectEquipProc   = "Equip proc"
+)
+
+var useEffectRegex = regexp.MustCompile(`Use: `)
+var chanceOnHitRegex = regexp.MustCompile(`Chance on hit: `)
+
+// Equip effects which proc, e.g. "Your melee attacks have a 5% chance to ..." or "When struck in combat ...".
+// Stat equips such as "Improves your chance to hit by 1%." don't match.
+var equipProcRegex = regexp.MustCompile(`(?i)Equip: (?:<a[^>]*>)?[^<]*(?:chance on|chance when|when struck|% chance|sometimes)`)
+
+var setBonusRegex = regexp.MustCompile(`\(([0-9]+)\) Set ?: `)
+
+// Returns the kinds of effects in the item's tooltip, ignoring its set bonuses.
+func (item WowheadItemResponse) GetEffectTypes() []string {
+	tooltip := item.TooltipWithoutSetBonus()
+
+	var effects []string
+	if useEffectRegex.MatchString(tooltip) {
+		effects = append(effects, ItemEffectUse)
+	}
+	if chanceOnHitRegex.MatchString(tooltip) {
+		effects = append(effects, ItemEffectChanceOnHit)
+	}
+	if equipProcRegex.MatchString(tooltip) {
+		effects = append(effects, ItemEffectEquipProc)
+	}
+	return effects
+}
+
+// Returns the number of pieces needed for each of the item's set bonuses.
+func (item WowheadItemResponse) GetSetBonusThresholds() []int32 {
+	var thresholds []int32
+	for _, match := range setBonusRegex.FindAllStringSubmatch(item.Tooltip, -1) {
+		numItems, _ := strconv.Atoi(match[1])
+		if !slices.Contains(thresholds, int32(numItems)) {
+			thresholds = append(thresholds, int32(numItems))
+		}
+	}
+	return thresholds
+}
+
+type ItemEffectCoverage struct {
+	Item    *proto.UIItem
+	Effects []string
+}
+
+type SetBonusCoverage struct {
+	SetName string
+	Missing []int32
+}
+
+type EffectCoverageReport struct {
+	// Items with effects which aren't registered.
+	Items []ItemEffectCoverage
+	// Number of items with effects, implemented or not.
+	NumItemsWithEffects int
+
+	// Sets with bonuses which aren't registered.
+	Sets []SetBonusCoverage
+
+	// Enchants without stats or a registered effect.
+	Enchants []*proto.UIEnchant
+}
+
+// Cross-references the effects in the item tooltips against the item effects, set
+// bonuses and enchant effects registered in the sim. Effects need to be registered
+// before calling this, e.g. with sim.RegisterAll().
+func (db *WowDatabase) ComputeEffectCoverage(tooltips map[int32]WowheadItemResponse) *EffectCoverageReport {
+	report := &EffectCoverageReport{}
+	missingSetBonuses := make(map[string][]int32)
+
+	for _, item := range db.Items {
+		tooltip, ok := tooltips[item.Id]
+		if !ok {
+			continue
+		}
+
+		if effects := tooltip.GetEffectTypes(); len(effects) > 0 {
+			report.NumItemsWithEffects++
+			if !core.HasItemEffect(item.Id) {
+				report.Items = append(report.Items, ItemEffectCoverage{Item: item, Effects: effects})
+			}
+		}
+
+		if item.SetName == "" {
+			continue
+		}
+		for _, numItems := range tooltip.GetSetBonusThresholds() {
+			if !core.HasItemSetBonus(item.SetName, numItems) && !slices.Contains(missingSetBonuses[item.SetName], numItems) {
+				missingSetBonuses[item.SetName] = append(missingSetBonuses[item.SetName], numItems)
+			}
+		}
+	}
+
+	for setName, missing := range missingSetBonuses {
+		slices.Sort(missing)
+		report.Sets = append(report.Sets, SetBonusCoverage{SetName: setName, Missing: missing})
+	}
+
+	for _, enchant := range db.Enchants {
+		if enchant.EffectId != 0 && stats.FromFloatArray(enchant.Stats).Equals(stats.Stats{}) &&
+			!core.HasEnchantEffect(enchant.EffectId) && !core.HasWeaponEffect(enchant.EffectId) {
+			report.Enchants = append(report.Enchants, enchant)
+		}
+	}
+
+	slices.SortFunc(report.Items, func(a, b ItemEffectCoverage) int { return int(a.Item.Id - b.Item.Id) })
+	slices.SortFunc(report.Sets, func(a, b SetBonusCoverage) int { return strings.Compare(a.SetName, b.SetName) })
+	slices.SortFunc(report.Enchants, func(a, b *proto.UIEnchant) int { return int(a.EffectId - b.EffectId) })
+	return report
+}
+
+// Sets UnimplementedEffect on the items whose effects aren't registered.
+func (db *WowDatabase) MarkUnimplementedEffects(report *EffectCoverageReport) {
+	for _, item := range db.Items {
+		item.UnimplementedEffect = false
+	}
+	for _, coverage := range report.Items {
+		if item, ok := db.Items[coverage.Item.Id]; ok {
+			item.UnimplementedEffect = true
+		}
+	}
+}
+
+// Writes the report as CSV, one line per unimplemented item effect, set bonus or enchant.
+func (report *EffectCoverageReport) WriteCsv(filePath string) {
+	buffer := new(bytes.Buffer)
+	writer := csv.NewWriter(buffer)
+	writer.Write([]string{"Type", "ID", "Name", "Missing"})
+	for _, coverage := range report.Items {
+		writer.Write([]string{"Item", strconv.Itoa(int(coverage.Item.Id)), coverage.Item.Name, strings.Join(coverage.Effects, "; ")})
+	}
+	for _, set := range report.Sets {
+		missing := make([]string, len(set.Missing))
+		for i, numItems := range set.Missing {
+			missing[i] = fmt.Sprintf("(%d) Set", numItems)
+		}
+		writer.Write([]string{"Set", "", set.SetName, strings.Join(missing, "; ")})
+	}
+	for _, enchant := range report.Enchants {
+		writer.Write([]string{"Enchant", strconv.Itoa(int(enchant.EffectId)), enchant.Name, "Effect"})
+	}
+	writer.Flush()
+	tools.WriteFile(filePath, buffer.String())
+}
+
+func (report *EffectCoverageReport) Summary() string {
+	return fmt.Sprintf("Item effects: %d/%d implemented, %d sets with missing bonuses, %d enchants without stats or effects",
+		report.NumItemsWithEffects-len(report.Items), report.NumItemsWithEffects, len(report.Sets), len(report.Enchants))
+}
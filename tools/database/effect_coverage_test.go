@@ -0,0 +1,81 @@
+package database
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/wowsims/classic/sim/core"
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/stats"
+)
+
+const (
+	itemHandOfJustice             = 11815
+	itemTalismanOfEphemeralPower  = 18820
+	itemBloodGuardsChainVices     = 22862
+	itemSetIdChampionsPursuance   = 543
+	itemSetNameChampionsPursuance = "Champion's Pursuance"
+)
+
+func TestGetEffectTypes(t *testing.T) {
+	for _, test := range []struct {
+		tooltip string
+		effects []string
+	}{
+		{"Use: Increases damage and healing done by magical spells and effects by up to 175 for 15 sec.", []string{ItemEffectUse}},
+		{"Chance on hit: Blasts a target for 150 Fire damage.", []string{ItemEffectChanceOnHit}},
+		{"Equip: 2% chance on melee hit to gain 1 extra attack.", []string{ItemEffectEquipProc}},
+		{"Equip: Improves your chance to hit by 1%.", nil},
+		// Set bonuses are effects of the set, not the item.
+		{"Equip: Increases attack power by 20.(2) Set : Use: Reduces the cooldown of Concussive Shot by 1 sec.", nil},
+	} {
+		if effects := (WowheadItemResponse{Tooltip: test.tooltip}).GetEffectTypes(); !slices.Equal(effects, test.effects) {
+			t.Errorf("Expected effects %v for %q, got %v", test.effects, test.tooltip, effects)
+		}
+	}
+}
+
+func TestComputeEffectCoverage(t *testing.T) {
+	if !core.HasItemEffect(itemHandOfJustice) {
+		core.NewItemEffect(itemHandOfJustice, func(agent core.Agent) {})
+	}
+	if !core.HasItemSetBonus(itemSetNameChampionsPursuance, 2) {
+		core.NewItemSet(core.ItemSet{
+			ID:      itemSetIdChampionsPursuance,
+			Name:    itemSetNameChampionsPursuance,
+			Bonuses: map[int32]core.ApplyEffect{2: func(agent core.Agent) {}},
+		})
+	}
+
+	db := NewWowDatabase()
+	db.Items[itemHandOfJustice] = &proto.UIItem{Id: itemHandOfJustice, UnimplementedEffect: true}
+	db.Items[itemTalismanOfEphemeralPower] = &proto.UIItem{Id: itemTalismanOfEphemeralPower}
+	db.Items[itemBloodGuardsChainVices] = &proto.UIItem{Id: itemBloodGuardsChainVices, SetName: itemSetNameChampionsPursuance}
+	db.Enchants[EnchantDBKey{EffectID: 1}] = &proto.UIEnchant{EffectId: 1, Stats: stats.Stats{stats.Agility: 7}.ToFloatArray()}
+	db.Enchants[EnchantDBKey{EffectID: 2}] = &proto.UIEnchant{EffectId: 2}
+	// Enchants without an effect ID can't have an effect registered.
+	db.Enchants[EnchantDBKey{ItemID: 3}] = &proto.UIEnchant{ItemId: 3}
+
+	tooltips := map[int32]WowheadItemResponse{
+		itemHandOfJustice:            {Tooltip: "Equip: 2% chance on melee hit to gain 1 extra attack."},
+		itemTalismanOfEphemeralPower: {Tooltip: "Use: Increases damage and healing done by magical spells and effects by up to 175 for 15 sec."},
+		itemBloodGuardsChainVices:    {Tooltip: "(2) Set : +20 Agility.(4) Set : Reduces the cooldown of your Concussive Shot by 1 sec.(6) Set : +20 Stamina."},
+	}
+	report := db.ComputeEffectCoverage(tooltips)
+
+	if report.NumItemsWithEffects != 2 || len(report.Items) != 1 || report.Items[0].Item.Id != itemTalismanOfEphemeralPower ||
+		!slices.Equal(report.Items[0].Effects, []string{ItemEffectUse}) {
+		t.Fatalf("Expected only the talisman's use effect to be missing of 2 items with effects, got %d: %v", report.NumItemsWithEffects, report.Items)
+	}
+	if len(report.Sets) != 1 || report.Sets[0].SetName != itemSetNameChampionsPursuance || !slices.Equal(report.Sets[0].Missing, []int32{4, 6}) {
+		t.Fatalf("Expected the 4 and 6 piece bonuses to be missing, got %v", report.Sets)
+	}
+	if len(report.Enchants) != 1 || report.Enchants[0].EffectId != 2 {
+		t.Fatalf("Expected only the enchant without stats to be missing, got %v", report.Enchants)
+	}
+
+	db.MarkUnimplementedEffects(report)
+	if db.Items[itemHandOfJustice].UnimplementedEffect || !db.Items[itemTalismanOfEphemeralPower].UnimplementedEffect || db.Items[itemBloodGuardsChainVices].UnimplementedEffect {
+		t.Fatalf("Expected only the talisman to be marked unimplemented")
+	}
+}
@@ -30,11 +30,16 @@ import (
 // Note: This does not make network requests, only regenerates core db binary and json files from existing inputs
 // go run ./tools/database/gen_db -outDir=assets -gen=db
 
+// To list the item effects, set bonuses and enchants in db.json which aren't implemented in the sim
+// (this is also written by -gen=db, which marks those items as UnimplementedEffect; the checked in
+// db.bin hasn't been regenerated since, so no item is marked until it is):
+// go run ./tools/database/gen_db -outDir=assets -gen=effect-coverage
+
 var exactId = flag.Int("id", 0, "ID to scan for")
 var minId = flag.Int("minid", 1, "Minimum ID to scan for")
 var maxId = flag.Int("maxid", 31000, "Maximum ID to scan for")
 var outDir = flag.String("outDir", "assets", "Path to output directory for writing generated .go files.")
-var genAsset = flag.String("gen", "", "Asset to generate. Valid values are 'db', 'atlasloot', 'wowhead-items', 'wowhead-spells', 'wowhead-itemdb', 'wotlk-items', and 'wago-db2-items'")
+var genAsset = flag.String("gen", "", "Asset to generate. Valid values are 'db', 'atlasloot', 'wowhead-items', 'wowhead-spells', 'wowhead-itemdb', 'wotlk-items', 'wago-db2-items', and 'effect-coverage'")
 
 func main() {
 	flag.Parse()
@@ -67,6 +72,12 @@ func main() {
 	} else if *genAsset == "wago-db2-items" {
 		tools.WriteFile(fmt.Sprintf("%s/wago_db2_items.csv", inputsDir), tools.ReadWebRequired("https://wago.tools/db2/ItemSparse/csv?build=1.15.3.55646"))
 		return
+	} else if *genAsset == "effect-coverage" {
+		sim.RegisterAll()
+		itemTooltips := database.NewWowheadItemTooltipManager(fmt.Sprintf("%s/wowhead_item_tooltips.csv", inputsDir)).Read()
+		db := database.ReadDatabaseFromJson(tools.ReadFile(fmt.Sprintf("%s/db.json", dbDir)))
+		writeEffectCoverage(db, itemTooltips, dbDir)
+		return
 	} else if *genAsset != "db" {
 		panic("Invalid gen value")
 	}
@@ -173,6 +184,9 @@ func main() {
 	leftovers.WriteBinaryAndJson(fmt.Sprintf("%s/leftover_db.bin", dbDir), fmt.Sprintf("%s/leftover_db.json", dbDir))
 
 	ApplySimmableFilters(db)
+	sim.RegisterAll()
+	db.MarkUnimplementedEffects(writeEffectCoverage(db, itemTooltips, dbDir))
+
 	for _, enchant := range db.Enchants {
 		if enchant.ItemId != 0 {
 			db.AddItemIcon(enchant.ItemId, itemTooltips)
@@ -238,6 +252,14 @@ func main() {
 	db.WriteBinaryAndJson(fmt.Sprintf("%s/db.bin", dbDir), fmt.Sprintf("%s/db.json", dbDir))
 }
 
+// Writes the effects of the db which aren't implemented in the sim to effect_coverage.csv.
+func writeEffectCoverage(db *database.WowDatabase, itemTooltips map[int32]database.WowheadItemResponse, dbDir string) *database.EffectCoverageReport {
+	report := db.ComputeEffectCoverage(itemTooltips)
+	report.WriteCsv(fmt.Sprintf("%s/effect_coverage.csv", dbDir))
+	fmt.Println(report.Summary())
+	return report
+}
+
 // Filters out entities which shouldn't be included anywhere.
 func ApplyGlobalFilters(db *database.WowDatabase) {
 	db.Items = core.FilterMap(db.Items, func(_ int32, item *proto.UIItem) bool {
@@ -0,0 +1,10 @@
+package ui
+
+import (
+	"embed"
+)
+
+// The specs' APL presets, so the sim can fall back to the rotations the UI defaults to.
+//
+//go:embed */apls/*.apl.json
+var APLPresets embed.FS
@@ -16,6 +16,7 @@ import { PlayerDamageTakenMetricsTable } from './detailed_results/player_damage_
 import { ResourceMetricsTable } from './detailed_results/resource_metrics';
 import { SimResultData } from './detailed_results/result_component';
 import { ResultsFilter } from './detailed_results/results_filter';
+import { SurvivabilityMetrics } from './detailed_results/survivability_metrics';
 import { ThreatMetricsTable } from './detailed_results/threat_metrics';
 import { Timeline } from './detailed_results/timeline';
 import { ToplineResults } from './detailed_results/topline_results';
@@ -52,6 +53,11 @@ const tabs: Tab[] = [
 		label: 'Damage Taken',
 		classes: ['threat-metrics-tab'],
 	},
+	{
+		targetId: 'survivabilityTab',
+		label: 'Survivability',
+		classes: ['threat-metrics-tab'],
+	},
 	{
 		targetId: 'buffsTab',
 		label: 'Buffs',
@@ -157,6 +163,11 @@ export abstract class DetailedResults extends Component {
 						</div>
 						<div className="dr-row damage-taken-histogram single-player-only" />
 					</div>
+					<div id="survivabilityTab" className="tab-pane dr-tab-content survivability-content fade">
+						<div className="dr-row">
+							<div className="survivability-metrics" />
+						</div>
+					</div>
 					<div id="buffsTab" className="tab-pane dr-tab-content buffs-content fade">
 						<div className="dr-row">
 							<div className="buff-aura-metrics" />
@@ -275,6 +286,11 @@ export abstract class DetailedResults extends Component {
 			resultsEmitter: this.resultsEmitter,
 		});
 
+		new SurvivabilityMetrics({
+			parent: this.rootElem.querySelector('.survivability-metrics')!,
+			resultsEmitter: this.resultsEmitter,
+		});
+
 		const timeline = new Timeline({
 			parent: this.rootElem.querySelector('.timeline')!,
 			cssScheme: cssScheme,
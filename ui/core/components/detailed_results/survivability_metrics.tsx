@@ -0,0 +1,98 @@
+import { MeleeOutcomeBreakdown, TankSurvivabilityMetrics } from '../../proto/api';
+import { UnitMetrics } from '../../proto_utils/sim_result';
+import { formatToNumber, formatToPercent } from '../../utils';
+import { ResultComponent, ResultComponentConfig, SimResultData } from './result_component';
+
+const outcomeLabels: Array<[keyof MeleeOutcomeBreakdown, string]> = [
+	['miss', 'Miss'],
+	['dodge', 'Dodge'],
+	['parry', 'Parry'],
+	['block', 'Block'],
+	['crit', 'Crit'],
+	['crush', 'Crush'],
+	['hit', 'Hit'],
+];
+
+export class SurvivabilityMetrics extends ResultComponent {
+	constructor(config: ResultComponentConfig) {
+		config.rootCssClass = 'survivability-metrics-root';
+		super(config);
+	}
+
+	onSimResult(resultData: SimResultData) {
+		const players = resultData.result.getRaidIndexedPlayers(resultData.filter).filter(player => player.survivability);
+		if (!players.length) {
+			this.rootElem.replaceChildren(<p>No players are tanking a target.</p>);
+			return;
+		}
+
+		this.rootElem.replaceChildren(...players.map(player => this.makePlayerContent(player, player.survivability!)));
+	}
+
+	private makePlayerContent(player: UnitMetrics, survivability: TankSurvivabilityMetrics) {
+		const worstWindow = survivability.worstWindow!;
+		const outcomes = survivability.outcomes!;
+		const attackTable = survivability.attackTable!;
+		const window = formatToNumber(survivability.windowSeconds, { maximumFractionDigits: 1 });
+
+		return (
+			<div className="survivability-metrics-player">
+				<h6 className={player.classColor}>{player.name}</h6>
+				<div className="dr-row">
+					<table className="metrics-table">
+						<thead className="metrics-table-header">
+							<tr className="metrics-table-header-row">
+								<th className="metrics-table-header-cell">Health</th>
+								<th className="metrics-table-header-cell text-center">Value</th>
+							</tr>
+						</thead>
+						<tbody className="metrics-table-body">
+							<tr>
+								<td>Max Health</td>
+								<td className="text-center">{formatToNumber(survivability.maxHealth, { maximumFractionDigits: 0 })}</td>
+							</tr>
+							<tr>
+								<td>Effective Health (physical)</td>
+								<td className="text-center">{formatToNumber(survivability.effectiveHealth, { maximumFractionDigits: 0 })}</td>
+							</tr>
+							<tr>
+								<td>Missing for Crushing Blow Immunity</td>
+								<td className="text-center">
+									{survivability.crushImmunityDeficit > 0 ? formatToPercent(survivability.crushImmunityDeficit * 100) : 'Immune'}
+								</td>
+							</tr>
+							<tr>
+								<td>{`Worst ${window}s Damage (avg)`}</td>
+								<td className="text-center">{`${formatToPercent(worstWindow.avg)} ± ${formatToPercent(worstWindow.stdev)}`}</td>
+							</tr>
+							{survivability.spikeDamage.map(spike => (
+								<tr>
+									<td>{`Worst ${window}s Damage (${formatToNumber(spike.percentile * 100, { maximumFractionDigits: 0 })}th percentile)`}</td>
+									<td className="text-center">{formatToPercent(spike.damage)}</td>
+								</tr>
+							))}
+						</tbody>
+					</table>
+					<table className="metrics-table">
+						<thead className="metrics-table-header">
+							<tr className="metrics-table-header-row">
+								<th className="metrics-table-header-cell">Boss Auto Attacks</th>
+								<th className="metrics-table-header-cell text-center">Attack Table</th>
+								<th className="metrics-table-header-cell text-center">Simulated</th>
+							</tr>
+						</thead>
+						<tbody className="metrics-table-body">
+							{outcomeLabels.map(([key, label]) => (
+								<tr>
+									<td>{label}</td>
+									<td className="text-center">{formatToPercent(attackTable[key] * 100)}</td>
+									<td className="text-center">{formatToPercent(outcomes[key] * 100)}</td>
+								</tr>
+							))}
+						</tbody>
+					</table>
+				</div>
+			</div>
+		);
+	}
+}
@@ -5,7 +5,7 @@ import { NumberPicker } from '../components/number_picker.js';
 import * as Mechanics from '../constants/mechanics.js';
 import { Encounter } from '../encounter.js';
 import { IndividualSimUI } from '../individual_sim_ui.js';
-import { InputType, MobType, SpellSchool, Stat, Target, Target as TargetProto, TargetInput } from '../proto/common.js';
+import { DebuffSlots, InputType, MobType, SpellSchool, Stat, Target, Target as TargetProto, TargetInput, ThreatCap } from '../proto/common.js';
 import { statNames } from '../proto_utils/names.js';
 import { Stats } from '../proto_utils/stats.js';
 import { isHealingSpec, isTankSpec } from '../proto_utils/utils.js';
@@ -175,6 +175,65 @@ class AdvancedEncounterModal extends BaseModal {
 				},
 			});
 		}
+		new BooleanPicker<Encounter>(header, encounter, {
+			id: 'encounter-use-threat-table',
+			label: 'Track Threat',
+			labelTooltip: 'Targets keep a threat table and attack whoever holds aggro, using the 110% melee / 130% ranged pull rules, instead of always attacking their assigned tank.',
+			inline: true,
+			changedEvent: (encounter: Encounter) => encounter.changeEmitter,
+			getValue: (encounter: Encounter) => encounter.getUseThreatTable(),
+			setValue: (eventID: EventID, encounter: Encounter, newValue: boolean) => {
+				encounter.setUseThreatTable(eventID, newValue);
+			},
+		});
+		new BooleanPicker<Encounter>(header, encounter, {
+			id: 'encounter-use-threat-cap',
+			label: 'Threat Cap',
+			labelTooltip: "Limits damage dealers to the tank's threat. Rotations can check the remaining budget with the Threat Margin APL value, and the DPS lost to the cap is reported in the results.",
+			inline: true,
+			changedEvent: (encounter: Encounter) => encounter.changeEmitter,
+			getValue: (encounter: Encounter) => !!encounter.getThreatCap(),
+			setValue: (eventID: EventID, encounter: Encounter, newValue: boolean) => {
+				encounter.setThreatCap(eventID, newValue ? ThreatCap.create({ tankTps: 500 }) : undefined);
+			},
+		});
+		new NumberPicker<Encounter>(header, encounter, {
+			id: 'encounter-threat-cap-tank-tps',
+			label: 'Tank TPS',
+			labelTooltip: 'Threat per second of the tank, used for the threat cap when the target has no assigned tank in the raid.',
+			changedEvent: (encounter: Encounter) => encounter.changeEmitter,
+			getValue: (encounter: Encounter) => encounter.getThreatCap()?.tankTps ?? 0,
+			setValue: (eventID: EventID, encounter: Encounter, newValue: number) => {
+				const threatCap = encounter.getThreatCap() ?? ThreatCap.create();
+				threatCap.tankTps = newValue;
+				encounter.setThreatCap(eventID, threatCap);
+			},
+		});
+		new NumberPicker<Encounter>(header, encounter, {
+			id: 'encounter-threat-cap-tank-initial-threat',
+			label: 'Tank Initial Threat',
+			labelTooltip: 'Threat the tank builds before damage dealers start attacking.',
+			changedEvent: (encounter: Encounter) => encounter.changeEmitter,
+			getValue: (encounter: Encounter) => encounter.getThreatCap()?.tankInitialThreat ?? 0,
+			setValue: (eventID: EventID, encounter: Encounter, newValue: number) => {
+				const threatCap = encounter.getThreatCap() ?? ThreatCap.create();
+				threatCap.tankInitialThreat = newValue;
+				encounter.setThreatCap(eventID, threatCap);
+			},
+		});
+		new NumberPicker<Encounter>(header, encounter, {
+			id: 'encounter-debuff-slot-limit',
+			label: 'Debuff Slot Limit',
+			labelTooltip:
+				'Maximum number of debuffs on each target, 0 for unlimited. When a target is full, raid debuffs push off DoTs, DoTs push off other debuffs, and anything else is denied. How often each debuff was denied or pushed off is reported in the results.',
+			changedEvent: (encounter: Encounter) => encounter.changeEmitter,
+			getValue: (encounter: Encounter) => encounter.getDebuffSlots()?.limit ?? 0,
+			setValue: (eventID: EventID, encounter: Encounter, newValue: number) => {
+				const debuffSlots = encounter.getDebuffSlots() ?? DebuffSlots.create();
+				debuffSlots.limit = Math.max(newValue, 0);
+				encounter.setDebuffSlots(eventID, debuffSlots.limit > 0 || debuffSlots.priorities.length > 0 ? debuffSlots : undefined);
+			},
+		});
 		new ListPicker<Encounter, TargetProto>(targetsElem, this.encounter, {
 			extraCssClasses: ['targets-picker', 'mb-0'],
 			itemLabel: 'Target',
@@ -424,6 +424,7 @@ export default class ItemList<T extends ItemListType> {
 		const favoriteIconElem = ref<HTMLElement>();
 		const compareContainer = ref<HTMLDivElement>();
 		const compareButton = ref<HTMLButtonElement>();
+		const unimplementedElem = ref<HTMLElement>();
 
 		const listItemElem = (
 			<li className={clsx('selector-modal-list-item', equippedItemID === itemData.id && 'active')} dataset={{ idx: item.idx.toString() }}>
@@ -434,6 +435,9 @@ export default class ItemList<T extends ItemListType> {
 							{itemData.name}
 						</label>
 					</a>
+					{this.label === SelectorModalTabs.Items && (itemData.item as unknown as UIItem).unimplementedEffect && (
+						<i ref={unimplementedElem} className="fas fa-triangle-exclamation text-warning ms-2" />
+					)}
 				</div>
 				{this.label === SelectorModalTabs.Items && (
 					<>
@@ -516,6 +520,10 @@ export default class ItemList<T extends ItemListType> {
 			listItemElem.dataset.fav = 'false';
 		}
 
+		if (unimplementedElem.value) {
+			tippy(unimplementedElem.value, { content: 'This item has an effect which is not implemented in the sim, only its stats are simmed.' });
+		}
+
 		const favoriteTooltip = tippy(favoriteElem.value!);
 		const toggleFavoriteTooltipContent = (isFavorited: boolean) => favoriteTooltip.setContent(isFavorited ? 'Remove from favorites' : 'Add to favorites');
 		toggleFavoriteTooltipContent(listItemElem.dataset.fav === 'true');
@@ -46,6 +46,7 @@ import {
 	APLValueMin,
 	APLValueNot,
 	APLValueNumberTargets,
+	APLValueTimeToNextMovement,
 	APLValueOr,
 	APLValueRemainingTime,
 	APLValueRemainingTimePercent,
@@ -61,8 +62,10 @@ import {
 	APLValueSpellIsChanneling,
 	APLValueSpellIsKnown,
 	APLValueSpellIsReady,
+	APLValueSpellManaEfficiency,
 	APLValueSpellTimeToReady,
 	APLValueSpellTravelTime,
+	APLValueThreatMargin,
 	APLValueTimeToEnergyTick,
 	APLValueTotemRemainingTime,
 	APLValueWarlockCurrentPetMana,
@@ -591,6 +594,13 @@ const valueKindFactories: { [f in NonNullable<APLValueKind>]: ValueKindConfig<AP
 		newValue: APLValueNumberTargets.create,
 		fields: [],
 	}),
+	timeToNextMovement: inputBuilder({
+		label: 'Time To Next Movement',
+		submenu: ['Encounter'],
+		shortDescription: 'Time until the encounter next forces this player to move, <b>0</b> while moving, or the remaining fight duration if no more movement is expected.',
+		newValue: APLValueTimeToNextMovement.create,
+		fields: [],
+	}),
 	frontOfTarget: inputBuilder({
 		label: 'Front of Target',
 		submenu: ['Encounter'],
@@ -682,6 +692,17 @@ const valueKindFactories: { [f in NonNullable<APLValueKind>]: ValueKindConfig<AP
 		],
 		includeIf: (player: Player<any>, _isPrepull: boolean) => player.getClass() === Class.ClassRogue || player.getClass() === Class.ClassDruid,
 	}),
+	threatMargin: inputBuilder({
+		label: 'Threat Margin',
+		submenu: ['Resources'],
+		shortDescription: 'Threat that can still be generated on the current target before pulling aggro off the tank.',
+		fullDescription: `
+		<p>Only limited when the encounter tracks threat or has a threat cap, otherwise this is always a very large number.</p>
+		<p>Uses the 110% melee / 130% ranged pull thresholds, and is negative once the player is above them.</p>
+		`,
+		newValue: APLValueThreatMargin.create,
+		fields: [],
+	}),
 	currentComboPoints: inputBuilder({
 		label: 'Combo Points',
 		submenu: ['Resources'],
@@ -722,7 +743,7 @@ const valueKindFactories: { [f in NonNullable<APLValueKind>]: ValueKindConfig<AP
 		submenu: ['Auto'],
 		shortDescription: 'Amount of time remaining before the next Main-hand or Off-hand melee attack, or <b>0</b> if autoattacks are not engaged.',
 		newValue: APLValueAutoTimeToNext.create,
-		fields: [autoTypeFieldConfig('autoType')],
+		fields: [autoTypeFieldConfig('autoType'), AplHelpers.unitFieldConfig('sourceUnit', 'aura_sources')],
 	}),
 	autoSwingTime: inputBuilder({
 		label: 'Auto Swing Time',
@@ -747,6 +768,17 @@ const valueKindFactories: { [f in NonNullable<APLValueKind>]: ValueKindConfig<AP
 		newValue: APLValueSpellCurrentCost.create,
 		fields: [AplHelpers.actionIdFieldConfig('spellId', 'castable_spells', '')],
 	}),
+	spellManaEfficiency: inputBuilder({
+		label: 'Mana Efficiency',
+		submenu: ['Spell'],
+		shortDescription: 'Expected damage or healing of a cast of the spell per point of mana, at its current cost.',
+		fullDescription: `
+			<p>Compare the ranks of a spell with this and the remaining fight time and mana to pick which rank to cast.</p>
+			<p>Spells without an expected damage estimate use their average damage and healing per cast so far, so they are <b>0</b> until the first cast.</p>
+		`,
+		newValue: APLValueSpellManaEfficiency.create,
+		fields: [AplHelpers.actionIdFieldConfig('spellId', 'castable_spells', '')],
+	}),
 	spellCanCast: inputBuilder({
 		label: 'Can Cast',
 		submenu: ['Spell'],
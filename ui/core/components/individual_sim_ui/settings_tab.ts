@@ -21,7 +21,7 @@ import { MultiIconPicker, MultiIconPickerItemConfig } from '../multi_icon_picker
 import { NumberPicker } from '../number_picker';
 import { SavedDataManager } from '../saved_data_manager';
 import { SimTab } from '../sim_tab';
-import { IsbConfig } from './../other_inputs';
+import { DebuffRampUpConfig, IsbConfig } from './../other_inputs';
 import { ConsumesPicker } from './consumes_picker';
 import { ItemSwapPicker } from './item_swap_picker';
 import { PresetConfigurationPicker } from './preset_configuration_picker';
@@ -74,6 +74,7 @@ export class SettingsTab extends SimTab {
 			this.buildConsumesSection();
 			this.buildOtherSettings();
 			this.buildIsbSettings();
+			this.buildDebuffRampUpSettings();
 
 			if (!this.simUI.isWithinRaidSim) {
 				this.buildBuffsSettings();
@@ -222,6 +223,25 @@ export class SettingsTab extends SimTab {
 		}
 	}
 
+	private buildDebuffRampUpSettings() {
+		if (!this.simUI.isWithinRaidSim) {
+			const contentBlock = new ContentBlock(this.column1, 'other-settings', {
+				header: { title: 'Debuff Ramp-Up' },
+			});
+
+			this.configureInputSection(contentBlock.bodyElement, DebuffRampUpConfig);
+
+			this.simUI.player.getRaid()!.debuffsChangeEmitter.on(() => {
+				const debuffs = this.simUI.player.getRaid()!.getDebuffs();
+				if (debuffs.sunderArmor || debuffs.wintersChill || debuffs.shadowWeaving || debuffs.improvedShadowBolt) {
+					contentBlock.rootElem.classList.remove('hide');
+				} else {
+					contentBlock.rootElem.classList.add('hide');
+				}
+			});
+		}
+	}
+
 	private buildBuffsSettings() {
 		const buffOptions = relevantStatOptions(BuffDebuffInputs.RAID_BUFFS_CONFIG, this.simUI);
 		const miscBuffOptions = relevantStatOptions(BuffDebuffInputs.MISC_BUFFS_CONFIG, this.simUI);
@@ -263,6 +283,11 @@ export class SettingsTab extends SimTab {
 				options => options.picker && new options.picker(contentBlock.bodyElement, this.simUI.player, options.config as any, this.simUI),
 			),
 		);
+
+		const durationsElem = document.createElement('div');
+		durationsElem.classList.add('world-buff-durations');
+		contentBlock.bodyElement.appendChild(durationsElem);
+		BuffDebuffInputs.WORLD_BUFF_DURATIONS_CONFIG.forEach(config => new NumberPicker(durationsElem, this.simUI.player, config));
 	}
 
 	private buildDebuffsSettings() {
@@ -1,5 +1,7 @@
-import { Faction, SaygesFortune, Stat } from '../../proto/common';
+import { Player } from '../../player';
+import { Faction, SaygesFortune, Stat, WorldBuffDurations } from '../../proto/common';
 import { ActionId } from '../../proto_utils/action_id';
+import { EventID } from '../../typed_event';
 import {
 	makeBooleanDebuffInput,
 	makeBooleanIndividualBuffInput,
@@ -15,6 +17,7 @@ import {
 import { IconPicker, IconPickerDirection } from '../icon_picker';
 import * as InputHelpers from '../input_helpers';
 import { MultiIconPicker } from '../multi_icon_picker';
+import { NumberPickerConfig } from '../number_picker';
 import { ItemStatOption, PickerStatOptions } from './stat_options';
 
 ///////////////////////////////////////////////////////////////////////////
@@ -716,6 +719,35 @@ export const WORLD_BUFFS_CONFIG = [
 	},
 ] as PickerStatOptions[];
 
+// Minutes left on a world buff at the pull, shown while the buff is on.
+export const WorldBuffDuration = (fieldName: keyof WorldBuffDurations, label: string): NumberPickerConfig<Player<any>> => ({
+	id: `world-buff-duration-${fieldName}`,
+	label: `${label} (min left)`,
+	labelTooltip: 'Minutes left on the buff at the pull, after which it falls off during the fight. Leave empty to keep it up for the whole fight.',
+	float: true,
+	positive: true,
+	showZeroes: false,
+	changedEvent: (player: Player<any>) => player.buffsChangeEmitter,
+	getValue: (player: Player<any>) => (player.getBuffs().worldBuffDurations?.[fieldName] ?? 0) / 60,
+	setValue: (eventID: EventID, player: Player<any>, newValue: number) => {
+		const buffs = player.getBuffs();
+		buffs.worldBuffDurations = WorldBuffDurations.create({ ...buffs.worldBuffDurations, [fieldName]: newValue * 60 });
+		player.setBuffs(eventID, buffs);
+	},
+	showWhen: (player: Player<any>) => !!player.getBuffs()[fieldName],
+});
+
+export const WORLD_BUFF_DURATIONS_CONFIG = [
+	WorldBuffDuration('rallyingCryOfTheDragonslayer', 'Rallying Cry'),
+	WorldBuffDuration('songflowerSerenade', 'Songflower'),
+	WorldBuffDuration('spiritOfZandalar', 'Spirit of Zandalar'),
+	WorldBuffDuration('warchiefsBlessing', "Warchief's Blessing"),
+	WorldBuffDuration('fengusFerocity', "Fengus' Ferocity"),
+	WorldBuffDuration('moldarsMoxie', "Moldar's Moxie"),
+	WorldBuffDuration('slipkiksSavvy', "Slip'kik's Savvy"),
+	WorldBuffDuration('saygesFortune', "Sayge's Fortune"),
+];
+
 export const SAYGES_CONFIG = [
 	{
 		config: SaygesDamage,
@@ -2,7 +2,7 @@ import { BooleanPicker } from '../components/boolean_picker.js';
 import { CURRENT_LEVEL_CAP } from '../constants/mechanics.js';
 import { CURRENT_PHASE } from '../constants/other.js';
 import { Player } from '../player.js';
-import { Spec, UnitReference } from '../proto/common.js';
+import { DebuffRampUp, Debuffs, Spec, UnitReference } from '../proto/common.js';
 import { emptyUnitReference } from '../proto_utils/utils.js';
 import { Sim } from '../sim.js';
 import { EventID, TypedEvent } from '../typed_event.js';
@@ -208,6 +208,96 @@ export const IsbConfig = {
 	inputs: [IsbUsingShadowflame, IsbSbFrequencey, IsbCrit, IsbWarlocks, IsbSpriests],
 };
 
+const makeDebuffRampUpInput = (
+	id: string,
+	label: string,
+	labelTooltip: string,
+	field: keyof DebuffRampUp,
+	showWhen: (debuffs: Debuffs) => boolean,
+) => ({
+	id: id,
+	type: 'number' as const,
+	label: label,
+	labelTooltip: labelTooltip,
+	float: !field.endsWith('Casters'),
+	inline: true,
+	changedEvent: (player: Player<any>) => player.getRaid()!.debuffsChangeEmitter,
+	getValue: (player: Player<any>) => player.getRaid()!.getDebuffs().rampUp?.[field] || 0,
+	setValue: (eventID: EventID, player: Player<any>, newValue: number) => {
+		const debuffs = player.getRaid()!.getDebuffs();
+		debuffs.rampUp = DebuffRampUp.create({ ...debuffs.rampUp, [field]: newValue });
+		player.getRaid()!.setDebuffs(eventID, debuffs);
+	},
+	showWhen: (player: Player<any>) => {
+		const debuffs = player.getRaid()!.getDebuffs();
+		return !!debuffs.rampUp && showWhen(debuffs);
+	},
+});
+
+export const DebuffRampUpEnabled = {
+	id: 'debuff-ramp-up',
+	type: 'boolean' as const,
+	label: 'Ramp Up Debuffs',
+	labelTooltip: 'Stacks external Sunder Armor, Winter\'s Chill and Shadow Weaving as they are cast, instead of stacking up every 1.5s from the pull.',
+	inline: true,
+	changedEvent: (player: Player<any>) => player.getRaid()!.debuffsChangeEmitter,
+	getValue: (player: Player<any>) => !!player.getRaid()!.getDebuffs().rampUp,
+	setValue: (eventID: EventID, player: Player<any>, newValue: boolean) => {
+		const debuffs = player.getRaid()!.getDebuffs();
+		debuffs.rampUp = newValue ? DebuffRampUp.create() : undefined;
+		player.getRaid()!.setDebuffs(eventID, debuffs);
+	},
+};
+
+export const DebuffRampUpConfig = {
+	tooltip: 'External debuff ramp-up configuration',
+	inputs: [
+		DebuffRampUpEnabled,
+		makeDebuffRampUpInput(
+			'debuff-ramp-up-start-delay',
+			'Start Delay',
+			'Seconds after the pull before external players start applying debuffs. Also delays external Improved Shadow Bolt.',
+			'startDelay',
+			() => true,
+		),
+		makeDebuffRampUpInput(
+			'debuff-ramp-up-sunder-interval',
+			'Sunder Interval',
+			'Seconds between external Sunder Armor casts. Defaults to 1.5.',
+			'sunderArmorInterval',
+			debuffs => debuffs.sunderArmor,
+		),
+		makeDebuffRampUpInput(
+			'debuff-ramp-up-wc-casters',
+			'Frost Mages',
+			'Number of Frost Mages applying Winter\'s Chill. Defaults to 1.',
+			'wintersChillCasters',
+			debuffs => debuffs.wintersChill,
+		),
+		makeDebuffRampUpInput(
+			'debuff-ramp-up-wc-interval',
+			'Frostbolt Interval',
+			'Seconds between each Frost Mage\'s Frostbolts. Defaults to 2.5.',
+			'wintersChillInterval',
+			debuffs => debuffs.wintersChill,
+		),
+		makeDebuffRampUpInput(
+			'debuff-ramp-up-sw-casters',
+			'Shadow Priests',
+			'Number of Shadow Priests applying Shadow Weaving. Defaults to 1.',
+			'shadowWeavingCasters',
+			debuffs => debuffs.shadowWeaving,
+		),
+		makeDebuffRampUpInput(
+			'debuff-ramp-up-sw-interval',
+			'Shadow Spell Interval',
+			'Seconds between each Shadow Priest\'s shadow spells. Defaults to 1.5.',
+			'shadowWeavingInterval',
+			debuffs => debuffs.shadowWeaving,
+		),
+	],
+};
+
 export const TankAssignment = {
 	id: 'tank-assignment',
 	type: 'enum' as const,
@@ -25,6 +25,7 @@ export class SettingsMenu extends BaseModal {
 		const showThreatMetrics = ref<HTMLDivElement>();
 		const showExperimental = ref<HTMLDivElement>();
 		const showQuickSwap = ref<HTMLDivElement>();
+		const spellBatching = ref<HTMLDivElement>();
 		const useConcurrentWorkersWrap = ref<HTMLDivElement>();
 		const useConcurrentWorkers = ref<HTMLDivElement>();
 		const useConcurrentWorkersNote = ref<HTMLDivElement>();
@@ -46,6 +47,7 @@ export class SettingsMenu extends BaseModal {
 				<div ref={showThreatMetrics} className="show-threat-metrics-picker w-50 pe-2"></div>
 				<div ref={showExperimental} className="show-experimental-picker w-50 pe-2"></div>
 				<div ref={showQuickSwap} className="show-quick-swap-picker w-50 pe-2"></div>
+				<div ref={spellBatching} className="spell-batching-picker w-50 pe-2"></div>
 				<div ref={useConcurrentWorkersWrap} className="use-concurrency-container w-50 pe-2">
 					<div ref={useConcurrentWorkers} className="use-concurrent-workers-picker"></div>
 					<div ref={useConcurrentWorkersNote} className="form-text" hidden></div>
@@ -150,6 +152,22 @@ export class SettingsMenu extends BaseModal {
 				},
 			});
 
+		if (spellBatching.value)
+			new EnumPicker<Sim>(spellBatching.value, this.simUI.sim, {
+				id: 'simui-spell-batching-picker',
+				label: 'Spell Batching',
+				labelTooltip:
+					'Quantizes when casts, swings and procs land into server batches. Reactions, e.g. Overpower after a dodge, land in the batch after the one which triggered them.',
+				values: [
+					{ value: 0, name: 'Off' },
+					{ value: 10, name: 'Modern (10ms)' },
+					{ value: 400, name: 'Original (400ms)' },
+				],
+				changedEvent: (sim: Sim) => sim.spellBatchWindowChangeEmitter,
+				getValue: (sim: Sim) => Math.round(sim.getSpellBatchWindow() * 1000),
+				setValue: (eventID, sim, newValue) => sim.setSpellBatchWindow(eventID, newValue / 1000),
+			});
+
 		if (useConcurrentWorkersWrap.value && useConcurrentWorkers.value) {
 			const values: EnumValueConfig[] = [{ value: 0, name: 'Off' }];
 			for (let i = 2; i <= navigator.hardwareConcurrency; i++) {
@@ -1,5 +1,5 @@
 import { UnitMetadataList } from './player.js';
-import { Encounter as EncounterProto, PresetEncounter, PresetTarget, Target as TargetProto } from './proto/common.js';
+import { DebuffSlots, Encounter as EncounterProto, PresetEncounter, PresetTarget, Target as TargetProto, ThreatCap } from './proto/common.js';
 import { Sim } from './sim.js';
 import { EventID, TypedEvent } from './typed_event.js';
 
@@ -19,6 +19,9 @@ export class Encounter {
 	private executeProportion25 = DEFAULT_EXECUTE_25;
 	private executeProportion35 = DEFAULT_EXECUTE_35;
 	private useHealth = false;
+	private useThreatTable = false;
+	private threatCap: ThreatCap | undefined = undefined;
+	private debuffSlots: DebuffSlots | undefined = undefined;
 
 	targets!: Array<TargetProto>;
 	targetsMetadata: UnitMetadataList;
@@ -109,6 +112,36 @@ export class Encounter {
 		this.executeProportionChangeEmitter.emit(eventID);
 	}
 
+	getUseThreatTable(): boolean {
+		return this.useThreatTable;
+	}
+	setUseThreatTable(eventID: EventID, newUseThreatTable: boolean) {
+		if (newUseThreatTable == this.useThreatTable) return;
+
+		this.useThreatTable = newUseThreatTable;
+		this.changeEmitter.emit(eventID);
+	}
+
+	getThreatCap(): ThreatCap | undefined {
+		return this.threatCap ? ThreatCap.clone(this.threatCap) : undefined;
+	}
+	setThreatCap(eventID: EventID, newThreatCap: ThreatCap | undefined) {
+		if (this.threatCap == newThreatCap || (this.threatCap && newThreatCap && ThreatCap.equals(this.threatCap, newThreatCap))) return;
+
+		this.threatCap = newThreatCap ? ThreatCap.clone(newThreatCap) : undefined;
+		this.changeEmitter.emit(eventID);
+	}
+
+	getDebuffSlots(): DebuffSlots | undefined {
+		return this.debuffSlots ? DebuffSlots.clone(this.debuffSlots) : undefined;
+	}
+	setDebuffSlots(eventID: EventID, newDebuffSlots: DebuffSlots | undefined) {
+		if (this.debuffSlots == newDebuffSlots || (this.debuffSlots && newDebuffSlots && DebuffSlots.equals(this.debuffSlots, newDebuffSlots))) return;
+
+		this.debuffSlots = newDebuffSlots ? DebuffSlots.clone(newDebuffSlots) : undefined;
+		this.changeEmitter.emit(eventID);
+	}
+
 	matchesPreset(preset: PresetEncounter): boolean {
 		return preset.targets.length == this.targets.length && this.targets.every((t, i) => TargetProto.equals(t, preset.targets[i].target));
 	}
@@ -131,6 +164,9 @@ export class Encounter {
 			executeProportion25: this.executeProportion25,
 			executeProportion35: this.executeProportion35,
 			useHealth: this.useHealth,
+			useThreatTable: this.useThreatTable,
+			threatCap: this.threatCap,
+			debuffSlots: this.debuffSlots,
 			targets: this.targets,
 		});
 	}
@@ -143,6 +179,9 @@ export class Encounter {
 			this.setExecuteProportion25(eventID, proto.executeProportion25);
 			this.setExecuteProportion35(eventID, proto.executeProportion35);
 			this.setUseHealth(eventID, proto.useHealth);
+			this.setUseThreatTable(eventID, proto.useThreatTable);
+			this.setThreatCap(eventID, proto.threatCap);
+			this.setDebuffSlots(eventID, proto.debuffSlots);
 			this.targets = proto.targets;
 			this.targetsChangeEmitter.emit(eventID);
 		});
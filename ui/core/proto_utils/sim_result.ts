@@ -12,6 +12,7 @@ import {
 	RaidSimResult,
 	ResourceMetrics as ResourceMetricsProto,
 	ResourceType,
+	TankSurvivabilityMetrics as TankSurvivabilityMetricsProto,
 	TargetedActionMetrics as TargetedActionMetricsProto,
 	UnitMetrics as UnitMetricsProto,
 } from '../proto/api.js';
@@ -438,6 +439,11 @@ export class UnitMetrics {
 		return this.metrics.chanceOfDeath * 100;
 	}
 
+	// Only set for players tanking a target.
+	get survivability(): TankSurvivabilityMetricsProto | undefined {
+		return this.metrics.survivability;
+	}
+
 	get maxThreat() {
 		return this.threatLogs[this.threatLogs.length - 1]?.threatAfter || 0;
 	}
@@ -824,6 +830,14 @@ export class ActionMetrics {
 		return this.combinedMetrics.avgResistedDamage;
 	}
 
+	get avgResistedDamageLost() {
+		return this.combinedMetrics.avgResistedDamageLost;
+	}
+
+	get partialResistDistribution() {
+		return this.combinedMetrics.partialResistDistribution;
+	}
+
 	get critDamage() {
 		return this.combinedMetrics.critDamage;
 	}
@@ -1392,6 +1406,17 @@ export class TargetedActionMetrics {
 		return this.data.resistedDamage / this.iterations;
 	}
 
+	get avgResistedDamageLost() {
+		return this.data.resistedDamageLost / this.iterations;
+	}
+
+	// Share of partially resistable hits which were resisted by 0, 25, 50 and 75%.
+	get partialResistDistribution() {
+		const counts = [this.data.partialResists0, this.data.partialResists25, this.data.partialResists50, this.data.partialResists75];
+		const total = counts.reduce((a, b) => a + b, 0);
+		return counts.map(count => (total ? count / total : 0));
+	}
+
 	get critDamage() {
 		return this.data.critDamage;
 	}
@@ -1762,6 +1787,11 @@ export class TargetedActionMetrics {
 				blockDamage: sum(actions.map(a => a.data.blockDamage)),
 				blockedCritDamage: sum(actions.map(a => a.data.blockedCritDamage)),
 				crushDamage: sum(actions.map(a => a.data.crushDamage)),
+				partialResists0: sum(actions.map(a => a.data.partialResists0)),
+				partialResists25: sum(actions.map(a => a.data.partialResists25)),
+				partialResists50: sum(actions.map(a => a.data.partialResists50)),
+				partialResists75: sum(actions.map(a => a.data.partialResists75)),
+				resistedDamageLost: sum(actions.map(a => a.data.resistedDamageLost)),
 				threat: sum(actions.map(a => a.data.threat)),
 				healing: sum(actions.map(a => a.data.healing)),
 				critHealing: sum(actions.map(a => a.data.critHealing)),
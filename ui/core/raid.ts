@@ -22,6 +22,7 @@ export class Raid {
 	private tanks: Array<UnitReference> = [];
 	private targetDummies = 0;
 	private numActiveParties = 5;
+	private buffsFromMembers = false;
 
 	// Emits when a raid member is added/removed/moved.
 	readonly compChangeEmitter = new TypedEvent<void>();
@@ -173,6 +174,18 @@ export class Raid {
 		this.targetDummiesChangeEmitter.emit(eventID);
 	}
 
+	getBuffsFromMembers(): boolean {
+		return this.buffsFromMembers;
+	}
+
+	setBuffsFromMembers(eventID: EventID, newBuffsFromMembers: boolean) {
+		if (this.buffsFromMembers == newBuffsFromMembers)
+			return;
+
+		this.buffsFromMembers = newBuffsFromMembers;
+		this.buffsChangeEmitter.emit(eventID);
+	}
+
 	getNumActiveParties(): number {
 		return this.numActiveParties;
 	}
@@ -201,6 +214,7 @@ export class Raid {
 			tanks: this.getTanks(),
 			targetDummies: this.getTargetDummies(),
 			numActiveParties: this.getNumActiveParties(),
+			buffsFromMembers: this.getBuffsFromMembers(),
 		});
 	}
 
@@ -211,6 +225,7 @@ export class Raid {
 			this.setTanks(eventID, proto.tanks);
 			this.setTargetDummies(eventID, proto.targetDummies);
 			this.setNumActiveParties(eventID, proto.numActiveParties || 5);
+			this.setBuffsFromMembers(eventID, proto.buffsFromMembers);
 
 			for (let i = 0; i < MAX_NUM_PARTIES; i++) {
 				if (proto.parties[i]) {
@@ -68,6 +68,7 @@ export class Sim {
 	private phase: number = OtherConstants.CURRENT_PHASE;
 	private faction: Faction = Faction.Alliance;
 	private fixedRngSeed = 0;
+	private spellBatchWindow = 0;
 	private filters: DatabaseFilters = Sim.defaultFilters();
 	private showDamageMetrics = true;
 	private showThreatMetrics = false;
@@ -87,6 +88,7 @@ export class Sim {
 	readonly factionChangeEmitter = new TypedEvent<void>();
 	readonly fixedRngSeedChangeEmitter = new TypedEvent<void>();
 	readonly lastUsedRngSeedChangeEmitter = new TypedEvent<void>();
+	readonly spellBatchWindowChangeEmitter = new TypedEvent<void>();
 	readonly filtersChangeEmitter = new TypedEvent<void>();
 	readonly showDamageMetricsChangeEmitter = new TypedEvent<void>();
 	readonly showThreatMetricsChangeEmitter = new TypedEvent<void>();
@@ -156,6 +158,7 @@ export class Sim {
 			this.iterationsChangeEmitter,
 			this.phaseChangeEmitter,
 			this.fixedRngSeedChangeEmitter,
+			this.spellBatchWindowChangeEmitter,
 			this.filtersChangeEmitter,
 			this.showDamageMetricsChangeEmitter,
 			this.showThreatMetricsChangeEmitter,
@@ -232,6 +235,7 @@ export class Sim {
 				iterations: debug ? 1 : this.getIterations(),
 				randomSeed: BigInt(this.nextRngSeed()),
 				debugFirstIteration: true,
+				spellBatchWindow: this.getSpellBatchWindow(),
 			}),
 		});
 	}
@@ -437,6 +441,7 @@ export class Sim {
 					iterations: this.getIterations(),
 					randomSeed: BigInt(this.nextRngSeed()),
 					debug: false,
+					spellBatchWindow: this.getSpellBatchWindow(),
 				}),
 				tanks: tanks,
 
@@ -521,6 +526,16 @@ export class Sim {
 		}
 	}
 
+	getSpellBatchWindow(): number {
+		return this.spellBatchWindow;
+	}
+	setSpellBatchWindow(eventID: EventID, newSpellBatchWindow: number) {
+		if (newSpellBatchWindow != this.spellBatchWindow) {
+			this.spellBatchWindow = newSpellBatchWindow;
+			this.spellBatchWindowChangeEmitter.emit(eventID);
+		}
+	}
+
 	static MAX_RNG_SEED = Math.pow(2, 32) - 1;
 	private nextRngSeed(): number {
 		let rngSeed = 0;
@@ -663,6 +678,7 @@ export class Sim {
 			iterations: this.getIterations(),
 			phase: this.getPhase(),
 			fixedRngSeed: BigInt(this.getFixedRngSeed()),
+			spellBatchWindow: this.getSpellBatchWindow(),
 			showDamageMetrics: this.getShowDamageMetrics(),
 			showThreatMetrics: this.getShowThreatMetrics(),
 			showHealingMetrics: this.getShowHealingMetrics(),
@@ -679,6 +695,7 @@ export class Sim {
 			this.setIterations(eventID, proto.iterations || 3000);
 			this.setPhase(eventID, proto.phase || OtherConstants.CURRENT_PHASE);
 			this.setFixedRngSeed(eventID, Number(proto.fixedRngSeed));
+			this.setSpellBatchWindow(eventID, proto.spellBatchWindow);
 			this.setShowDamageMetrics(eventID, proto.showDamageMetrics);
 			this.setShowThreatMetrics(eventID, proto.showThreatMetrics);
 			this.setShowHealingMetrics(eventID, proto.showHealingMetrics);
@@ -1,3 +1,4 @@
+import { BooleanPicker } from "../core/components/boolean_picker";
 import { ContentBlock } from "../core/components/content_block";
 import { EncounterPicker } from "../core/components/encounter_picker";
 import { IconPicker } from "../core/components/icon_picker";
@@ -88,10 +89,19 @@ export class SettingsTab extends SimTab {
 	}
 
 	private buildOtherSettings() {
-		// const raid = this.simUI.sim.raid;
-		// const contentBlock = new ContentBlock(this.column1, 'other-settings', {
-		//   header: {title: 'Other'}
-		// });
+		const contentBlock = new ContentBlock(this.column1, 'other-settings', {
+			header: { title: 'Other' }
+		});
+
+		new BooleanPicker(contentBlock.bodyElement, this.simUI.sim.raid, {
+			label: 'Party Buffs From Members',
+			labelTooltip: 'Totems, Battle Shout and Trueshot Aura come from the simulated players in each party, including their uptime. The matching buff toggles only apply to parties without such a player.',
+			changedEvent: (raid: Raid) => raid.buffsChangeEmitter,
+			getValue: (raid: Raid) => raid.getBuffsFromMembers(),
+			setValue: (eventID: EventID, raid: Raid, newValue: boolean) => {
+				raid.setBuffsFromMembers(eventID, newValue);
+			},
+		});
 
 		// new BooleanPicker(contentBlock.bodyElement, this.simUI.sim.raid, {
 		// 	label: 'Stagger Stormstrikes',
@@ -20,6 +20,14 @@ declare global {
 	const raidSimResultCombination: SimRequestSync;
 	const raidSimRequestSplit: SimRequestSync;
 	const abortById: SimRequestSync;
+	const raidComposition: SimRequestSync;
+	const blessingsPlan: SimRequestSync;
+	const characterImport: SimRequestSync;
+	const defensivePlan: SimRequestSync;
+	const manaPlan: SimRequestSync;
+	const consumableValue: SimRequestSync;
+	const worldBuffTimeline: SimRequestSync;
+	const raidNight: SimRequestSync;
 }
 
 // Wasm binary calls this function when its done loading.
@@ -40,6 +48,14 @@ globalThis.wasmready = function () {
 		raidSimRequestSplit: raidSimRequestSplit,
 		raidSimResultCombination: raidSimResultCombination,
 		abortById: abortById,
+		raidComposition: raidComposition,
+		blessingsPlan: blessingsPlan,
+		characterImport: characterImport,
+		defensivePlan: defensivePlan,
+		manaPlan: manaPlan,
+		consumableValue: consumableValue,
+		worldBuffTimeline: worldBuffTimeline,
+		raidNight: raidNight,
 	}).ready(true);
 };
 
@@ -16,6 +16,14 @@ export enum SimRequest {
 	raidSimRequestSplit = 'raidSimRequestSplit',
 	raidSimResultCombination = 'raidSimResultCombination',
 	abortById = 'abortById',
+	raidComposition = 'raidComposition',
+	blessingsPlan = 'blessingsPlan',
+	characterImport = 'characterImport',
+	defensivePlan = 'defensivePlan',
+	manaPlan = 'manaPlan',
+	consumableValue = 'consumableValue',
+	worldBuffTimeline = 'worldBuffTimeline',
+	raidNight = 'raidNight',
 }
 
 /**
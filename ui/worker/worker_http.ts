@@ -61,5 +61,13 @@ export const setupHttpWorker = (baseURL: string) => {
 		raidSimRequestSplit: noWasmConcurrency,
 		raidSimResultCombination: noWasmConcurrency,
 		abortById: syncHandler,
+		raidComposition: syncHandler,
+		blessingsPlan: syncHandler,
+		characterImport: syncHandler,
+		defensivePlan: syncHandler,
+		manaPlan: syncHandler,
+		consumableValue: syncHandler,
+		worldBuffTimeline: syncHandler,
+		raidNight: syncHandler,
 	}).ready(false);
 };